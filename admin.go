@@ -0,0 +1,987 @@
+package goticks
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// ErrTaskExists is returned by [Admin.Create] for a task name, which is
+// already taken.
+var ErrTaskExists = errors.New("task already exists")
+
+// ErrTaskInUse is returned by [Admin.Delete] for a task, which other tasks
+// depend on with [TaskConfig] After.
+var ErrTaskInUse = errors.New("task is in use")
+
+// ErrInvalidPatch is returned by [Admin.Reconfigure] for a [TaskPatch] with
+// invalid values, e.g. a negative timeout.
+var ErrInvalidPatch = errors.New("invalid task patch")
+
+// Admin manages the tasks, created at runtime from the registered factories.
+// See [Register].
+//
+// The tasks may be isolated in namespaces, e.g. per tenant, each managed by
+// its own admin, see [Admin.Namespace].
+type Admin struct {
+	mux       sync.Mutex
+	namespace string
+	opts      []option
+	metrics   *Metrics
+	cfg       map[string]TaskConfig
+	tasks     map[string]RestartableWithTicker[time.Time]
+	// order is the configuration order of the task names.
+	order []string
+	// ready is closed on the first successful run of the task.
+	ready map[string]chan struct{}
+	// starts is cancelled by the stop of all tasks to abandon the starts,
+	// waiting for the dependencies.
+	starts       context.Context
+	cancelStarts context.CancelFunc
+	// quarantine holds the tasks, stopped by a failure.
+	quarantine map[string]*quarantined
+	// root is the admin of the default namespace, which owns the namespaces
+	// and the persistence.
+	root       *Admin
+	namespaces map[string]*Admin
+	// pending is the initial configuration of the namespaces, built when
+	// the namespace is open.
+	pending map[string][]TaskConfig
+
+	// saveMux serializes the saves of the whole configuration, which
+	// snapshots are kept per namespace in saved.
+	saveMux sync.Mutex
+	save    func([]TaskConfig) error
+	saved   map[string][]TaskConfig
+}
+
+// NewAdmin returns the task admin, which starts the tasks of the initial
+// configuration, e.g. loaded with [LoadConfig]. The options are applied to
+// every task, e.g. [WithShaper] shapes the aggregate rate of all the tasks,
+// delaying the [TaskConfig] LowPriority ones. The tasks of the other
+// namespaces are started when the namespace is open with [Admin.Namespace].
+// The save function, if not nil, is called with the whole configuration of
+// all namespaces on every change, so that the tasks can be restored after a
+// restart.
+func NewAdmin(cfg []TaskConfig, save func([]TaskConfig) error, opts ...option) (*Admin, error) {
+	a := newAdmin("", opts)
+	a.root = a
+	a.save = save
+	a.namespaces = map[string]*Admin{}
+	a.pending = map[string][]TaskConfig{}
+	a.saved = map[string][]TaskConfig{}
+	var own []TaskConfig
+	for _, c := range cfg {
+		if c.Namespace == "" {
+			own = append(own, c)
+		} else {
+			a.pending[c.Namespace] = append(a.pending[c.Namespace], c)
+		}
+	}
+	if err := a.buildAll(own); err != nil {
+		return nil, err
+	}
+	maps.Copy(a.saved, a.pending)
+	a.saved[""] = own
+	// The new tasks need no reset, and the starts, cancelled by WithOnStart,
+	// are deliberate.
+	_ = a.StartAll()
+	return a, nil
+}
+
+func newAdmin(namespace string, opts []option) *Admin {
+	return &Admin{
+		namespace: namespace,
+		opts:      opts,
+		metrics:   NewMetrics(),
+		cfg:       map[string]TaskConfig{},
+		tasks:     map[string]RestartableWithTicker[time.Time]{},
+		ready:     map[string]chan struct{}{},
+
+		quarantine: map[string]*quarantined{},
+	}
+}
+
+// Namespace returns the admin of the tasks in the namespace, opening it on
+// the first call: the namespace tasks of the initial configuration of
+// [NewAdmin] are built with the admin options followed by opts, e.g. the
+// namespace limits with [WithPool], and are started. The options of the later
+// calls are ignored.
+func (a *Admin) Namespace(name string, opts ...option) (*Admin, error) {
+	root := a.root
+	if name == "" {
+		return root, nil
+	}
+	root.mux.Lock()
+	defer root.mux.Unlock()
+	if ns, ok := root.namespaces[name]; ok {
+		return ns, nil
+	}
+	ns := newAdmin(name, append(slices.Clone(root.opts), opts...))
+	ns.root = root
+	if err := ns.buildAll(root.pending[name]); err != nil {
+		return nil, fmt.Errorf("namespace %q: %w", name, err)
+	}
+	delete(root.pending, name)
+	root.namespaces[name] = ns
+	_ = ns.StartAll()
+	return ns, nil
+}
+
+// Metrics returns the run metrics of the tasks of the admin namespace.
+func (a *Admin) Metrics() *Metrics {
+	return a.metrics
+}
+
+// StartAll starts the tasks of the admin namespace in the configuration
+// order. The tasks, configured with [TaskConfig] After, are started in
+// background once the tasks they depend on have completed their first
+// successful run, unless the tasks are stopped first. With [WithStartSpread],
+// the other tasks are started in background over the window. It returns the
+// joined errors of the tasks, which have not been started immediately, e.g.
+// wrapping [ErrNeedsReset].
+func (a *Admin) StartAll() error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	var o options
+	for _, opt := range a.opts {
+		opt(&o)
+	}
+	var spread []string
+	var errs []error
+	for _, name := range a.order {
+		if o.startSpread > 0 && len(a.cfg[name].After) == 0 {
+			spread = append(spread, name)
+			continue
+		}
+		errs = append(errs, a.startLocked(name))
+	}
+	for i, name := range spread {
+		offset := spreadOffset(o.startSpread, o.startSpreadRandom, i, len(spread))
+		if offset == 0 {
+			errs = append(errs, a.startLocked(name))
+			continue
+		}
+		a.startLater(name, offset)
+	}
+	return errors.Join(errs...)
+}
+
+// startLater starts the named task after the delay, unless the tasks are
+// stopped, or the task is deleted first. Must be called under the lock.
+func (a *Admin) startLater(name string, delay time.Duration) {
+	starts := a.startsContext()
+	task := a.tasks[name]
+	timer := time.AfterFunc(timescale.Scale(delay), func() {
+		a.mux.Lock()
+		defer a.mux.Unlock()
+		if starts.Err() == nil && a.tasks[name] == task {
+			_ = task.StartE()
+		}
+	})
+	context.AfterFunc(starts, func() { timer.Stop() })
+}
+
+// startsContext returns the context of the background starts, cancelled by the
+// stop of all tasks. Must be called under the lock.
+func (a *Admin) startsContext() context.Context {
+	if a.starts == nil {
+		a.starts, a.cancelStarts = context.WithCancel(context.Background())
+	}
+	return a.starts
+}
+
+// StartInOrder starts the named tasks of the admin namespace in the given
+// order, and then the others as [Admin.StartAll] does. It returns an error,
+// starting nothing, if a name is unknown, or the joined errors of the tasks,
+// which have not been started.
+func (a *Admin) StartInOrder(names ...string) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	for _, name := range names {
+		if _, exists := a.tasks[name]; !exists {
+			return fmt.Errorf("task %q: %w", name, ErrUnknownTask)
+		}
+	}
+	var errs []error
+	for _, name := range names {
+		errs = append(errs, a.startLocked(name))
+	}
+	for _, name := range a.order {
+		if !slices.Contains(names, name) {
+			errs = append(errs, a.startLocked(name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// startLocked starts the named task, or arranges its start after the first
+// successful runs of its dependencies, and returns the error of the immediate
+// start. Must be called under the lock.
+func (a *Admin) startLocked(name string) error {
+	task := a.tasks[name]
+	after := a.cfg[name].After
+	if len(after) == 0 {
+		if err := task.StartE(); err != nil {
+			return fmt.Errorf("task %q: %w", name, err)
+		}
+		return nil
+	}
+	var ready []chan struct{}
+	for _, dependency := range after {
+		if ch, ok := a.ready[dependency]; ok {
+			ready = append(ready, ch)
+		}
+	}
+	starts := a.startsContext()
+	go func() {
+		for _, ch := range ready {
+			select {
+			case <-ch:
+			case <-starts.Done():
+				return
+			}
+		}
+		a.mux.Lock()
+		defer a.mux.Unlock()
+		// The task, failed meanwhile, is quarantined, and is reset on the
+		// requeue.
+		if starts.Err() == nil {
+			_ = task.StartE()
+		}
+	}()
+	return nil
+}
+
+// AwaitFirstSuccess waits for the named task of the admin namespace to
+// complete its first successful run, and returns the context cause if the
+// context is done first.
+func (a *Admin) AwaitFirstSuccess(ctx context.Context, name string) error {
+	a.mux.Lock()
+	ready, exists := a.ready[name]
+	a.mux.Unlock()
+	if !exists {
+		return fmt.Errorf("task %q: %w", name, ErrUnknownTask)
+	}
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// DefaultDrainTimeout is the time [Admin.StopAll] waits for the runs of the
+// [ShutdownNormal] tasks to finish.
+const DefaultDrainTimeout = 10 * time.Second
+
+// TaskStopReport tells how a task has been stopped by [Admin.StopAllContext].
+type TaskStopReport struct {
+	Name string
+	// Namespace is the namespace of the task, see [Admin.Namespace].
+	Namespace string
+	Shutdown  ShutdownClass
+	// Duration is the time from the stop request till the task has been
+	// stopped.
+	Duration time.Duration
+	// Drained is false if the run in progress has been left unfinished: the
+	// best-effort task has been stopped during a run, or the normal task run
+	// has not finished before the deadline.
+	Drained bool
+	// Err is the error of the task before the stop, see
+	// [RestartableWithTicker] Error: nil for a running task, or the cause of
+	// the earlier stop, e.g. the task failure.
+	Err error
+}
+
+// StopReport is the list of the task stop reports, sorted by the namespace
+// and the task name.
+type StopReport []TaskStopReport
+
+// Undrained returns the names of the tasks, which runs have been left
+// unfinished, prefixed with the namespace, if any, e.g. "tenant/refresh".
+func (r StopReport) Undrained() []string {
+	var names []string
+	for _, task := range r {
+		if !task.Drained {
+			names = append(names, task.qualifiedName())
+		}
+	}
+	return names
+}
+
+// qualifiedName returns the task name, prefixed with the namespace, if any.
+func (r TaskStopReport) qualifiedName() string {
+	if r.Namespace == "" {
+		return r.Name
+	}
+	return r.Namespace + "/" + r.Name
+}
+
+// StopAll stops the tasks as [Admin.StopAllContext] does, with
+// [DefaultDrainTimeout], and returns the report.
+func (a *Admin) StopAll() StopReport {
+	ctx, cancel := context.WithTimeout(context.Background(), timescale.Scale(DefaultDrainTimeout))
+	defer cancel()
+	report, _ := a.StopAllContext(ctx)
+	return report
+}
+
+// StopAllContext stops the tasks of the admin namespace according to their
+// shutdown classes: the [ShutdownBestEffort] tasks are stopped without
+// waiting, the [ShutdownNormal] tasks are stopped after the runs in progress,
+// waited for until the context is done, and the [ShutdownCritical] tasks are
+// stopped after the runs in progress, waited for indefinitely. The root admin,
+// returned by [NewAdmin], stops the tasks of the open namespaces as well, so
+// that it shuts down all the tasks. It returns the report of every task, and
+// an error, naming the normal tasks with the unfinished runs, if the context
+// is done first.
+func (a *Admin) StopAllContext(ctx context.Context) (StopReport, error) {
+	admins := []*Admin{a}
+	if a.root == a {
+		a.mux.Lock()
+		for _, ns := range a.namespaces {
+			admins = append(admins, ns)
+		}
+		a.mux.Unlock()
+	}
+	var report StopReport
+	var mux sync.Mutex
+	var wg sync.WaitGroup
+	for _, admin := range admins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := admin.stopAll(ctx)
+			mux.Lock()
+			defer mux.Unlock()
+			report = append(report, r...)
+		}()
+	}
+	wg.Wait()
+	slices.SortFunc(report, func(x, y TaskStopReport) int {
+		return cmp.Or(strings.Compare(x.Namespace, y.Namespace), strings.Compare(x.Name, y.Name))
+	})
+	var cancelled []string
+	for _, r := range report {
+		if !r.Drained && r.Shutdown == ShutdownNormal {
+			cancelled = append(cancelled, r.qualifiedName())
+		}
+	}
+	if len(cancelled) == 0 {
+		return report, nil
+	}
+	return report, fmt.Errorf("tasks %s did not finish: %w", strings.Join(cancelled, ", "), context.Cause(ctx))
+}
+
+// stopAll stops the tasks of the admin namespace as [Admin.StopAllContext]
+// does, and returns the unsorted report.
+func (a *Admin) stopAll(ctx context.Context) StopReport {
+	a.mux.Lock()
+	if a.starts != nil {
+		a.cancelStarts()
+		a.starts = nil
+	}
+	for _, q := range a.quarantine {
+		if q.timer != nil {
+			q.timer.Stop()
+		}
+	}
+	tasks := maps.Clone(a.tasks)
+	classes := make(map[string]ShutdownClass, len(a.cfg))
+	for name, c := range a.cfg {
+		classes[name] = c.Shutdown
+	}
+	a.mux.Unlock()
+
+	// done checks whether a run is in progress without waiting.
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	report := make(StopReport, 0, len(tasks))
+	var mux sync.Mutex
+	var wg sync.WaitGroup
+	for name, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := TaskStopReport{Name: name, Namespace: a.namespace, Shutdown: classes[name], Err: task.Error()}
+			if r.Shutdown == "" {
+				r.Shutdown = ShutdownBestEffort
+			}
+			switch r.Shutdown {
+			case ShutdownNormal:
+				task.StopAfterCurrentRun()
+				r.Drained = waitDrained(ctx, task)
+				if !r.Drained {
+					task.Stop()
+				}
+			case ShutdownCritical:
+				task.StopAfterCurrentRun()
+				r.Drained = waitDrained(context.Background(), task)
+			default:
+				task.Stop()
+				r.Drained = waitDrained(done, task)
+			}
+			r.Duration = time.Since(start)
+			mux.Lock()
+			defer mux.Unlock()
+			report = append(report, r)
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// buildAll builds the tasks of the configuration without starting them.
+func (a *Admin) buildAll(cfg []TaskConfig) error {
+	for _, c := range cfg {
+		if _, exists := a.cfg[c.Name]; exists {
+			return fmt.Errorf("task %q: %w", c.Name, ErrTaskExists)
+		}
+		task, ready, err := a.build(c)
+		if err != nil {
+			return err
+		}
+		a.add(c, task, ready)
+	}
+	return nil
+}
+
+// build builds the task, which dependencies must be configured before, and
+// returns the channel, closed on its first successful run.
+func (a *Admin) build(c TaskConfig) (RestartableWithTicker[time.Time], chan struct{}, error) {
+	for _, dependency := range c.After {
+		if _, exists := a.cfg[dependency]; !exists {
+			return nil, nil, fmt.Errorf("task %q: after %q: %w", c.Name, dependency, ErrUnknownTask)
+		}
+	}
+	ready := make(chan struct{})
+	var once sync.Once
+	var task RestartableWithTicker[time.Time]
+	tasks, err := BuildAll([]TaskConfig{c}, append(slices.Clone(a.opts),
+		WithMetrics(a.metrics),
+		withOnSuccess(func() { once.Do(func() { close(ready) }) }),
+		withOnFailure(func(err error) { a.quarantineTask(c.Name, task, err) }))...)
+	if err != nil {
+		return nil, nil, err
+	}
+	task = tasks[c.Name]
+	return task, ready, nil
+}
+
+// add adds the built task. Must be called under the lock, or before the admin
+// is shared.
+func (a *Admin) add(c TaskConfig, task RestartableWithTicker[time.Time], ready chan struct{}) {
+	a.cfg[c.Name] = c
+	a.tasks[c.Name] = task
+	a.ready[c.Name] = ready
+	a.order = append(a.order, c.Name)
+}
+
+// Config returns the configuration of the tasks of the admin namespace, sorted
+// by name.
+func (a *Admin) Config() []TaskConfig {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	return a.config()
+}
+
+// TaskStatus is the entry of the admin task listing: the configuration of the
+// task with the setup of the built task and the time of its next run.
+type TaskStatus struct {
+	TaskConfig
+	Setup TaskSetup
+	// NextRun is the time of the next scheduled run, or zero time, see
+	// [RestartableWithTicker] NextRun.
+	NextRun time.Time
+}
+
+type taskStatusJSON struct {
+	Setup   TaskSetup  `json:"setup"`
+	NextRun *time.Time `json:"next_run,omitempty"`
+}
+
+func (s *TaskStatus) UnmarshalJSON(data []byte) error {
+	var raw taskStatusJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := s.TaskConfig.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	s.Setup = raw.Setup
+	s.NextRun = time.Time{}
+	if raw.NextRun != nil {
+		s.NextRun = *raw.NextRun
+	}
+	return nil
+}
+
+// MarshalJSON adds the fields of the status to the ones of the configuration.
+func (s TaskStatus) MarshalJSON() ([]byte, error) {
+	cfg, err := json.Marshal(s.TaskConfig)
+	if err != nil {
+		return nil, err
+	}
+	raw := taskStatusJSON{Setup: s.Setup}
+	if !s.NextRun.IsZero() {
+		raw.NextRun = &s.NextRun
+	}
+	status, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(cfg, &fields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(status, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// Status returns the status of the tasks of the admin namespace, sorted by
+// name.
+func (a *Admin) Status() []TaskStatus {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	cfg := a.config()
+	status := make([]TaskStatus, len(cfg))
+	for i, c := range cfg {
+		task := a.tasks[c.Name]
+		status[i] = TaskStatus{TaskConfig: c, Setup: task.Config(), NextRun: task.NextRun()}
+	}
+	return status
+}
+
+// config must be called under the lock.
+func (a *Admin) config() []TaskConfig {
+	cfg := make([]TaskConfig, 0, len(a.cfg))
+	for _, c := range a.cfg {
+		cfg = append(cfg, c)
+	}
+	slices.SortFunc(cfg, func(x, y TaskConfig) int { return strings.Compare(x.Name, y.Name) })
+	return cfg
+}
+
+// Create builds and starts the task of the configuration in the admin
+// namespace, as [Admin.StartAll] does, and saves the configuration. The task
+// is not created, if its start is refused, e.g. by [WithOnStart], or the
+// configuration cannot be saved.
+func (a *Admin) Create(c TaskConfig) error {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	c.Namespace = a.namespace
+	if _, exists := a.cfg[c.Name]; exists {
+		return fmt.Errorf("task %q: %w", c.Name, ErrTaskExists)
+	}
+	task, ready, err := a.build(c)
+	if err != nil {
+		return err
+	}
+	a.add(c, task, ready)
+	if err := a.startLocked(c.Name); err != nil {
+		a.remove(c.Name)
+		return err
+	}
+	if err := a.saveLocked(); err != nil {
+		a.remove(c.Name)
+		return err
+	}
+	return nil
+}
+
+// Delete stops the named task of the admin namespace, and saves the
+// configuration without it. It returns false if there is no such task, and an
+// error, wrapping [ErrTaskInUse], if other tasks depend on it, so that they
+// are neither left waiting for it to start, nor fail to build on restart.
+func (a *Admin) Delete(name string) (bool, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	c, exists := a.cfg[name]
+	if !exists {
+		return false, nil
+	}
+	for _, dependent := range a.order {
+		if slices.Contains(a.cfg[dependent].After, name) {
+			return true, fmt.Errorf("task %q: after by %q: %w", name, dependent, ErrTaskInUse)
+		}
+	}
+	delete(a.cfg, name)
+	if err := a.saveLocked(); err != nil {
+		a.cfg[name] = c
+		return true, err
+	}
+	a.remove(name)
+	return true, nil
+}
+
+// remove stops the task, and removes it from the admin. Must be called under
+// the lock.
+func (a *Admin) remove(name string) {
+	a.tasks[name].Stop()
+	delete(a.cfg, name)
+	delete(a.tasks, name)
+	delete(a.ready, name)
+	a.release(name)
+	a.order = slices.DeleteFunc(a.order, func(n string) bool { return n == name })
+}
+
+// TaskPatch is the change of the task configuration by [Admin.Reconfigure].
+// The nil fields are not changed.
+type TaskPatch struct {
+	Timeout  *time.Duration
+	Attempts *int
+}
+
+// Reconfigure changes the timeout and the number of attempts of the named task
+// of the admin namespace with [RestartableWithTicker] Reload, without
+// restarting it, and saves the configuration. Only the fields, provided by the
+// patch, are changed, and the zero ones restore the options, given to the
+// admin. The run in progress is not affected. It returns false if there is no
+// such task, and an error, wrapping [ErrInvalidPatch], if the timeout or the
+// attempts are negative.
+func (a *Admin) Reconfigure(name string, patch TaskPatch) (bool, error) {
+	if patch.Timeout != nil && *patch.Timeout < 0 {
+		return false, fmt.Errorf("negative timeout %v: %w", *patch.Timeout, ErrInvalidPatch)
+	}
+	if patch.Attempts != nil && *patch.Attempts < 0 {
+		return false, fmt.Errorf("negative attempts %d: %w", *patch.Attempts, ErrInvalidPatch)
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	previous, exists := a.cfg[name]
+	if !exists {
+		return false, nil
+	}
+	c := previous
+	if patch.Timeout != nil {
+		c.Timeout = *patch.Timeout
+	}
+	if patch.Attempts != nil {
+		c.Attempts = *patch.Attempts
+	}
+	a.cfg[name] = c
+	if err := a.saveLocked(); err != nil {
+		a.cfg[name] = previous
+		return true, err
+	}
+	// The zero values fall back to the admin options, as configOptions does.
+	var base options
+	for _, opt := range a.opts {
+		opt(&base)
+	}
+	var opts []option
+	if patch.Timeout != nil {
+		timeout := base.timeout
+		if c.Timeout > 0 {
+			timeout = c.Timeout
+		}
+		opts = append(opts, WithTimeout(timeout))
+	}
+	if patch.Attempts != nil {
+		retry := base.retry
+		if c.Attempts > 1 {
+			retry = retryPolicy(c)
+		}
+		opts = append(opts, WithRetry(retry))
+	}
+	// The options are not of a tick type, and cannot be refused.
+	_ = a.tasks[name].Reload(opts...)
+	return true, nil
+}
+
+// TriggerNow runs the named task of the admin namespace out of band, see
+// [RestartableWithTicker] TriggerNow.
+func (a *Admin) TriggerNow(ctx context.Context, name string, wait bool) error {
+	a.mux.Lock()
+	task, exists := a.tasks[name]
+	a.mux.Unlock()
+	if !exists {
+		return fmt.Errorf("task %q: %w", name, ErrUnknownTask)
+	}
+	return task.TriggerNow(ctx, wait)
+}
+
+// QuarantinedTask is the task of an [Admin], stopped by the task function
+// error, wrapping [utils.ErrStopped], and kept stopped until it is requeued.
+type QuarantinedTask struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	// Err is the task function error, which stopped the task.
+	Err error `json:"-"`
+	// Error is the message of Err.
+	Error string `json:"error"`
+	// Requeue is the time of the automatic requeue after the [TaskConfig]
+	// Cooldown, or zero time.
+	Requeue time.Time `json:"requeue"`
+}
+
+type quarantined struct {
+	QuarantinedTask
+	timer *time.Timer
+}
+
+// quarantineTask parks the task, stopped by the failure, unless it has been
+// deleted or restarted since, and arranges its requeue after the cooldown.
+func (a *Admin) quarantineTask(name string, task RestartableWithTicker[time.Time], err error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if a.tasks[name] != task || task.Error() != err {
+		return
+	}
+	a.release(name)
+	q := &quarantined{QuarantinedTask: QuarantinedTask{Name: name, Time: time.Now(), Err: err, Error: err.Error()}}
+	if cooldown := a.cfg[name].Cooldown; cooldown > 0 {
+		cooldown = timescale.Scale(cooldown)
+		q.Requeue = q.Time.Add(cooldown)
+		q.timer = time.AfterFunc(cooldown, func() {
+			a.mux.Lock()
+			defer a.mux.Unlock()
+			if a.quarantine[name] == q {
+				a.requeueLocked(name)
+			}
+		})
+	}
+	a.quarantine[name] = q
+}
+
+// release removes the task from the quarantine. Must be called under the lock.
+func (a *Admin) release(name string) {
+	if q, ok := a.quarantine[name]; ok {
+		if q.timer != nil {
+			q.timer.Stop()
+		}
+		delete(a.quarantine, name)
+	}
+}
+
+// requeueLocked restarts the quarantined task. Must be called under the lock.
+func (a *Admin) requeueLocked(name string) {
+	a.release(name)
+	task := a.tasks[name]
+	task.Reset()
+	task.Start()
+}
+
+// Quarantined returns the tasks of the admin namespace, which have been
+// stopped by a failure and not requeued yet, sorted by name.
+func (a *Admin) Quarantined() []QuarantinedTask {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	tasks := make([]QuarantinedTask, 0, len(a.quarantine))
+	for _, q := range a.quarantine {
+		tasks = append(tasks, q.QuarantinedTask)
+	}
+	slices.SortFunc(tasks, func(x, y QuarantinedTask) int { return strings.Compare(x.Name, y.Name) })
+	return tasks
+}
+
+// Requeue resets and restarts the quarantined task of the admin namespace
+// before its [TaskConfig] Cooldown, if any. The ticker of the task is restarted
+// if it has been stopped, see [WithTickerStop]. It returns false if the task is
+// not quarantined.
+func (a *Admin) Requeue(name string) bool {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if _, ok := a.quarantine[name]; !ok {
+		return false
+	}
+	a.requeueLocked(name)
+	return true
+}
+
+// WaitContext waits for the runs in progress of all tasks to finish, and
+// returns an error, naming the tasks with the unfinished runs and wrapping the
+// context cause, if the context is done first. See [RestartableWithTicker]
+// WaitContext.
+func (a *Admin) WaitContext(ctx context.Context) error {
+	a.mux.Lock()
+	tasks := maps.Clone(a.tasks)
+	a.mux.Unlock()
+
+	var mux sync.Mutex
+	var stuck []string
+	var wg sync.WaitGroup
+	for name, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !waitDrained(ctx, task) {
+				mux.Lock()
+				defer mux.Unlock()
+				stuck = append(stuck, name)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(stuck) == 0 {
+		return nil
+	}
+	slices.Sort(stuck)
+	return fmt.Errorf("tasks %s did not finish: %w", strings.Join(stuck, ", "), context.Cause(ctx))
+}
+
+// waitDrained waits for the runs in progress of the task to finish, and tells
+// whether they have finished before the context is done. The task, which has
+// never been started, e.g. waiting for its dependencies, has none.
+func waitDrained(ctx context.Context, task RestartableWithTicker[time.Time]) bool {
+	err := task.WaitContext(ctx)
+	return err == nil || errors.Is(err, ErrNotStarted)
+}
+
+// WaitTimeout is [Admin.WaitContext] with a timeout.
+func (a *Admin) WaitTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return a.WaitContext(ctx)
+}
+
+// saveLocked saves the configuration of the admin namespace with the ones of
+// the other namespaces. Must be called under the lock.
+func (a *Admin) saveLocked() error {
+	root := a.root
+	root.saveMux.Lock()
+	defer root.saveMux.Unlock()
+	if root.save == nil {
+		return nil
+	}
+	previous, existed := root.saved[a.namespace]
+	root.saved[a.namespace] = a.config()
+	namespaces := slices.Sorted(maps.Keys(root.saved))
+	var all []TaskConfig
+	for _, ns := range namespaces {
+		all = append(all, root.saved[ns]...)
+	}
+	if err := root.save(all); err != nil {
+		if existed {
+			root.saved[a.namespace] = previous
+		} else {
+			delete(root.saved, a.namespace)
+		}
+		return fmt.Errorf("failed to save the task configuration: %w", err)
+	}
+	return nil
+}
+
+// Handler returns the HTTP handler of the admin API:
+//   - GET /tasks lists the task configurations with the task setup and the
+//     next run time, see [TaskStatus];
+//   - POST /tasks creates a task from the [TaskConfig] in the request body;
+//   - PATCH /tasks/{name} changes the timeout and the attempts of the task to
+//     the ones of the request body, e.g. {"timeout": "30s", "attempts": 5},
+//     keeping the fields, which are not in the body;
+//   - DELETE /tasks/{name} deletes the task, unless other tasks depend on it;
+//   - POST /tasks/{name}/run runs the task now, and waits for the run to
+//     finish with the wait=true query parameter;
+//   - GET /quarantine lists the quarantined tasks;
+//   - POST /quarantine/{name} requeues the quarantined task.
+func (a *Admin) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tasks", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(a.Status())
+	})
+	mux.HandleFunc("POST /tasks", func(w http.ResponseWriter, r *http.Request) {
+		var c TaskConfig
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := a.Create(c); err != nil {
+			status := http.StatusBadRequest
+			switch {
+			case errors.Is(err, ErrUnknownTask):
+				status = http.StatusNotFound
+			case errors.Is(err, ErrTaskExists):
+				status = http.StatusConflict
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("PATCH /tasks/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var raw struct {
+			Timeout  *string `json:"timeout"`
+			Attempts *int    `json:"attempts"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		patch := TaskPatch{Attempts: raw.Attempts}
+		if raw.Timeout != nil {
+			timeout, err := parseDuration("timeout", *raw.Timeout)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			patch.Timeout = &timeout
+		}
+		found, err := a.Reconfigure(r.PathValue("name"), patch)
+		switch {
+		case errors.Is(err, ErrInvalidPatch):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case !found:
+			http.NotFound(w, r)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("DELETE /tasks/{name}", func(w http.ResponseWriter, r *http.Request) {
+		found, err := a.Delete(r.PathValue("name"))
+		switch {
+		case errors.Is(err, ErrTaskInUse):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case !found:
+			http.NotFound(w, r)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("POST /tasks/{name}/run", func(w http.ResponseWriter, r *http.Request) {
+		wait := r.URL.Query().Get("wait") == "true"
+		err := a.TriggerNow(r.Context(), r.PathValue("name"), wait)
+		switch {
+		case errors.Is(err, ErrUnknownTask):
+			http.NotFound(w, r)
+		case errors.Is(err, ErrNotRunning):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		case wait:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusAccepted)
+		}
+	})
+	mux.HandleFunc("GET /quarantine", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(a.Quarantined())
+	})
+	mux.HandleFunc("POST /quarantine/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if !a.Requeue(r.PathValue("name")) {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
@@ -0,0 +1,422 @@
+package goticks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestAdmin(t *testing.T) {
+	runs := make(chan string, 10)
+	registerForTest("test-admin", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error {
+			runs <- c.Name
+			return nil
+		}, nil
+	})
+
+	var saved []TaskConfig
+	admin, err := NewAdmin([]TaskConfig{{Name: "initial", Task: "test-admin", Every: time.Hour}},
+		func(cfg []TaskConfig) error {
+			saved = cfg
+			return nil
+		}, WithTickerStop())
+	assert.That(t, assert.NoError(err), assert.Equal("initial", <-runs))
+
+	server := httptest.NewServer(admin.Handler())
+	defer server.Close()
+
+	post := func(body string) int {
+		resp, err := http.Post(server.URL+"/tasks", "application/json", strings.NewReader(body))
+		assert.That(t, assert.NoError(err))
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	del := func(name string) int {
+		req, _ := http.NewRequest(http.MethodDelete, server.URL+"/tasks/"+name, nil)
+		resp, err := http.DefaultClient.Do(req)
+		assert.That(t, assert.NoError(err))
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.That(t,
+		assert.Equal(http.StatusCreated, post(`{"name": "created", "task": "test-admin", "every": "1h"}`)),
+		assert.Equal("created", <-runs),
+		assert.Equal(2, len(saved)),
+		assert.Equal(http.StatusConflict, post(`{"name": "created", "task": "test-admin", "every": "1h"}`)),
+		assert.Equal(http.StatusNotFound, post(`{"name": "other", "task": "unknown", "every": "1h"}`)),
+		assert.Equal(http.StatusBadRequest, post(`{"name": "other", "task": "test-admin"}`)))
+
+	resp, err := http.Get(server.URL + "/tasks")
+	assert.That(t, assert.NoError(err))
+	var listed []TaskStatus
+	assert.That(t, assert.NoError(json.NewDecoder(resp.Body).Decode(&listed)))
+	resp.Body.Close()
+	assert.That(t,
+		assert.Equal(2, len(listed)),
+		assert.Equal("created", listed[0].Name),
+		assert.Equal(time.Hour, listed[0].Every),
+		assert.Equal("created", listed[0].Setup.Name),
+		assert.Equal("every 1h0m0s", listed[0].Setup.Schedule),
+		assert.True(slices.Contains(listed[0].Setup.Wrappers, "Classify")),
+		assert.True(listed[0].NextRun.After(time.Now().Add(59*time.Minute))))
+
+	assert.That(t, assert.NoError(admin.WaitTimeout(time.Second)))
+
+	patch := func(name, body string) int {
+		req, _ := http.NewRequest(http.MethodPatch, server.URL+"/tasks/"+name, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		assert.That(t, assert.NoError(err))
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.That(t,
+		assert.Equal(http.StatusNoContent, patch("created", `{"timeout": "30s", "attempts": 5}`)),
+		assert.Equal(http.StatusNotFound, patch("unknown", `{"attempts": 5}`)),
+		assert.Equal(http.StatusBadRequest, patch("created", `{"timeout": "soon"}`)),
+		assert.Equal(http.StatusBadRequest, patch("created", `{"timeout": "-1s"}`)),
+		assert.Equal(http.StatusBadRequest, patch("created", `{"attempts": -1}`)),
+		assert.Equal(30*time.Second, saved[0].Timeout),
+		assert.Equal(5, saved[0].Attempts))
+	assert.That(t,
+		assert.Equal(http.StatusNoContent, patch("created", `{"attempts": 3}`)),
+		assert.Equal(30*time.Second, saved[0].Timeout),
+		assert.Equal(3, saved[0].Attempts))
+	setup := admin.tasks["created"].Config()
+	assert.That(t,
+		assert.Equal(30*time.Second, setup.Timeout),
+		assert.Equal("utils.ExponentialBackoffPolicy", setup.Retry))
+	var timeout time.Duration
+	attempts := 0
+	found, err := admin.Reconfigure("created", TaskPatch{Timeout: &timeout, Attempts: &attempts})
+	setup = admin.tasks["created"].Config()
+	assert.That(t,
+		assert.True(found),
+		assert.NoError(err),
+		assert.Equal(time.Duration(0), setup.Timeout),
+		assert.Equal("", setup.Retry))
+
+	run := func(name string) int {
+		resp, err := http.Post(server.URL+"/tasks/"+name+"/run?wait=true", "", nil)
+		assert.That(t, assert.NoError(err))
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.That(t,
+		assert.Equal(http.StatusNoContent, run("created")),
+		assert.Equal("created", <-runs),
+		assert.Equal(http.StatusNotFound, run("unknown")))
+
+	assert.That(t,
+		assert.Equal(http.StatusNoContent, del("initial")),
+		assert.Equal(http.StatusNotFound, del("initial")),
+		assert.Equal(1, len(saved)),
+		assert.Equal("created", saved[0].Name))
+}
+
+func TestAdmin_Reconfigure(t *testing.T) {
+	registerForTest("test-reconfigure", func(TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error { return nil }, nil
+	})
+	admin, err := NewAdmin([]TaskConfig{{Name: "patched", Task: "test-reconfigure", Every: time.Hour}},
+		nil, WithTimeout(time.Minute), WithTickerStop())
+	assert.That(t, assert.NoError(err))
+	defer admin.StopAll()
+	setup := func() TaskSetup { return admin.tasks["patched"].Config() }
+
+	attempts := 3
+	_, err = admin.Reconfigure("patched", TaskPatch{Attempts: &attempts})
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal(time.Minute, setup().Timeout),
+		assert.Equal("utils.ExponentialBackoffPolicy", setup().Retry))
+
+	timeout := time.Second
+	_, err = admin.Reconfigure("patched", TaskPatch{Timeout: &timeout})
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal(time.Second, setup().Timeout),
+		assert.Equal("utils.ExponentialBackoffPolicy", setup().Retry))
+
+	// The zero timeout restores the admin option.
+	timeout = 0
+	_, err = admin.Reconfigure("patched", TaskPatch{Timeout: &timeout})
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal(time.Minute, setup().Timeout))
+}
+
+func TestAdmin_Namespace(t *testing.T) {
+	runs := make(chan string, 10)
+	registerForTest("test-tenant", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error {
+			runs <- c.Namespace + "/" + c.Name
+			return nil
+		}, nil
+	})
+
+	var saved []TaskConfig
+	admin, err := NewAdmin([]TaskConfig{
+		{Name: "job", Task: "test-tenant", Every: time.Hour, Namespace: "a"},
+	}, func(cfg []TaskConfig) error {
+		saved = cfg
+		return nil
+	}, WithTickerStop())
+	assert.That(t, assert.NoError(err))
+
+	tenantA, err := admin.Namespace("a")
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal("a/job", <-runs))
+	tenantB, _ := admin.Namespace("b")
+	assert.That(t,
+		assert.NoError(tenantB.Create(TaskConfig{Name: "job", Task: "test-tenant", Every: time.Hour})),
+		assert.Equal("b/job", <-runs),
+		assert.Equal(2, len(saved)),
+		assert.Equal("a", saved[0].Namespace),
+		assert.Equal("b", saved[1].Namespace))
+
+	again, _ := admin.Namespace("a")
+	assert.That(t, assert.Equal(tenantA, again))
+
+	tenantA.StopAll()
+	assert.That(t, assert.NoError(tenantA.WaitTimeout(time.Second)))
+	var b strings.Builder
+	assert.That(t, assert.NoError(tenantA.Metrics().WriteMetrics(&b)))
+	assert.That(t,
+		assert.True(strings.Contains(b.String(), `goticks_running{task="job"} 0`)),
+		assert.True(strings.Contains(b.String(), `goticks_runs_total{task="job",outcome="executed"} 1`)))
+	b.Reset()
+	assert.That(t, assert.NoError(tenantB.Metrics().WriteMetrics(&b)))
+	assert.That(t, assert.True(strings.Contains(b.String(), `goticks_running{task="job"} 1`)))
+
+	// The root admin stops the namespaces too.
+	report := admin.StopAll()
+	assert.That(t,
+		assert.Equal(2, len(report)),
+		assert.Equal("a", report[0].Namespace),
+		assert.Equal("b", report[1].Namespace),
+		assert.Equal("job", report[1].Name),
+		assert.Equal(0, len(report.Undrained())),
+		assert.ErrorIs(tenantB.TriggerNow(context.Background(), "job", true), ErrNotRunning))
+}
+
+func TestAdmin_StopAllContext(t *testing.T) {
+	started := make(chan string, 3)
+	release := make(chan struct{})
+	var mux sync.Mutex
+	finished := map[string]bool{}
+	registerForTest("test-shutdown", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error {
+			started <- c.Name
+			<-release
+			mux.Lock()
+			defer mux.Unlock()
+			finished[c.Name] = true
+			return nil
+		}, nil
+	})
+	cfg, err := LoadConfig(strings.NewReader(`[
+		{"name": "effort", "task": "test-shutdown", "every": "1h"},
+		{"name": "normal", "task": "test-shutdown", "every": "1h", "shutdown": "normal"},
+		{"name": "critical", "task": "test-shutdown", "every": "1h", "shutdown": "critical"}]`))
+	assert.That(t, assert.NoError(err))
+	admin, err := NewAdmin(cfg, nil)
+	assert.That(t, assert.NoError(err))
+	for range 3 {
+		<-started
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	time.AfterFunc(50*time.Millisecond, func() {
+		mux.Lock()
+		defer mux.Unlock()
+		close(release)
+	})
+	report, err := admin.StopAllContext(ctx)
+	assert.That(t,
+		assert.ErrorIs(err, context.DeadlineExceeded),
+		assert.Equal("tasks normal did not finish: context deadline exceeded", err.Error()),
+		assert.EqualSlices([]string{"effort", "normal"}, report.Undrained()),
+		assert.Equal(3, len(report)))
+	critical := report[0]
+	assert.That(t,
+		assert.Equal("critical", critical.Name),
+		assert.Equal(ShutdownCritical, critical.Shutdown),
+		assert.True(critical.Drained),
+		assert.True(critical.Duration >= 40*time.Millisecond),
+		assert.NoError(critical.Err),
+		assert.Equal(ShutdownBestEffort, report[1].Shutdown))
+	mux.Lock()
+	assert.That(t, assert.True(finished["critical"]))
+	mux.Unlock()
+
+	_, err = LoadConfig(strings.NewReader(`[{"name": "x", "every": "1h", "shutdown": "never"}]`))
+	assert.That(t, assert.True(err != nil))
+}
+
+func TestAdmin_StartAll(t *testing.T) {
+	var mux sync.Mutex
+	var events []string
+	attempts := 0
+	registerForTest("test-ordered", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error {
+			mux.Lock()
+			defer mux.Unlock()
+			if c.Name == "migrate" {
+				if attempts++; attempts < 3 {
+					events = append(events, "migrate failed")
+					return errors.New("not yet")
+				}
+			}
+			events = append(events, c.Name)
+			return nil
+		}, nil
+	})
+	cfg, err := LoadConfig(strings.NewReader(`[
+		{"name": "migrate", "task": "test-ordered", "every": "5ms"},
+		{"name": "sync", "task": "test-ordered", "every": "1h", "after": ["migrate"]}]`))
+	assert.That(t, assert.NoError(err))
+	admin, err := NewAdmin(cfg, nil)
+	assert.That(t, assert.NoError(err))
+	defer admin.StopAll()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.That(t,
+		assert.NoError(admin.AwaitFirstSuccess(ctx, "migrate")),
+		assert.NoError(admin.AwaitFirstSuccess(ctx, "sync")),
+		assert.ErrorIs(admin.AwaitFirstSuccess(ctx, "unknown"), ErrUnknownTask),
+		assert.ErrorIs(admin.StartInOrder("sync", "unknown"), ErrUnknownTask))
+	mux.Lock()
+	assert.That(t, assert.EqualSlices([]string{"migrate failed", "migrate failed", "migrate"}, events[:3]))
+	assert.That(t, assert.True(slices.Index(events, "sync") > 2))
+	mux.Unlock()
+
+	found, err := admin.Delete("migrate")
+	assert.That(t,
+		assert.True(found),
+		assert.ErrorIs(err, ErrTaskInUse),
+		assert.Equal(`task "migrate": after by "sync": task is in use`, err.Error()))
+	_, err = admin.Delete("sync")
+	assert.That(t, assert.NoError(err))
+	_, err = admin.Delete("migrate")
+	assert.That(t, assert.NoError(err))
+
+	_, err = NewAdmin([]TaskConfig{{Name: "sync", Task: "test-ordered", Every: time.Hour, After: []string{"migrate"}}}, nil)
+	assert.That(t, assert.ErrorIs(err, ErrUnknownTask))
+}
+
+func TestAdmin_CreateRefused(t *testing.T) {
+	registerForTest("test-refused", func(TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error { return nil }, nil
+	})
+	saves := 0
+	admin, err := NewAdmin(nil, func([]TaskConfig) error {
+		saves++
+		return nil
+	}, WithOnStart(func() error { return utils.ErrStopped }))
+	assert.That(t, assert.NoError(err))
+
+	err = admin.Create(TaskConfig{Name: "refused", Task: "test-refused", Every: time.Hour})
+	assert.That(t,
+		assert.ErrorIs(err, utils.ErrStopped),
+		assert.True(strings.HasPrefix(err.Error(), `task "refused": `)),
+		assert.Equal(0, len(admin.Config())),
+		assert.Equal(0, len(admin.Status())),
+		assert.Equal(0, saves))
+}
+
+func TestAdmin_Quarantine(t *testing.T) {
+	runs := make(chan string, 10)
+	var mux sync.Mutex
+	failed := map[string]bool{}
+	registerForTest("test-quarantine", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error {
+			mux.Lock()
+			defer mux.Unlock()
+			if !failed[c.Name] {
+				failed[c.Name] = true
+				return fmt.Errorf("%w: corrupted", utils.ErrStopped)
+			}
+			runs <- c.Name
+			return nil
+		}, nil
+	})
+	cfg, err := LoadConfig(strings.NewReader(`[
+		{"name": "manual", "task": "test-quarantine", "every": "1h"},
+		{"name": "auto", "task": "test-quarantine", "every": "1h", "cooldown": "50ms"}]`))
+	assert.That(t, assert.NoError(err), assert.Equal(50*time.Millisecond, cfg[1].Cooldown))
+	admin, err := NewAdmin(cfg, nil, WithTickerStop())
+	assert.That(t, assert.NoError(err))
+	defer admin.StopAll()
+
+	server := httptest.NewServer(admin.Handler())
+	defer server.Close()
+
+	var quarantined []QuarantinedTask
+	for deadline := time.Now().Add(time.Second); len(quarantined) < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+		quarantined = admin.Quarantined()
+	}
+	assert.That(t,
+		assert.Equal(2, len(quarantined)),
+		assert.Equal("auto", quarantined[0].Name),
+		assert.False(quarantined[0].Requeue.IsZero()),
+		assert.Equal("manual", quarantined[1].Name),
+		assert.ErrorIs(quarantined[1].Err, utils.ErrStopped),
+		assert.Equal("stopped: corrupted", quarantined[1].Error),
+		assert.True(quarantined[1].Requeue.IsZero()))
+
+	assert.That(t, assert.Equal("auto", <-runs))
+	assert.That(t, assert.Equal(1, len(admin.Quarantined())))
+
+	requeue := func(name string) int {
+		resp, err := http.Post(server.URL+"/quarantine/"+name, "", nil)
+		assert.That(t, assert.NoError(err))
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.That(t, assert.Equal(http.StatusNoContent, requeue("manual")))
+	assert.That(t, assert.Equal("manual", <-runs))
+	assert.That(t,
+		assert.Equal(0, len(admin.Quarantined())),
+		assert.Equal(http.StatusNotFound, requeue("manual")),
+		assert.False(admin.Requeue("auto")))
+}
+
+func TestAdmin_Shaper(t *testing.T) {
+	runs := make(chan string, 2)
+	registerForTest("test-shaped", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error {
+			runs <- c.Name
+			return nil
+		}, nil
+	})
+	shaper := utils.NewShaper(1)
+	// The low priority run has to wait for the accounted start to expire.
+	assert.That(t, assert.NoError(utils.Shape[int](shaper, false, func() {})(context.Background(), 0)))
+	cfg, err := LoadConfig(strings.NewReader(`[
+		{"name": "low", "task": "test-shaped", "every": "1h", "low_priority": true},
+		{"name": "high", "task": "test-shaped", "every": "1h"}]`))
+	assert.That(t, assert.NoError(err), assert.True(cfg[0].LowPriority))
+	admin, err := NewAdmin(cfg, nil, WithShaper(shaper, false), WithTickerStop())
+	assert.That(t, assert.NoError(err))
+	defer admin.StopAll()
+	assert.That(t,
+		assert.Equal("high", <-runs),
+		assert.Equal("low", <-runs))
+}
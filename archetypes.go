@@ -0,0 +1,54 @@
+package goticks
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+// SkipReasonBlackout is the reason of the [NewJanitor] runs skipped during a
+// blackout.
+const SkipReasonBlackout = "blackout"
+
+// NewPoller returns a task that calls fn every period with the timeout, retries
+// it on failure up to the number of attempts with exponential backoff, starting
+// at one second, and skips the ticks while fn is running. Use [WithOnRun] to
+// collect the run metrics.
+//
+// Example:
+//
+//	NewPoller(time.Minute, 10*time.Second, 3, poll, WithOnRun(record)).Start()
+func NewPoller[Fn utils.Func[time.Time]](period, timeout time.Duration, attempts int, fn Fn, opts ...option) RestartableWithTicker[time.Time] {
+	return NewTask(ticker.NewTimer(period),
+		utils.NoOverlap[time.Time](
+			utils.Retry[time.Time](utils.ExponentialBackoffPolicy(attempts, time.Second),
+				utils.Timeout[time.Time](timeout, fn))),
+		opts...)
+}
+
+// NewRefresher returns a task that calls fn every period in background. A new
+// run takes over the previous one, cancelling its context, so that the newest
+// run wins. The runs are cancelled when the task stops, but not by the timeout
+// of [WithTimeout], which ends as soon as a run is started. See
+// [utils.Takeover].
+func NewRefresher[Fn utils.Func[time.Time]](period time.Duration, fn Fn, opts ...option) RestartableWithTicker[time.Time] {
+	return NewTask(ticker.NewTimer(period), utils.Takeover[time.Time](fn), opts...)
+}
+
+// NewJanitor returns a low priority task that calls fn every period, skipping
+// the ticks while fn is running or during a blackout, for which blackout
+// returns true. The errors of fn are ignored, so that they never stop the task.
+// The runs are delayed by the shaper of [WithShaper], and shed by the load
+// shedder of [WithLoadShedding], if provided.
+func NewJanitor[Fn utils.Func[time.Time]](period time.Duration, blackout func(time.Time) bool, fn Fn, opts ...option) RestartableWithTicker[time.Time] {
+	return NewTask(ticker.NewTimer(period),
+		utils.When[time.Time](func(_ context.Context, tick time.Time) bool {
+			return blackout == nil || !blackout(tick)
+		}, SkipReasonBlackout,
+			utils.NoOverlap[time.Time](
+				utils.IgnoreErr[time.Time](fn))),
+		append(slices.Clone(opts), withLowPriority())...)
+}
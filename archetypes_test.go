@@ -0,0 +1,96 @@
+package goticks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestArchetypes(t *testing.T) {
+	t.Run("poller", func(t *testing.T) {
+		deadlines := make(chan bool, 1)
+		poller := NewPoller(time.Hour, time.Minute, 3, func(ctx context.Context) {
+			_, ok := ctx.Deadline()
+			deadlines <- ok
+		}, WithTickerStop())
+		poller.Start()
+		assert.That(t, assert.True(<-deadlines))
+		poller.Stop()
+	})
+
+	t.Run("refresher", func(t *testing.T) {
+		started := make(chan struct{})
+		causes := make(chan error, 1)
+		refresher := NewRefresher(time.Hour, func(ctx context.Context) {
+			close(started)
+			<-ctx.Done()
+			causes <- context.Cause(ctx)
+		}, WithTickerStop())
+		refresher.Start()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.That(t, assert.ErrorIs(refresher.WaitContext(ctx), context.DeadlineExceeded))
+		refresher.Stop()
+		assert.That(t,
+			assert.ErrorIs(<-causes, utils.ErrStopped),
+			assert.NoError(refresher.WaitContext(context.Background())))
+	})
+
+	t.Run("refresher with timeout", func(t *testing.T) {
+		errs := make(chan error, 1)
+		refresher := NewRefresher(time.Hour, func(ctx context.Context) {
+			// The run outlives the run of the task, bounded by the timeout.
+			time.Sleep(10 * time.Millisecond)
+			errs <- ctx.Err()
+		}, WithTimeout(time.Second), WithTickerStop())
+		refresher.Start()
+		assert.That(t, assert.NoError(<-errs))
+		refresher.Stop()
+	})
+
+	t.Run("refresher failure", func(t *testing.T) {
+		errFatal := fmt.Errorf("fatal: %w", utils.ErrStopped)
+		refresher := NewRefresher(time.Hour, func() error { return errFatal }, WithTickerStop())
+		stopped := make(chan error, 1)
+		refresher.OnStop(func(cause error) { stopped <- cause })
+		refresher.Start()
+		assert.That(t,
+			assert.ErrorIs(<-stopped, errFatal),
+			assert.ErrorIs(refresher.Error(), errFatal))
+	})
+
+	t.Run("janitor", func(t *testing.T) {
+		results := make(chan utils.RunResult, 1)
+		janitor := NewJanitor(time.Hour, func(time.Time) bool { return true },
+			func() error { return errors.New("unexpected call") },
+			WithOnRun(func(r utils.RunResult) { results <- r }), WithTickerStop())
+		janitor.Start()
+		assert.That(t, assert.Equal(utils.RunResult{
+			Outcome: utils.RunSkipped,
+			Reason:  SkipReasonBlackout,
+		}, <-results))
+		janitor.Stop()
+	})
+
+	t.Run("janitor priority", func(t *testing.T) {
+		shaper := utils.NewShaper(1)
+		assert.That(t, assert.NoError(utils.Shape[int](shaper, false, func() {})(context.Background(), 0)))
+		runs := make(chan struct{}, 1)
+		janitor := NewJanitor(time.Hour, nil, func() { runs <- struct{}{} },
+			WithShaper(shaper, false), WithTickerStop())
+		janitor.Start()
+		defer janitor.Stop()
+		select {
+		case <-runs:
+			t.Fatal("the low priority run has not been delayed")
+		case <-time.After(100 * time.Millisecond):
+		}
+		<-runs
+	})
+}
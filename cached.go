@@ -0,0 +1,145 @@
+package goticks
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/parametalol/goticks/ticker"
+)
+
+// CachedTask periodically fetches a value, and serves the last successfully
+// fetched one.
+type CachedTask[T any] struct {
+	RestartableWithTicker[time.Time]
+
+	fetch   func(context.Context) (T, error)
+	mux     sync.RWMutex
+	value   T
+	updated time.Time
+	// err is the error of the last fetch, if it has failed.
+	err    error
+	policy CachePolicy
+	// now is the clock of the fetch times.
+	now func() time.Time
+
+	// revalidating is set while a background fetch is pending.
+	revalidating atomic.Bool
+}
+
+// CachePolicy tells how long the value of a [CachedTask], returned by
+// [CachedTask.Lookup], is served after its fetch, as the HTTP Cache-Control
+// max-age, stale-while-revalidate and stale-if-error directives do.
+type CachePolicy struct {
+	// TTL is the time the fetched value is fresh for. The policy is
+	// disabled if it is zero: every value is fresh.
+	TTL time.Duration
+	// StaleWhileRevalidate is the time after the TTL, during which the stale
+	// value is served, while it is fetched in background.
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is the time after the TTL, during which the stale value is
+	// served, if its fetch fails.
+	StaleIfError time.Duration
+}
+
+// NewCachedTask returns a task with the name, that calls fetch every period
+// and caches the value if fetch succeeds. The fetch error is handled as the
+// task error, so the errors, wrapping [utils.ErrStopped], stop the task.
+//
+// Example:
+//
+//	rates := NewCachedTask("rates", time.Hour, fetchRates)
+//	rates.Start()
+//	...
+//	rate := rates.Get()["EUR"]
+func NewCachedTask[T any](name string, period time.Duration, fetch func(context.Context) (T, error), opts ...option) *CachedTask[T] {
+	c := &CachedTask[T]{fetch: fetch, now: time.Now}
+	c.RestartableWithTicker = NewTask(ticker.NewTimer(period), func(ctx context.Context) error {
+		_, err := c.GetFresh(ctx)
+		c.revalidating.Store(false)
+		return err
+	}, append([]option{WithName(name)}, opts...)...)
+	return c
+}
+
+// Get returns the last successfully fetched value, or the zero value if there
+// is none yet.
+func (c *CachedTask[T]) Get() T {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.value
+}
+
+// Updated returns the time of the last successful fetch, or zero time if there
+// is none yet.
+func (c *CachedTask[T]) Updated() time.Time {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+	return c.updated
+}
+
+// GetFresh fetches the value now, and caches it on success. On failure, the
+// cached value is returned with the error.
+func (c *CachedTask[T]) GetFresh(ctx context.Context) (T, error) {
+	value, err := c.fetch(ctx)
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.err = err
+	if err != nil {
+		return c.value, err
+	}
+	c.value, c.updated = value, c.now()
+	return value, nil
+}
+
+// SetPolicy sets the policy of [CachedTask.Lookup].
+func (c *CachedTask[T]) SetPolicy(policy CachePolicy) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.policy = policy
+}
+
+// Lookup returns the cached value according to the [CachePolicy]: the fresh
+// value is returned as is; the stale value is returned instantly during the
+// StaleWhileRevalidate time, or during the StaleIfError time after a failed
+// fetch, and is fetched in background by a run of the task, triggered with
+// TriggerNow, if the task is running; otherwise the value is fetched now,
+// as by [CachedTask.GetFresh], and the stale value is returned without the
+// error, if the fetch fails during the StaleIfError time.
+// The failed fetches never drop the cached value.
+func (c *CachedTask[T]) Lookup(ctx context.Context) (T, error) {
+	c.mux.RLock()
+	value, updated, failed, policy := c.value, c.updated, c.err != nil, c.policy
+	c.mux.RUnlock()
+	if policy.TTL == 0 && !updated.IsZero() {
+		return value, nil
+	}
+	age := c.now().Sub(updated)
+	staleIfError := !updated.IsZero() && age <= policy.TTL+policy.StaleIfError
+	switch {
+	case updated.IsZero():
+	case age <= policy.TTL:
+		return value, nil
+	case age <= policy.TTL+policy.StaleWhileRevalidate, failed && staleIfError:
+		c.revalidate(ctx)
+		return value, nil
+	}
+	value, err := c.GetFresh(ctx)
+	if err != nil && staleIfError {
+		return value, nil
+	}
+	return value, err
+}
+
+// revalidate triggers a task run to fetch the value in background, unless it
+// is pending already, so that the fetch is waited for and cancelled by the
+// task stop as the scheduled runs are.
+func (c *CachedTask[T]) revalidate(ctx context.Context) {
+	if !c.revalidating.CompareAndSwap(false, true) {
+		return
+	}
+	if err := c.TriggerNow(ctx, false); err != nil {
+		c.revalidating.Store(false)
+	}
+}
@@ -0,0 +1,118 @@
+package goticks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestCachedTask(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	fetched := make(chan struct{}, 1)
+	n := 0
+	cached := NewCachedTask("test", time.Hour, func(context.Context) (int, error) {
+		defer func() { fetched <- struct{}{} }()
+		n++
+		if n == 2 {
+			return 0, errFetch
+		}
+		return n, nil
+	}, WithTickerStop())
+	assert.That(t,
+		assert.Equal(0, cached.Get()),
+		assert.True(cached.Updated().IsZero()))
+
+	cached.Start()
+	<-fetched
+	cached.Stop()
+	assert.That(t,
+		assert.NoError(cached.WaitTimeout(time.Second)),
+		assert.Equal(1, cached.Get()),
+		assert.False(cached.Updated().IsZero()))
+
+	value, err := cached.GetFresh(context.Background())
+	<-fetched
+	assert.That(t,
+		assert.ErrorIs(err, errFetch),
+		assert.Equal(1, value))
+
+	value, err = cached.GetFresh(context.Background())
+	<-fetched
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal(3, value),
+		assert.Equal(3, cached.Get()))
+}
+
+func TestCachedTask_Lookup(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	fetched := make(chan struct{}, 1)
+	var mux sync.Mutex
+	n := 0
+	var failure error
+	cached := NewCachedTask("lookup", time.Hour, func(context.Context) (int, error) {
+		defer func() { fetched <- struct{}{} }()
+		mux.Lock()
+		defer mux.Unlock()
+		n++
+		return n, failure
+	}, WithTickerStop())
+	now := time.Now()
+	cached.now = func() time.Time {
+		mux.Lock()
+		defer mux.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mux.Lock()
+		defer mux.Unlock()
+		now = now.Add(d)
+	}
+	cached.SetPolicy(CachePolicy{TTL: 50 * time.Millisecond, StaleWhileRevalidate: 50 * time.Millisecond, StaleIfError: time.Hour})
+	fetches := func() int {
+		mux.Lock()
+		defer mux.Unlock()
+		return n
+	}
+
+	// The start fetches the value.
+	cached.Start()
+	defer cached.Stop()
+	<-fetched
+	assert.That(t, assert.NoError(cached.WaitTimeout(time.Second)))
+	value, err := cached.Lookup(context.Background())
+	assert.That(t, assert.NoError(err), assert.Equal(1, value))
+	advance(50 * time.Millisecond)
+	value, err = cached.Lookup(context.Background())
+	assert.That(t, assert.NoError(err), assert.Equal(1, value), assert.Equal(1, fetches()))
+
+	// Stale while revalidate.
+	advance(10 * time.Millisecond)
+	value, err = cached.Lookup(context.Background())
+	assert.That(t, assert.NoError(err), assert.Equal(1, value))
+	<-fetched
+	assert.That(t, assert.NoError(cached.WaitTimeout(time.Second)))
+	assert.That(t, assert.Equal(2, cached.Get()))
+
+	// Stale if error.
+	mux.Lock()
+	failure = errFetch
+	mux.Unlock()
+	advance(110 * time.Millisecond)
+	value, err = cached.Lookup(context.Background())
+	<-fetched
+	assert.That(t, assert.NoError(err), assert.Equal(2, value), assert.Equal(3, fetches()))
+	value, err = cached.Lookup(context.Background())
+	<-fetched
+	assert.That(t, assert.NoError(cached.WaitTimeout(time.Second)))
+	assert.That(t, assert.NoError(err), assert.Equal(2, value), assert.Equal(4, fetches()))
+
+	cached.SetPolicy(CachePolicy{TTL: 50 * time.Millisecond})
+	value, err = cached.Lookup(context.Background())
+	<-fetched
+	assert.That(t, assert.ErrorIs(err, errFetch), assert.Equal(2, value), assert.Equal(2, cached.Get()))
+}
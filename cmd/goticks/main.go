@@ -0,0 +1,268 @@
+// Command goticks runs ad-hoc periodic commands, previews schedules, and
+// serves the admin API of the configured tasks.
+//
+// Usage:
+//
+//	goticks run [-every d] [-timeout d] [-attempts n] [-count n] [-v] -- command [args...]
+//	goticks next [-every d] [-from time] [-n count] [cron spec]
+//	goticks serve [-addr host:port] [-config file] [-heartbeat d]
+//
+// The served tasks are configured as with [goticks.LoadConfig], and run the
+// shell command of the "command" parameter of the "exec" task, e.g.:
+//
+//	[{"name": "report", "task": "exec", "ticker": "cron", "schedule": "0 9 * * mon",
+//	  "params": {"command": "curl -fsS http://localhost/report"}}]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/parametalol/goticks"
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+const usage = `Usage:
+  goticks run [-every d] [-timeout d] [-attempts n] [-count n] [-v] -- command [args...]
+  goticks next [-every d] [-from time] [-n count] [cron spec]
+  goticks serve [-addr host:port] [-config file] [-heartbeat d]
+`
+
+var errUsage = errors.New("invalid usage")
+
+// stopTimeout bounds the wait for the command in progress on a signal.
+const stopTimeout = 5 * time.Second
+
+func init() {
+	goticks.Register("exec", func(c goticks.TaskConfig) (func(context.Context, time.Time) error, error) {
+		command := c.Params["command"]
+		if command == "" {
+			return nil, errors.New("no command parameter")
+		}
+		return utils.Exec(func(time.Time) *exec.Cmd {
+			return exec.Command("sh", "-c", command)
+		}, os.Stdout, os.Stderr), nil
+	})
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		_, _ = fmt.Fprint(stderr, usage)
+		return errUsage
+	}
+	switch args[0] {
+	case "run":
+		return runCommand(ctx, args[1:], stdout, stderr)
+	case "next":
+		return nextCommand(args[1:], stdout, stderr)
+	case "serve":
+		return serveCommand(ctx, args[1:], stdout, stderr)
+	}
+	_, _ = fmt.Fprint(stderr, usage)
+	return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+}
+
+// runCommand executes the command on every tick until the context is cancelled
+// or the count of runs is reached. On the context cancellation, the command in
+// progress is waited for up to stopTimeout.
+func runCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	every := fs.Duration("every", time.Minute, "period of the runs")
+	timeout := fs.Duration("timeout", 0, "timeout of a single attempt, 0 for no timeout")
+	attempts := fs.Int("attempts", 1, "number of attempts on failure")
+	count := fs.Int("count", 0, "number of runs before exit, 0 for no limit")
+	verbose := fs.Bool("v", false, "print the task setup as JSON to stderr before the runs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("%w: no command to run", errUsage)
+	}
+	name, cmdArgs := fs.Arg(0), fs.Args()[1:]
+
+	execute := utils.Exec(func(time.Time) *exec.Cmd {
+		return exec.Command(name, cmdArgs...)
+	}, stdout, stderr)
+	var retry utils.RetryPolicy
+	if *attempts > 1 {
+		retry = utils.SimpleRetryPolicy(*attempts)
+	}
+
+	var runs atomic.Int32
+	var task goticks.RestartableWithTicker[time.Time]
+	done := make(chan struct{})
+	timer := ticker.NewTimer(*every)
+	task = goticks.NewTask(timer, execute,
+		goticks.WithName(strings.Join(fs.Args(), " ")),
+		goticks.WithTimeout(*timeout),
+		goticks.WithRetry(retry),
+		goticks.WithLog(stdout, stderr),
+		goticks.WithOnRun(func(utils.RunResult) {
+			if *count > 0 && int(runs.Add(1)) >= *count {
+				task.StopAfterCurrentRun()
+			}
+		}),
+		goticks.WithTickerStop(), goticks.WithOnStop(func() { close(done) }))
+
+	if *verbose {
+		_ = json.NewEncoder(stderr).Encode(task.Config())
+	}
+	task.Start()
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		task.StopAfterCurrentRun()
+		if err := task.WaitTimeout(stopTimeout); err != nil {
+			return fmt.Errorf("command did not finish: %w", err)
+		}
+	case <-done:
+	}
+	return nil
+}
+
+// nextCommand prints the upcoming ticks of the cron spec, if given, or of the
+// periodic schedule otherwise. The cron ticks are computed in the location of
+// the start.
+func nextCommand(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("next", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	every := fs.Duration("every", time.Minute, "period of the schedule")
+	from := fs.String("from", "", "start of the schedule in RFC 3339 format, now by default")
+	n := fs.Int("n", 10, "number of ticks to print")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	start := time.Now()
+	if *from != "" {
+		var err error
+		if start, err = time.Parse(time.RFC3339, *from); err != nil {
+			return err
+		}
+	}
+	var schedule ticker.Schedule
+	if fs.NArg() != 0 {
+		// The spec may be given unquoted.
+		var err error
+		if schedule, err = ticker.ParseCron(strings.Join(fs.Args(), " ")); err != nil {
+			return err
+		}
+	} else {
+		if *every <= 0 {
+			return fmt.Errorf("%w: the period must be positive", errUsage)
+		}
+		// The timer ticks immediately on start.
+		schedule = ticker.Periodic{Start: start, Period: *every}
+	}
+	for _, tick := range schedule.NextN(start, *n) {
+		_, _ = fmt.Fprintln(stdout, tick.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// serveCommand serves the admin API of the tasks, configured in the file, until
+// the context is cancelled. The configuration changes, made with the API, are
+// saved to the file. The heartbeat with the task statuses is written to stdout,
+// if its period is positive. On the context cancellation, the tasks are
+// stopped as [goticks.Admin.StopAllContext] does, waiting up to stopTimeout.
+func serveCommand(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	addr := fs.String("addr", "localhost:8080", "address of the admin API")
+	file := fs.String("config", "", "task configuration file, created on the first change")
+	heartbeat := fs.Duration("heartbeat", 0, "period of the heartbeat, 0 for no heartbeat")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, fs.Arg(0))
+	}
+	cfg, err := loadConfigFile(*file)
+	if err != nil {
+		return err
+	}
+	var save func([]goticks.TaskConfig) error
+	if *file != "" {
+		save = func(cfg []goticks.TaskConfig) error {
+			data, err := json.MarshalIndent(cfg, "", "  ")
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(*file, data, 0o644)
+		}
+	}
+	admin, err := goticks.NewAdmin(cfg, save)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		admin.StopAll()
+		return err
+	}
+	_, _ = fmt.Fprintf(stderr, "Serving the admin API on http://%s\n", listener.Addr())
+	if *heartbeat > 0 {
+		beat := goticks.NewHeartbeat("goticks", *heartbeat, goticks.HeartbeatWriter(stdout),
+			goticks.WithHeartbeatTasks(admin.Status), goticks.WithTickerStop())
+		beat.Start()
+		defer func() {
+			beat.Stop()
+			_ = beat.WaitTimeout(stopTimeout)
+		}()
+	}
+	server := &http.Server{Handler: admin.Handler()}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	select {
+	case <-ctx.Done():
+	case err = <-served:
+	}
+	stopCtx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	_ = server.Shutdown(stopCtx)
+	if _, stopErr := admin.StopAllContext(stopCtx); stopErr != nil {
+		return errors.Join(err, stopErr)
+	}
+	return err
+}
+
+// loadConfigFile loads the task configuration from the file, if it exists.
+func loadConfigFile(file string) ([]goticks.TaskConfig, error) {
+	if file == "" {
+		return nil, nil
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return goticks.LoadConfig(f)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/ticker"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("usage", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.That(t,
+			assert.ErrorIs(run(context.Background(), nil, &out, &out), errUsage),
+			assert.ErrorIs(run(context.Background(), []string{"oops"}, &out, &out), errUsage),
+			assert.ErrorIs(run(context.Background(), []string{"run"}, &out, &out), errUsage))
+	})
+
+	t.Run("next", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run(context.Background(), []string{"next",
+			"-every", "90m", "-from", "2025-05-04T00:00:00Z", "-n", "3"}, &out, &out)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal("2025-05-04T00:00:00Z\n2025-05-04T01:30:00Z\n2025-05-04T03:00:00Z\n", out.String()))
+	})
+
+	t.Run("next cron", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run(context.Background(), []string{"next",
+			"-from", "2025-05-04T00:00:00Z", "-n", "3", "0 */5 * * *"}, &out, &out)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal("2025-05-04T00:00:00Z\n2025-05-04T05:00:00Z\n2025-05-04T10:00:00Z\n", out.String()),
+			assert.ErrorIs(run(context.Background(), []string{"next", "0 * *"}, &out, &out), ticker.ErrInvalidCron))
+	})
+
+	t.Run("serve", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "tasks.json")
+		assert.That(t, assert.NoError(os.WriteFile(file,
+			[]byte(`[{"name": "noop", "task": "exec", "every": "1h", "params": {"command": "true"}}]`), 0o644)))
+		var out, errW bytes.Buffer
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err := run(ctx, []string{"serve", "-addr", "127.0.0.1:0", "-config", file, "-heartbeat", "10ms"}, &out, &errW)
+		assert.That(t,
+			assert.NoError(err),
+			assert.True(strings.HasPrefix(errW.String(), "Serving the admin API on http://127.0.0.1:")),
+			assert.True(strings.HasPrefix(out.String(), `{"name":"goticks",`)),
+			assert.True(strings.Contains(out.String(), `"name":"noop"`)))
+	})
+
+	t.Run("run", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run(context.Background(), []string{"run",
+			"-every", "10ms", "-count", "2", "--", "echo", "hello"}, &out, &out)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal("Calling echo hello\nhello\nCalling echo hello\nhello\n", out.String()))
+	})
+
+	t.Run("run verbose", func(t *testing.T) {
+		var out, errW bytes.Buffer
+		err := run(context.Background(), []string{"run",
+			"-every", "10ms", "-timeout", "1s", "-attempts", "2", "-count", "1", "-v", "--", "true"}, &out, &errW)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal(`{"name":"true","schedule":"every 10ms","timeout":"1s","retry":"utils.SimpleRetryPolicy","wrappers":["Classify","Retry","Log","Timeout"]}`+"\n", errW.String()))
+	})
+
+	t.Run("run cancelled", func(t *testing.T) {
+		var out bytes.Buffer
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := run(ctx, []string{"run", "-every", "1h", "--", "true"}, &out, &out)
+		assert.That(t,
+			assert.NoError(err))
+	})
+
+	t.Run("run interrupted", func(t *testing.T) {
+		var out bytes.Buffer
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+		start := time.Now()
+		err := run(ctx, []string{"run", "-every", "1h", "--", "sh", "-c", "sleep 0.2; echo done"}, &out, &out)
+		// The command in progress has been waited for.
+		assert.That(t,
+			assert.NoError(err),
+			assert.True(time.Since(start) >= 200*time.Millisecond),
+			assert.True(strings.HasSuffix(out.String(), "echo done\ndone\n")))
+	})
+}
@@ -0,0 +1,100 @@
+package goticks
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+)
+
+// DebugHooks records the task lifecycle transitions, so that the task
+// invariants can be checked with [DebugHooks.Check], e.g. after [Stress].
+//
+// The invariants are:
+//   - the transitions are serialized, each starting from the state, where the
+//     previous one ended;
+//   - a task is started and stopped only through the starting and stopping
+//     states, in which the onStart and onStop callbacks are called;
+//   - a stopped task stays stopped until it is started.
+type DebugHooks struct {
+	mux         sync.Mutex
+	transitions [][2]state
+}
+
+// WithDebugHooks makes the task record its lifecycle transitions in the hooks.
+// The hooks must not be shared by tasks.
+func WithDebugHooks(h *DebugHooks) option {
+	return func(o *options) {
+		o.onTransition = h.add
+	}
+}
+
+func (h *DebugHooks) add(from, to state) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.transitions = append(h.transitions, [2]state{from, to})
+}
+
+// Transitions returns the recorded transitions as "from -> to" strings.
+func (h *DebugHooks) Transitions() []string {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	transitions := make([]string, 0, len(h.transitions))
+	for _, tr := range h.transitions {
+		transitions = append(transitions, tr[0].String()+" -> "+tr[1].String())
+	}
+	return transitions
+}
+
+// Check returns the violations of the task invariants in the recorded
+// transitions, joined, or nil if there are none.
+func (h *DebugHooks) Check() error {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	var errs []error
+	last := stateStopped
+	for i, tr := range h.transitions {
+		if tr[0] != last {
+			errs = append(errs, fmt.Errorf("transition #%d from %v, expected from %v", i, tr[0], last))
+		}
+		if !slices.Contains(allowedTransitions[tr[0]], tr[1]) {
+			errs = append(errs, fmt.Errorf("transition #%d from %v to %v is not allowed", i, tr[0], tr[1]))
+		}
+		last = tr[1]
+	}
+	return errors.Join(errs...)
+}
+
+// Stress calls concurrently the task StartE, Stop and StopAfterCurrentRun
+// methods, resetting the task, stopped by a failure, and ticks the task ticker
+// with the tick, in random order, to exercise the task wrapper stack under
+// go test -race. Each of the workers makes the given number of calls. The task
+// is stopped at the end. Use [WithDebugHooks] to check the invariants
+// afterwards.
+func Stress[TickType any](task RestartableWithTicker[TickType], tick TickType, workers, calls int) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range calls {
+				switch rand.IntN(4) {
+				case 0:
+					// Keep exercising the task, stopped by a failure.
+					if errors.Is(task.StartE(), ErrNeedsReset) {
+						task.Reset()
+					}
+				case 1:
+					task.Stop()
+				case 2:
+					task.StopAfterCurrentRun()
+				default:
+					task.Ticker().Tick(tick).Wait()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	task.Stop()
+}
@@ -0,0 +1,38 @@
+package goticks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestStress(t *testing.T) {
+	calls := 500
+	if testing.Short() {
+		calls = 50
+	}
+	hooks := &DebugHooks{}
+	task := NewTask(ticker.New[int](),
+		utils.NoOverlap[int](utils.Timeout[int](time.Second, func() {})),
+		WithDebugHooks(hooks), WithMinGap(time.Microsecond))
+	Stress(task, 0, 4, calls)
+
+	transitions := hooks.Transitions()
+	assert.That(t,
+		assert.NoError(hooks.Check()),
+		assert.True(len(transitions) > 0),
+		assert.Equal("stopped -> starting", transitions[0]))
+}
+
+func TestDebugHooks_Check(t *testing.T) {
+	hooks := &DebugHooks{}
+	hooks.add(stateStopped, stateRunning)
+	hooks.add(statePaused, stateStarting)
+	assert.That(t, assert.Equal(
+		"transition #0 from stopped to running is not allowed\n"+
+			"transition #1 from paused, expected from running",
+		hooks.Check().Error()))
+}
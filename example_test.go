@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/parametalol/goticks/ticker"
@@ -113,3 +115,74 @@ func ExampleTask_Start() {
 	// Passed time: 2s
 	// Passed time: 3s
 }
+
+// This example composes the task wrappers with the options: every attempt of
+// the retried task is limited by the timeout.
+func ExampleWithRetry() {
+	ticker := ticker.New[int]()
+	attempts := 0
+	task := NewTask(ticker,
+		func(ctx context.Context, tick int) error {
+			attempts++
+			fmt.Println("tick", tick, "attempt", attempts, "on", utils.RunCauseFromContext(ctx))
+			if attempts < 2 {
+				return fmt.Errorf("unavailable")
+			}
+			return nil
+		},
+		WithRetry(utils.SimpleRetryPolicy(3)),
+		WithTimeout(time.Second))
+
+	task.Start()
+	ticker.Tick(1).Wait()
+	task.Stop()
+
+	// Output:
+	// tick 1 attempt 1 on start
+	// tick 1 attempt 2 on retry
+}
+
+var registerGreeter sync.Once
+
+// This example runs the tasks of the declarative configuration, built with
+// the registered factory. The second task is started after the first
+// successful run of the first one.
+func ExampleNewAdmin() {
+	// The factories are usually registered by the init functions.
+	registerGreeter.Do(func() {
+		Register("greeter", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+			return func(context.Context, time.Time) error {
+				fmt.Println("Hello,", c.Params["whom"])
+				return nil
+			}, nil
+		})
+	})
+
+	cfg, err := LoadConfig(strings.NewReader(`[
+		{"name": "greet", "task": "greeter", "every": "1h", "params": {"whom": "world"}},
+		{"name": "greet-again", "task": "greeter", "every": "1h", "params": {"whom": "again"}, "after": ["greet"]}]`))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	// The tasks are started by NewAdmin.
+	admin, err := NewAdmin(cfg, nil)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := admin.AwaitFirstSuccess(ctx, "greet-again"); err != nil {
+		fmt.Println(err)
+	}
+	for _, task := range admin.StopAll() {
+		fmt.Println("Stopped", task.Name)
+	}
+
+	// Output:
+	// Hello, world
+	// Hello, again
+	// Stopped greet
+	// Stopped greet-again
+}
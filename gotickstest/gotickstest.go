@@ -0,0 +1,33 @@
+// Package gotickstest provides the utilities for testing the code, using the
+// goticks package.
+package gotickstest
+
+import (
+	"testing"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// Accelerate divides the durations of the timers, created by the goticks
+// packages, by the factor until the end of the test: the ticker periods and
+// delays, the timeouts, the retry backoffs, e.g. of NewPoller, and the
+// suggested retry times, the minimal gaps, the staggered and spread starts,
+// the waits for the windows, the shapers and the load shedders, the budget
+// sampling, the admin cooldowns and the drain timeout. This lets the
+// integration tests exercise the real code paths in milliseconds instead of
+// seconds.
+//
+// The measured durations, e.g. the run durations in the metrics, are not
+// scaled, and neither are the timeouts, learnt from them by AutoTimeout, as
+// the measured runs are accelerated already. The waits, bounded by the
+// caller, e.g. WaitTimeout, are not scaled either.
+//
+// The acceleration is global, so the accelerated tests must not run in
+// parallel with the other tests of the package.
+func Accelerate(t testing.TB, factor float64) {
+	t.Helper()
+	if factor <= 0 {
+		t.Fatalf("gotickstest: invalid acceleration factor %v", factor)
+	}
+	t.Cleanup(timescale.Set(factor))
+}
@@ -0,0 +1,46 @@
+package gotickstest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestAccelerate(t *testing.T) {
+	t.Run("accelerated", func(t *testing.T) {
+		Accelerate(t, 1000)
+		start := time.Now()
+		ticks := slices.Collect(ticker.NewCountedTimer(time.Second, 3).Ticks())
+		assert.That(t,
+			assert.Equal(3, len(ticks)),
+			assert.True(time.Since(start) < time.Second))
+	})
+	t.Run("retry hint", func(t *testing.T) {
+		Accelerate(t, 1e6)
+		attempts := 0
+		start := time.Now()
+		err := utils.Retry[int](utils.HonorRetryAt(utils.SimpleRetryPolicy(2)), func(ctx context.Context) error {
+			attempts++
+			if attempts == 1 {
+				utils.SuggestRetryAt(ctx, time.Now().Add(time.Hour))
+				return errors.New("rate limited")
+			}
+			return nil
+		})(context.Background(), 0)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal(2, attempts),
+			assert.True(time.Since(start) < time.Second))
+	})
+	start := time.Now()
+	ticks := slices.Collect(ticker.NewCountedTimer(20*time.Millisecond, 2).Ticks())
+	assert.That(t,
+		assert.Equal(2, len(ticks)),
+		assert.True(time.Since(start) >= 20*time.Millisecond))
+}
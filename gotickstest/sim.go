@@ -0,0 +1,137 @@
+package gotickstest
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/parametalol/goticks/ticker"
+)
+
+// SimTicker is the ticker, which ticks at the times of a schedule on a fake
+// clock, advanced by the test, so that the tests validate the scheduling logic
+// rather than hand-fed timestamps. As the [ticker.FromSchedule] ticker, it is
+// started by the first call to Ticks, at the current fake time, and ticks at
+// the schedule times not before it. The schedule of a [ticker.NewTimer]
+// ticker is [ticker.Periodic], starting at the fake start time, so that the
+// first tick is dispatched immediately:
+//
+//	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//	sim := gotickstest.NewSimTicker(ticker.Periodic{Start: start, Period: time.Minute}, start)
+//	task := goticks.NewTask(sim, fn)
+//	task.Start()
+//	sim.Advance(time.Hour) // 61 runs.
+type SimTicker struct {
+	ticker.Ticker[time.Time]
+	schedule ticker.Schedule
+
+	// advanceMux serializes the clock advances, and mux guards the clock,
+	// which is not locked while a tick is processed, so that the consumers may
+	// read it.
+	advanceMux sync.Mutex
+	mux        sync.Mutex
+	now        time.Time
+	next       time.Time
+	started    bool
+	// stopped is not guarded by mux, so that the consumers may stop the
+	// ticker while processing a tick.
+	stopped atomic.Bool
+}
+
+var (
+	_ ticker.Ticker[time.Time] = (*SimTicker)(nil)
+	_ ticker.Schedulable       = (*SimTicker)(nil)
+)
+
+// NewSimTicker returns the ticker of the schedule with the fake clock, set to
+// now.
+func NewSimTicker(schedule ticker.Schedule, now time.Time) *SimTicker {
+	return &SimTicker{Ticker: ticker.New[time.Time](), schedule: schedule, now: now}
+}
+
+// Ticks returns a new iterator over the ticks, starting the ticker on the
+// first call.
+func (s *SimTicker) Ticks() iter.Seq[time.Time] {
+	ticks := s.Ticker.Ticks()
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if !s.started && !s.stopped.Load() {
+		s.started = true
+		s.scheduleNext(s.now)
+	}
+	return ticks
+}
+
+// scheduleNext sets the next tick time not before from. Must be called under
+// the lock.
+func (s *SimTicker) scheduleNext(from time.Time) {
+	s.next = time.Time{}
+	if next := s.schedule.NextN(from, 1); len(next) > 0 {
+		s.next = next[0]
+	}
+}
+
+// Now returns the fake time.
+func (s *SimTicker) Now() time.Time {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.now
+}
+
+// Next returns the time of the next tick, or zero time if the ticker is not
+// started, or no tick is scheduled.
+func (s *SimTicker) Next() time.Time {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.stopped.Load() {
+		return time.Time{}
+	}
+	return s.next
+}
+
+// Advance moves the fake clock forward by d. See [SimTicker.AdvanceTo].
+func (s *SimTicker) Advance(d time.Duration) []time.Time {
+	s.advanceMux.Lock()
+	defer s.advanceMux.Unlock()
+	return s.advanceTo(s.Now().Add(d))
+}
+
+// AdvanceTo moves the fake clock forward to the given time, dispatching the
+// ticks, which fall not after it, one by one, and waiting for the consumers to
+// process every tick before the next one, so every iterator, returned by
+// Ticks, must be consumed. While a tick is processed, the clock shows the tick
+// time, and Next returns the following tick. It returns the dispatched ticks.
+func (s *SimTicker) AdvanceTo(to time.Time) []time.Time {
+	s.advanceMux.Lock()
+	defer s.advanceMux.Unlock()
+	return s.advanceTo(to)
+}
+
+// advanceTo implements AdvanceTo. Must be called under the advance lock.
+func (s *SimTicker) advanceTo(to time.Time) []time.Time {
+	var ticks []time.Time
+	for {
+		s.mux.Lock()
+		if !s.started || s.stopped.Load() || s.next.IsZero() || s.next.After(to) {
+			if to.After(s.now) {
+				s.now = to
+			}
+			s.mux.Unlock()
+			return ticks
+		}
+		tick := s.next
+		s.now = tick
+		// The same tick is not repeated, as by the real ticker.
+		s.scheduleNext(tick.Add(time.Nanosecond))
+		s.mux.Unlock()
+		s.Ticker.Tick(tick).Wait()
+		ticks = append(ticks, tick)
+	}
+}
+
+// Stop cancels the next tick and terminates consumers.
+func (s *SimTicker) Stop() {
+	s.stopped.Store(true)
+	s.Ticker.Stop()
+}
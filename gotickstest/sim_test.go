@@ -0,0 +1,61 @@
+package gotickstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/loop"
+	"github.com/parametalol/goticks/ticker"
+)
+
+func TestSimTicker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)
+
+	t.Run("periodic", func(t *testing.T) {
+		sim := NewSimTicker(ticker.Periodic{Start: start, Period: time.Minute}, start)
+		assert.That(t, assert.True(sim.Next().IsZero()))
+
+		var runs []time.Time
+		var nexts []time.Time
+		done := make(chan error)
+		ticks := sim.Ticks()
+		go func() {
+			done <- loop.OnTick(ticks, func(_ context.Context, tick time.Time) error {
+				runs = append(runs, tick)
+				nexts = append(nexts, sim.Next())
+				return nil
+			})
+		}()
+		assert.That(t,
+			assert.EqualSlices([]time.Time{start}, sim.Advance(0)),
+			assert.Equal(2, len(sim.Advance(2*time.Minute+time.Second))),
+			assert.Equal(start.Add(2*time.Minute+time.Second), sim.Now()),
+			assert.Equal(start.Add(3*time.Minute), sim.Next()))
+		sim.Stop()
+		<-done
+		assert.That(t,
+			assert.EqualSlices([]time.Time{start, start.Add(time.Minute), start.Add(2 * time.Minute)}, runs),
+			assert.EqualSlices([]time.Time{start.Add(time.Minute), start.Add(2 * time.Minute), start.Add(3 * time.Minute)}, nexts),
+			assert.Equal(0, len(sim.Advance(time.Hour))),
+			assert.True(sim.Next().IsZero()))
+	})
+
+	t.Run("cron", func(t *testing.T) {
+		schedule, err := ticker.ParseCron("0 9 * * mon-fri")
+		assert.That(t, assert.NoError(err))
+		sim := NewSimTicker(schedule, start)
+		defer sim.Stop()
+		ticks := sim.Ticks()
+		go func() {
+			for range ticks {
+			}
+		}()
+		// 2024-01-01 is Monday: the first tick is at 9:00, none at the weekend.
+		assert.That(t,
+			assert.Equal(start.Add(30*time.Minute), sim.Next()),
+			assert.Equal(5, len(sim.Advance(7*24*time.Hour))),
+			assert.Equal(start.Add(7*24*time.Hour+30*time.Minute), sim.Next()))
+	})
+}
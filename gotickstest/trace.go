@@ -0,0 +1,110 @@
+package gotickstest
+
+import (
+	"context"
+	"sync"
+)
+
+// TraceTask is the name of the task function in the [Trace] events.
+const TraceTask = "task"
+
+// Layer is a named task wrapper, e.g. a [utils] wrapper with the options
+// applied:
+//
+//	gotickstest.Layer[time.Time]{"retry", func(next func(context.Context, time.Time) error) func(context.Context, time.Time) error {
+//		return utils.Retry[time.Time](utils.SimpleRetryPolicy(3), next)
+//	}}
+type Layer[TickType any] struct {
+	Name string
+	Wrap func(next func(context.Context, TickType) error) func(context.Context, TickType) error
+}
+
+// TraceEvent is the entry to a layer, or the exit from it with the returned
+// error.
+type TraceEvent struct {
+	Layer string
+	Enter bool
+	Err   error
+}
+
+// Trace is the record of a simulated tick, passed through the layers.
+type Trace struct {
+	Events []TraceEvent
+	// Err is the error, returned by the outermost layer.
+	Err error
+}
+
+// Order returns the names of the layers in the order they have been entered.
+// A layer appears as many times as it has been called, e.g. the task, retried
+// by the outer layer.
+func (t Trace) Order() []string {
+	var order []string
+	for _, event := range t.Events {
+		if event.Enter {
+			order = append(order, event.Layer)
+		}
+	}
+	return order
+}
+
+// ShortCircuited returns the names of the layers, which have returned without
+// calling the next layer, in the order of their exits.
+func (t Trace) ShortCircuited() []string {
+	type frame struct {
+		layer  string
+		called bool
+	}
+	var stack []frame
+	var layers []string
+	for _, event := range t.Events {
+		if event.Enter {
+			if len(stack) > 0 {
+				stack[len(stack)-1].called = true
+			}
+			stack = append(stack, frame{layer: event.Layer})
+			continue
+		}
+		if len(stack) == 0 {
+			continue
+		}
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !top.called && top.layer != TraceTask {
+			layers = append(layers, top.layer)
+		}
+	}
+	return layers
+}
+
+// TraceWrappers composes the task with the layers, the first being the
+// outermost, calls the composition with the tick, and returns the trace of the
+// calls, so that the tests can verify the order of the wrappers, e.g. that the
+// timeout applies to every retry attempt rather than to all of them.
+// The calls of the layers, which run the next layer concurrently, are recorded
+// in the order they happen.
+func TraceWrappers[TickType any](ctx context.Context, tick TickType, task func(context.Context, TickType) error, layers ...Layer[TickType]) Trace {
+	var mux sync.Mutex
+	var trace Trace
+	record := func(event TraceEvent) {
+		mux.Lock()
+		defer mux.Unlock()
+		trace.Events = append(trace.Events, event)
+	}
+	probe := func(name string, next func(context.Context, TickType) error) func(context.Context, TickType) error {
+		return func(ctx context.Context, tick TickType) error {
+			record(TraceEvent{Layer: name, Enter: true})
+			err := next(ctx, tick)
+			record(TraceEvent{Layer: name, Err: err})
+			return err
+		}
+	}
+	run := probe(TraceTask, task)
+	for i := len(layers) - 1; i >= 0; i-- {
+		run = probe(layers[i].Name, layers[i].Wrap(run))
+	}
+	err := run(ctx, tick)
+	mux.Lock()
+	defer mux.Unlock()
+	trace.Err = err
+	return trace
+}
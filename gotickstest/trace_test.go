@@ -0,0 +1,42 @@
+package gotickstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestTraceWrappers(t *testing.T) {
+	type run = func(context.Context, int) error
+	errFailed := errors.New("failed")
+	retry := Layer[int]{"retry", func(next run) run {
+		return utils.Retry[int](utils.SimpleRetryPolicy(2), next)
+	}}
+	timeout := Layer[int]{"timeout", func(next run) run {
+		return utils.Timeout[int](time.Minute, next)
+	}}
+	health := Layer[int]{"health", func(next run) run {
+		return utils.HealthGate[int](func(context.Context) error { return errors.New("down") }, nil, next)
+	}}
+	fail := func(context.Context, int) error { return errFailed }
+
+	trace := TraceWrappers(context.Background(), 1, fail, retry, timeout)
+	assert.That(t,
+		assert.EqualSlices([]string{"retry", "timeout", TraceTask, "timeout", TraceTask}, trace.Order()),
+		assert.Equal(0, len(trace.ShortCircuited())),
+		assert.ErrorIs(trace.Err, errFailed))
+
+	trace = TraceWrappers(context.Background(), 1, fail, timeout, retry)
+	assert.That(t,
+		assert.EqualSlices([]string{"timeout", "retry", TraceTask, TraceTask}, trace.Order()))
+
+	trace = TraceWrappers(context.Background(), 1, fail, retry, health, timeout)
+	assert.That(t,
+		assert.EqualSlices([]string{"retry", "health"}, trace.Order()),
+		assert.EqualSlices([]string{"health"}, trace.ShortCircuited()),
+		assert.NoError(trace.Err))
+}
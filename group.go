@@ -0,0 +1,132 @@
+package goticks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// GroupTask is the task, owned by a [Group]. It is implemented by
+// [RestartableWithTicker] of any tick type.
+type GroupTask interface {
+	Task
+	StartE() error
+	StopAfterCurrentRun()
+	WaitContext(ctx context.Context) error
+	OnStop(f func(cause error)) (stop func() bool)
+	Error() error
+}
+
+// Group owns the tasks, which are started, stopped and waited for together,
+// e.g. in main instead of managing every task by hand. The tasks are started
+// in the order of addition and stopped in the reverse order, so that a task
+// may rely on the ones added before it. The zero value is ready to use.
+//
+//	var g goticks.Group
+//	_ = g.Add("refresh", goticks.NewTask(ticker.NewTimer(time.Minute), refresh))
+//	_ = g.Add("report", goticks.NewTask(ticker.NewTimer(time.Hour), report))
+//	if err := g.StartAll(); err != nil {
+//		...
+//	}
+//	defer g.StopAll(ctx)
+type Group struct {
+	mux   sync.Mutex
+	names []string
+	tasks []GroupTask
+}
+
+// Add adds the named task to the group. It returns an error, wrapping
+// [ErrTaskExists], if the name is taken.
+func (g *Group) Add(name string, task GroupTask) error {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	for _, n := range g.names {
+		if n == name {
+			return fmt.Errorf("task %q: %w", name, ErrTaskExists)
+		}
+	}
+	g.names = append(g.names, name)
+	g.tasks = append(g.tasks, task)
+	return nil
+}
+
+// StartAll starts the tasks in the order of addition, and returns the joined
+// errors, naming the tasks, which have not been started, e.g. wrapping
+// [ErrNeedsReset].
+func (g *Group) StartAll() error {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	var errs []error
+	for i, task := range g.tasks {
+		if err := task.StartE(); err != nil {
+			errs = append(errs, fmt.Errorf("task %q: %w", g.names[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StopAll stops the tasks in the reverse order of addition, letting the run in
+// progress of every task finish first, unless the context is done, in which
+// case the task is stopped without waiting. It returns the joined errors,
+// naming the tasks, which have failed before the stop, or which runs have been
+// left unfinished.
+func (g *Group) StopAll(ctx context.Context) error {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	var errs []error
+	for i := len(g.tasks) - 1; i >= 0; i-- {
+		task := g.tasks[i]
+		if err := failure(task.Error()); err != nil {
+			errs = append(errs, fmt.Errorf("task %q: %w", g.names[i], err))
+		}
+		task.StopAfterCurrentRun()
+		if err := task.WaitContext(ctx); err != nil && !errors.Is(err, ErrNotStarted) {
+			task.Stop()
+			errs = append(errs, fmt.Errorf("task %q did not finish: %w", g.names[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WaitAll waits for all the tasks to stop, e.g. on the task function error,
+// and returns the joined stop causes of the failed tasks, or the context cause
+// if the context is done first. The tasks, which have never been started, are
+// not waited for.
+func (g *Group) WaitAll(ctx context.Context) error {
+	g.mux.Lock()
+	names, tasks := g.names, g.tasks
+	g.mux.Unlock()
+
+	causes := make([]chan error, len(tasks))
+	for i, task := range tasks {
+		cause := make(chan error, 1)
+		causes[i] = cause
+		stop := task.OnStop(func(err error) {
+			select {
+			case cause <- err:
+			default:
+			}
+		})
+		defer stop()
+		// The task could have stopped before the registration.
+		if err := task.Error(); err != nil {
+			select {
+			case cause <- err:
+			default:
+			}
+		}
+	}
+	var errs []error
+	for i, cause := range causes {
+		select {
+		case err := <-cause:
+			if err = failure(err); err != nil {
+				errs = append(errs, fmt.Errorf("task %q: %w", names[i], err))
+			}
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,115 @@
+package goticks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestGroup(t *testing.T) {
+	var mux sync.Mutex
+	var events []string
+	record := func(event string) {
+		mux.Lock()
+		defer mux.Unlock()
+		events = append(events, event)
+	}
+	newTask := func(name string, fn func(int) error) (ticker.Ticker[int], RestartableWithTicker[int]) {
+		ticker := ticker.New[int]()
+		return ticker, NewTask(ticker, fn,
+			WithOnStart(func() error { record("start " + name); return nil }),
+			WithOnStop(func() { record("stop " + name) }))
+	}
+
+	t.Run("lifecycle", func(t *testing.T) {
+		events = nil
+		var g Group
+		errFatal := fmt.Errorf("fatal: %w", utils.ErrStopped)
+		ticker1, task1 := newTask("first", func(int) error { return nil })
+		ticker2, task2 := newTask("second", func(int) error { return errFatal })
+		_, task3 := newTask("third", func(int) error { return nil })
+		assert.That(t,
+			assert.NoError(g.Add("first", task1)),
+			assert.NoError(g.Add("second", task2)),
+			assert.ErrorIs(g.Add("first", task3), ErrTaskExists))
+
+		stopped := make(chan error, 1)
+		task2.OnStop(func(cause error) { stopped <- cause })
+		assert.That(t, assert.NoError(g.StartAll()))
+		ticker1.Tick(0).Wait()
+		ticker2.Tick(0).Wait()
+		<-stopped
+		err := g.StopAll(context.Background())
+		assert.That(t,
+			assert.EqualSlices([]string{"start first", "start second", "stop second", "stop first"}, events),
+			assert.ErrorIs(err, errFatal),
+			assert.Equal(`task "second": fatal: stopped`, err.Error()))
+
+		err = g.StartAll()
+		assert.That(t,
+			assert.ErrorIs(err, ErrNeedsReset),
+			assert.Equal(`task "second": needs reset: fatal: stopped`, err.Error()))
+		assert.That(t, assert.ErrorIs(g.StopAll(context.Background()), errFatal))
+	})
+
+	t.Run("WaitAll", func(t *testing.T) {
+		var g Group
+		errFatal := fmt.Errorf("fatal: %w", utils.ErrStopped)
+		ticker1, task1 := newTask("first", func(int) error { return errFatal })
+		_, task2 := newTask("second", func(int) error { return nil })
+		_, task3 := newTask("idle", func(int) error { return nil })
+		_ = g.Add("first", task1)
+		_ = g.Add("second", task2)
+		_ = g.StartAll()
+		_ = g.Add("idle", task3)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.That(t, assert.ErrorIs(g.WaitAll(ctx), context.DeadlineExceeded))
+
+		done := make(chan error)
+		go func() { done <- g.WaitAll(context.Background()) }()
+		ticker1.Tick(0).Wait()
+		task2.Stop()
+		err := <-done
+		assert.That(t,
+			assert.ErrorIs(err, errFatal),
+			assert.Equal(`task "first": fatal: stopped`, err.Error()))
+	})
+
+	t.Run("never started", func(t *testing.T) {
+		var g Group
+		_, task := newTask("idle", func(int) error { return nil })
+		_ = g.Add("idle", task)
+		assert.That(t,
+			assert.ErrorIs(task.WaitContext(context.Background()), ErrNotStarted),
+			assert.NoError(g.StopAll(context.Background())))
+	})
+
+	t.Run("unfinished run", func(t *testing.T) {
+		var g Group
+		running, release := make(chan struct{}), make(chan struct{})
+		ticker1, task1 := newTask("slow", func(int) error {
+			close(running)
+			<-release
+			return nil
+		})
+		_ = g.Add("slow", task1)
+		_ = g.StartAll()
+		go ticker1.Tick(0)
+		<-running
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		err := g.StopAll(ctx)
+		close(release)
+		assert.That(t,
+			assert.ErrorIs(err, context.DeadlineExceeded),
+			assert.ErrorIs(task1.Error(), utils.ErrStopped))
+	})
+}
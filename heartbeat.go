@@ -0,0 +1,105 @@
+package goticks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/parametalol/goticks/ticker"
+)
+
+// processStart approximates the process start time.
+var processStart = time.Now()
+
+// HeartbeatInfo is the liveness report of the process.
+type HeartbeatInfo struct {
+	Name     string            `json:"name"`
+	Time     time.Time         `json:"time"`
+	Uptime   time.Duration     `json:"uptime"`
+	Hostname string            `json:"hostname,omitempty"`
+	Version  string            `json:"version,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Tasks    []TaskStatus      `json:"tasks,omitempty"`
+}
+
+// NewHeartbeat returns a task, named as the reports, that reports the process
+// liveness to the sink every period. The custom fields of the report are set
+// with [WithHeartbeatFields], and the tasks with [WithHeartbeatTasks].
+//
+// Example:
+//
+//	NewHeartbeat("api", time.Minute, HeartbeatWriter(os.Stdout)).Start()
+func NewHeartbeat(name string, period time.Duration, sink func(context.Context, HeartbeatInfo) error, opts ...option) RestartableWithTicker[time.Time] {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	hostname, _ := os.Hostname()
+	var version string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version = info.Main.Version
+	}
+	return NewTask(ticker.NewTimer(period), func(ctx context.Context, tick time.Time) error {
+		info := HeartbeatInfo{
+			Name:     name,
+			Time:     tick,
+			Uptime:   tick.Sub(processStart),
+			Hostname: hostname,
+			Version:  version,
+		}
+		if o.heartbeatFields != nil {
+			info.Fields = o.heartbeatFields()
+		}
+		if o.heartbeatTasks != nil {
+			info.Tasks = o.heartbeatTasks()
+		}
+		return sink(ctx, info)
+	}, append([]option{WithName(name)}, opts...)...)
+}
+
+// HeartbeatWriter returns a heartbeat sink, that writes the reports to w as
+// JSON lines.
+func HeartbeatWriter(w io.Writer) func(context.Context, HeartbeatInfo) error {
+	encoder := json.NewEncoder(w)
+	return func(_ context.Context, info HeartbeatInfo) error {
+		return encoder.Encode(info)
+	}
+}
+
+// HeartbeatPoster returns a heartbeat sink, that posts the reports as JSON to
+// the URL with the client.
+func HeartbeatPoster(client *http.Client, url string) func(context.Context, HeartbeatInfo) error {
+	return func(ctx context.Context, info HeartbeatInfo) error {
+		return postJSON(ctx, client, url, "heartbeat", info)
+	}
+}
+
+// postJSON posts the value as JSON to the URL with the client. The error
+// message mentions what is posted.
+func postJSON(ctx context.Context, client *http.Client, url, what string, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s post failed with status %s", what, resp.Status)
+	}
+	return nil
+}
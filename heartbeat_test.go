@@ -0,0 +1,64 @@
+package goticks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestNewHeartbeat(t *testing.T) {
+	reports := make(chan HeartbeatInfo, 1)
+	heartbeat := NewHeartbeat("test", time.Hour, func(_ context.Context, info HeartbeatInfo) error {
+		reports <- info
+		return nil
+	}, WithHeartbeatFields(func() map[string]string {
+		return map[string]string{"key": "value"}
+	}), WithHeartbeatTasks(func() []TaskStatus {
+		return []TaskStatus{{TaskConfig: TaskConfig{Name: "task"}}}
+	}), WithTickerStop())
+	heartbeat.Start()
+	info := <-reports
+	heartbeat.Stop()
+
+	assert.That(t,
+		assert.Equal("test", info.Name),
+		assert.True(info.Uptime > 0),
+		assert.Equal("value", info.Fields["key"]),
+		assert.Equal(1, len(info.Tasks)),
+		assert.Equal("test", heartbeat.Config().Name))
+}
+
+func TestHeartbeatSinks(t *testing.T) {
+	info := HeartbeatInfo{Name: "test", Uptime: time.Second}
+
+	t.Run("writer", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := HeartbeatWriter(&buf)(context.Background(), info)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal(`{"name":"test","time":"0001-01-01T00:00:00Z","uptime":1000000000}`+"\n", buf.String()))
+	})
+
+	t.Run("poster", func(t *testing.T) {
+		var received HeartbeatInfo
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			if received.Name != "test" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}))
+		defer server.Close()
+
+		post := HeartbeatPoster(server.Client(), server.URL)
+		assert.That(t,
+			assert.NoError(post(context.Background(), info)),
+			assert.Equal(info.Uptime, received.Uptime),
+			assert.Not(assert.NoError(post(context.Background(), HeartbeatInfo{}))))
+	})
+}
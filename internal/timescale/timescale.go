@@ -0,0 +1,29 @@
+// Package timescale scales the durations of the timers, created by the
+// package, so that the tests can accelerate the time. See
+// [github.com/parametalol/goticks/gotickstest.Accelerate].
+package timescale
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// factor holds the float64 bits of the acceleration factor, 0 meaning 1.
+var factor atomic.Uint64
+
+// Scale returns the duration, divided by the acceleration factor.
+func Scale(d time.Duration) time.Duration {
+	f := math.Float64frombits(factor.Load())
+	if f == 0 || f == 1 {
+		return d
+	}
+	return time.Duration(float64(d) / f)
+}
+
+// Set sets the acceleration factor, and returns the function, restoring the
+// previous one.
+func Set(f float64) (restore func()) {
+	previous := factor.Swap(math.Float64bits(f))
+	return func() { factor.Store(previous) }
+}
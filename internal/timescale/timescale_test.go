@@ -0,0 +1,16 @@
+package timescale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestScale(t *testing.T) {
+	assert.That(t, assert.Equal(time.Second, Scale(time.Second)))
+	restore := Set(100)
+	assert.That(t, assert.Equal(10*time.Millisecond, Scale(time.Second)))
+	restore()
+	assert.That(t, assert.Equal(time.Second, Scale(time.Second)))
+}
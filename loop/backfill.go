@@ -0,0 +1,51 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/parametalol/goticks/utils"
+)
+
+// MissedTicks returns the ticks of a schedule with the given period, started at
+// last, that fall after last and not after now. Only the latest limit ticks
+// are returned, so that a long gap does not grow the result. No ticks are
+// returned if the limit is below 1.
+func MissedTicks(last, now time.Time, period time.Duration, limit int) []time.Time {
+	if period <= 0 || limit < 1 || !now.After(last) {
+		return nil
+	}
+	n := int(now.Sub(last) / period)
+	first := 1
+	if n > limit {
+		first = n - limit + 1
+	}
+	ticks := make([]time.Time, 0, n-first+1)
+	for i := first; i <= n; i++ {
+		ticks = append(ticks, last.Add(time.Duration(i)*period))
+	}
+	return ticks
+}
+
+// BackfillMissed calls task sequentially for every tick, missed since the last
+// one, e.g. while the process was down, with [utils.RunCauseReplay]. The time
+// of the last processed tick is expected to be persisted by the caller. The
+// number of the backfilled ticks is bounded by limit, see [MissedTicks], and
+// their rate may be limited with [utils.ThrottleReplay].
+// The function returns the last task error when all missed ticks are
+// processed, the context cause if the context is cancelled, or the task error
+// wrapping [utils.ErrStopped], wrapped into [*RunError] and [*LoopExitError].
+func BackfillMissed(ctx context.Context, last time.Time, period time.Duration, limit int, task func(context.Context, time.Time) error) error {
+	var err error
+	ctx = utils.WithRunCause(ctx, utils.RunCauseReplay)
+	for i, tick := range MissedTicks(last, time.Now(), period, limit) {
+		if ctx.Err() != nil {
+			return exitError(ExitCancelled, context.Cause(ctx))
+		}
+		if err = task(ctx, tick); errors.Is(err, utils.ErrStopped) {
+			return exitError(ExitTaskStopped, &RunError[time.Time]{tick, i + 1, err})
+		}
+	}
+	return exitError(ExitTicksEnded, err)
+}
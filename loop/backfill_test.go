@@ -0,0 +1,82 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestMissedTicks(t *testing.T) {
+	last := time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC)
+	now := last.Add(3*time.Hour + 30*time.Minute)
+
+	assert.That(t,
+		assert.EqualSlices([]time.Time{
+			last.Add(time.Hour),
+			last.Add(2 * time.Hour),
+			last.Add(3 * time.Hour),
+		}, MissedTicks(last, now, time.Hour, 5)),
+		assert.EqualSlices([]time.Time{
+			last.Add(2 * time.Hour),
+			last.Add(3 * time.Hour),
+		}, MissedTicks(last, now, time.Hour, 2)),
+		assert.EqualSlices([]time.Time{
+			last.Add(3 * time.Hour),
+		}, MissedTicks(last, now, time.Hour, 1)),
+		assert.Equal(0, len(MissedTicks(last, now, time.Hour, 0))),
+		assert.Equal(0, len(MissedTicks(last, now, time.Nanosecond, -1))),
+		assert.Equal(0, len(MissedTicks(now, last, time.Hour, 0))),
+		assert.Equal(0, len(MissedTicks(last, now, 0, 0))))
+}
+
+func TestBackfillMissed(t *testing.T) {
+	last := time.Now().Add(-5*time.Minute - time.Second)
+
+	t.Run("all missed", func(t *testing.T) {
+		var ticks []time.Time
+		err := BackfillMissed(context.Background(), last, time.Minute, 10,
+			utils.AdaptT(func(tick time.Time) {
+				ticks = append(ticks, tick)
+			}))
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal(5, len(ticks)))
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		i := 0
+		err := BackfillMissed(context.Background(), last, time.Minute, 10,
+			func(context.Context, time.Time) error {
+				i++
+				if i == 2 {
+					return fmt.Errorf("stop: %w", utils.ErrStopped)
+				}
+				return nil
+			})
+		var runErr *RunError[time.Time]
+		assert.That(t,
+			assert.ErrorIs(err, utils.ErrStopped),
+			assert.Equal(2, i),
+			assert.True(errors.As(err, &runErr)),
+			assert.Equal(2, runErr.RunID))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(utils.ErrStopped)
+		err := BackfillMissed(ctx, last, time.Minute, 10,
+			utils.AdaptT(func() {
+				t.Error("unexpected call")
+			}))
+		var exit *LoopExitError
+		assert.That(t,
+			assert.ErrorIs(err, utils.ErrStopped),
+			assert.True(errors.As(err, &exit)),
+			assert.Equal(ExitCancelled, exit.Reason))
+	})
+}
@@ -29,5 +29,35 @@ func ExampleOnTick() {
 	// tick 0s
 	// tick 1s
 	// tick 2s
-	// oops
+	// ticks ended: oops
+}
+
+// This example processes all the ticks, collecting the errors instead of
+// stopping on the first one.
+func ExampleOnTickAll() {
+	ticks := func(yield func(int) bool) {
+		for tick := range 3 {
+			if !yield(tick) {
+				return
+			}
+		}
+	}
+
+	err := OnTickAll(context.Background(), ticks,
+		func(_ context.Context, tick int) error {
+			fmt.Println("tick", tick)
+			if tick%2 == 0 {
+				return fmt.Errorf("tick %d failed", tick)
+			}
+			return nil
+		})
+
+	fmt.Println(err)
+
+	// Output:
+	// tick 0
+	// tick 1
+	// tick 2
+	// ticks ended: run #1 on tick 0: tick 0 failed
+	// run #3 on tick 2: tick 2 failed
 }
@@ -0,0 +1,42 @@
+package loop
+
+import "fmt"
+
+// LoopExitError is returned by the loops, which exit with an error. It tells
+// the exit reason, and wraps the last task error or the context cause.
+type LoopExitError struct {
+	Reason ExitReason
+	Err    error
+}
+
+func (e *LoopExitError) Error() string {
+	return e.Reason.String() + ": " + e.Err.Error()
+}
+
+func (e *LoopExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitError returns nil if err is nil, or the [*LoopExitError] otherwise.
+func exitError(reason ExitReason, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &LoopExitError{reason, err}
+}
+
+// RunError wraps the task error, which has terminated the loop, with the tick
+// and the index of the run in the loop, starting from 1.
+type RunError[TickType any] struct {
+	Tick  TickType
+	RunID int
+	Err   error
+}
+
+func (e *RunError[TickType]) Error() string {
+	return fmt.Sprintf("run #%d on tick %v: %v", e.RunID, e.Tick, e.Err)
+}
+
+func (e *RunError[TickType]) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,19 @@
+package loop
+
+import (
+	"context"
+	"iter"
+)
+
+// LoopFunc runs the loop of the task over the ticks, as [OnTick] does.
+type LoopFunc[TickType any] func(ticks iter.Seq[TickType], task func(context.Context, TickType) error) error
+
+// Chain returns the loop function, wrapped with the middleware, the first
+// being the outermost. The middleware may act for the whole lifetime of the
+// loop, e.g. hold a leader lease, rather than per task run.
+func Chain[TickType any](next LoopFunc[TickType], middleware ...func(next LoopFunc[TickType]) LoopFunc[TickType]) LoopFunc[TickType] {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+	return next
+}
@@ -0,0 +1,31 @@
+package loop
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestChain(t *testing.T) {
+	var events []string
+	middleware := func(name string) func(LoopFunc[int]) LoopFunc[int] {
+		return func(next LoopFunc[int]) LoopFunc[int] {
+			return func(ticks iter.Seq[int], task func(context.Context, int) error) error {
+				events = append(events, "enter "+name)
+				defer func() { events = append(events, "exit "+name) }()
+				return next(ticks, task)
+			}
+		}
+	}
+	err := Chain(OnTick[int], middleware("a"), middleware("b"))(slices.Values([]int{1, 2}),
+		func(context.Context, int) error {
+			events = append(events, "run")
+			return nil
+		})
+	assert.That(t,
+		assert.NoError(err),
+		assert.EqualSlices([]string{"enter a", "enter b", "run", "run", "exit b", "exit a"}, events))
+}
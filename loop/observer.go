@@ -0,0 +1,82 @@
+package loop
+
+// ExitReason is the reason of the loop exit.
+type ExitReason int
+
+const (
+	// ExitTicksEnded means the ticks sequence has ended, e.g. the ticker has
+	// been stopped.
+	ExitTicksEnded ExitReason = iota
+	// ExitTaskStopped means the task returned an error, wrapping
+	// [utils.ErrStopped].
+	ExitTaskStopped
+	// ExitCancelled means the loop context has been cancelled.
+	ExitCancelled
+)
+
+func (r ExitReason) String() string {
+	switch r {
+	case ExitTicksEnded:
+		return "ticks ended"
+	case ExitTaskStopped:
+		return "task stopped"
+	case ExitCancelled:
+		return "cancelled"
+	}
+	return "unknown"
+}
+
+// Observer receives the loop events. Any of the callbacks may be nil.
+// The callbacks are called synchronously by the loop.
+type Observer struct {
+	// TickReceived is called when the loop receives a tick.
+	TickReceived func()
+	// RunStarted is called before the task is called.
+	RunStarted func()
+	// RunFinished is called with the task error after the task returns.
+	RunFinished func(error)
+	// TickDropped is called when a tick is received, but the task is not
+	// called, e.g. after the loop context is cancelled, or by a paused task.
+	TickDropped func()
+	// LoopExited is called with the reason and the last task error when the
+	// loop exits.
+	LoopExited func(ExitReason, error)
+}
+
+// The Report methods call the corresponding callbacks, if any, e.g. for the
+// loops, implemented outside of the package. The observer may be nil.
+
+// ReportTickReceived calls TickReceived.
+func (o *Observer) ReportTickReceived() {
+	if o != nil && o.TickReceived != nil {
+		o.TickReceived()
+	}
+}
+
+// ReportRunStarted calls RunStarted.
+func (o *Observer) ReportRunStarted() {
+	if o != nil && o.RunStarted != nil {
+		o.RunStarted()
+	}
+}
+
+// ReportRunFinished calls RunFinished.
+func (o *Observer) ReportRunFinished(err error) {
+	if o != nil && o.RunFinished != nil {
+		o.RunFinished(err)
+	}
+}
+
+// ReportTickDropped calls TickDropped.
+func (o *Observer) ReportTickDropped() {
+	if o != nil && o.TickDropped != nil {
+		o.TickDropped()
+	}
+}
+
+// ReportLoopExited calls LoopExited.
+func (o *Observer) ReportLoopExited(reason ExitReason, err error) {
+	if o != nil && o.LoopExited != nil {
+		o.LoopExited(reason, err)
+	}
+}
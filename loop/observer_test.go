@@ -0,0 +1,75 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestOnTickObserved(t *testing.T) {
+	var events []string
+	observer := &Observer{
+		TickReceived: func() { events = append(events, "received") },
+		RunStarted:   func() { events = append(events, "started") },
+		RunFinished: func(err error) {
+			events = append(events, "finished")
+		},
+		TickDropped: func() { events = append(events, "dropped") },
+		LoopExited: func(reason ExitReason, err error) {
+			events = append(events, reason.String())
+		},
+	}
+
+	err := OnTickObserved(slices.Values([]int{0, 1}), func(_ context.Context, tick int) error {
+		if tick == 1 {
+			return utils.ErrStopped
+		}
+		return nil
+	}, observer)
+	assert.That(t,
+		assert.ErrorIs(err, utils.ErrStopped),
+		assert.EqualSlices([]string{
+			"received", "started", "finished",
+			"received", "started", "finished",
+			"task stopped",
+		}, events))
+
+	events = nil
+	errTest := errors.New("test")
+	err = OnTickObserved(slices.Values([]int{0}), func(context.Context, int) error {
+		return errTest
+	}, observer)
+	assert.That(t,
+		assert.ErrorIs(err, errTest),
+		assert.EqualSlices([]string{"received", "started", "finished", "ticks ended"}, events))
+
+	events = nil
+	err = OnTickAllObserved(context.Background(), slices.Values([]int{0, 1}), func(context.Context, int) error {
+		return errTest
+	}, observer)
+	assert.That(t,
+		assert.Equal("ticks ended: run #1 on tick 0: test\nrun #2 on tick 1: test", err.Error()),
+		assert.EqualSlices([]string{
+			"received", "started", "finished",
+			"received", "started", "finished",
+			"ticks ended",
+		}, events))
+
+	events = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = onTick(ctx, slices.Values([]int{0}), func(context.Context, int) error {
+		return errTest
+	}, observer, false)
+	// The loop may receive the tick before it sees the cancellation.
+	if len(events) > 1 {
+		assert.That(t, assert.EqualSlices([]string{"received", "dropped", "cancelled"}, events))
+	}
+	assert.That(t,
+		assert.ErrorIs(err, context.Canceled),
+		assert.Equal("cancelled", events[len(events)-1]))
+}
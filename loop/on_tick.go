@@ -10,16 +10,125 @@ import (
 
 // OnTick calls task on every tick from the ticker.
 // The function returns the last task error when the ticker is stopped, or task
-// fails with [ErrStopped].
+// fails with [ErrStopped]. A non-nil error is wrapped into [*LoopExitError],
+// and the error of the task, which has stopped the loop, into [*RunError] too.
 func OnTick[TickType any](ticks iter.Seq[TickType], task func(context.Context, TickType) error) error {
-	ctx, cancel := context.WithCancelCause(context.Background())
+	return OnTickObserved(ticks, task, nil)
+}
+
+// OnTickObserved is [OnTick], reporting the loop events to the observer, which
+// may be nil.
+func OnTickObserved[TickType any](ticks iter.Seq[TickType], task func(context.Context, TickType) error, observer *Observer) error {
+	return onTick(context.Background(), ticks, task, observer, false)
+}
+
+// OnTickContext is [OnTick], which task runs inherit the context. When the
+// context is cancelled, the run in progress is cancelled with it, and the loop
+// exits with [ExitCancelled] as soon as the run returns, without waiting for
+// the next tick.
+func OnTickContext[TickType any](ctx context.Context, ticks iter.Seq[TickType], task func(context.Context, TickType) error) error {
+	return onTick(ctx, ticks, task, nil, false)
+}
+
+// onTick runs the loop. If all is true, the loop keeps running after the task
+// errors, except the ones wrapping [utils.ErrStopped], and returns all of them,
+// as [OnTickAll] does, instead of the last one.
+func onTick[TickType any](parent context.Context, ticks iter.Seq[TickType], task func(context.Context, TickType) error, observer *Observer, all bool) error {
+	ctx, cancel := context.WithCancelCause(parent)
 	defer cancel(utils.ErrStopped)
 	var err error
-	for tick := range ticks {
-		if err = task(ctx, tick); errors.Is(err, utils.ErrStopped) {
+	var errs []error
+	reason := ExitTicksEnded
+	runID := 0
+	for tick := range pullTicks(parent, ticks) {
+		observer.ReportTickReceived()
+		if parent.Err() != nil {
+			observer.ReportTickDropped()
+			break
+		}
+		observer.ReportRunStarted()
+		runID++
+		err = task(ctx, tick)
+		observer.ReportRunFinished(err)
+		stopped := errors.Is(err, utils.ErrStopped)
+		if stopped || all && err != nil {
+			err = &RunError[TickType]{tick, runID, err}
+			errs = append(errs, err)
+		}
+		if stopped {
+			reason = ExitTaskStopped
 			// This returns false to the ticks iterator.
 			break
 		}
 	}
-	return err
+	if reason == ExitTicksEnded && parent.Err() != nil {
+		reason, err = ExitCancelled, context.Cause(parent)
+		errs = append(errs, err)
+	}
+	if all {
+		err = errors.Join(errs...)
+	}
+	observer.ReportLoopExited(reason, err)
+	return exitError(reason, err)
+}
+
+// pullTicks returns the iterator over the ticks, which ends as soon as the
+// context is done, without waiting for the next tick. The ticks are received
+// by a goroutine, which passes them one by one, so that a tick is
+// acknowledged to the ticker only after it is processed. Once the iterator
+// ends, the goroutine exits on the next tick or on the end of the ticks.
+func pullTicks[TickType any](ctx context.Context, ticks iter.Seq[TickType]) iter.Seq[TickType] {
+	if ctx.Done() == nil {
+		return ticks
+	}
+	return func(yield func(TickType) bool) {
+		received := make(chan TickType)
+		processed := make(chan struct{})
+		ended := make(chan struct{})
+		quit := make(chan struct{})
+		defer close(quit)
+		go func() {
+			defer close(ended)
+			for tick := range ticks {
+				select {
+				case received <- tick:
+				case <-quit:
+					return
+				}
+				select {
+				case <-processed:
+				case <-quit:
+					return
+				}
+			}
+		}()
+		for {
+			select {
+			case tick := <-received:
+				if !yield(tick) {
+					return
+				}
+				processed <- struct{}{}
+			case <-ended:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// OnTickAll is [OnTickContext], which keeps running after the task errors,
+// except the ones wrapping [utils.ErrStopped], e.g. for a finite sequence of
+// replayed or backfilled ticks, and returns all of them, each wrapped into
+// [*RunError], joined with [errors.Join] and the context cause, if the loop is
+// cancelled. A non-nil error is wrapped into [*LoopExitError].
+func OnTickAll[TickType any](ctx context.Context, ticks iter.Seq[TickType], task func(context.Context, TickType) error) error {
+	return onTick(ctx, ticks, task, nil, true)
+}
+
+// OnTickAllObserved is [OnTickAll], reporting the loop events to the observer,
+// which may be nil.
+func OnTickAllObserved[TickType any](ctx context.Context, ticks iter.Seq[TickType], task func(context.Context, TickType) error, observer *Observer) error {
+	return onTick(ctx, ticks, task, observer, true)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -69,8 +70,17 @@ func TestOnTick(t *testing.T) {
 		go tickInRange(ticker, 5)
 
 		err := OnTick(ticks, counter)
+		var exit *LoopExitError
+		var runErr *RunError[int]
 		assert.That(t,
-			assert.ErrorIs(err, utils.ErrStopped))
+			assert.ErrorIs(err, utils.ErrStopped),
+			assert.True(errors.As(err, &exit)),
+			assert.Equal(ExitTaskStopped, exit.Reason),
+			assert.True(errors.As(exit.Err, &runErr)),
+			assert.Equal(3, runErr.Tick),
+			assert.True(runErr.RunID > 0),
+			assert.Equal[error](permErr, runErr.Err),
+			assert.Equal(fmt.Sprintf("task stopped: run #%d on tick 3: stop error: stopped", runErr.RunID), err.Error()))
 	})
 
 	t.Run("one ticker two loops", func(t *testing.T) {
@@ -101,3 +111,88 @@ func tickInRange(ticker tickerWithTick[int], n int) {
 	ticker.Wait()
 	ticker.Stop()
 }
+
+func TestOnTickContext(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	ch := make(chan int)
+	var ticks []int
+	done := make(chan error)
+	go func() {
+		done <- OnTickContext(ctx, ticker.FromChan(ch).Ticks(), func(ctx context.Context, tick int) error {
+			ticks = append(ticks, tick)
+			if tick == 1 {
+				cancel(errors.New("shutdown"))
+				<-ctx.Done()
+			}
+			return nil
+		})
+	}()
+	ch <- 0
+	ch <- 1
+	// The loop exits without waiting for the next tick.
+	err := <-done
+	var exit *LoopExitError
+	assert.That(t,
+		assert.EqualSlices([]int{0, 1}, ticks),
+		assert.True(errors.As(err, &exit)),
+		assert.Equal(ExitCancelled, exit.Reason),
+		assert.Equal("shutdown", exit.Err.Error()))
+
+	t.Run("without ticks", func(t *testing.T) {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		tick := ticker.New[int]()
+		done := make(chan error)
+		go func() {
+			done <- OnTickContext(ctx, tick.Ticks(), func(context.Context, int) error {
+				return errors.New("unexpected run")
+			})
+		}()
+		cancel(errors.New("shutdown"))
+		err := <-done
+		assert.That(t,
+			assert.True(errors.As(err, &exit)),
+			assert.Equal(ExitCancelled, exit.Reason),
+			assert.Equal("shutdown", exit.Err.Error()))
+		// The ticks after the exit are dropped.
+		tick.Tick(0).Wait()
+	})
+}
+
+func TestOnTickAll(t *testing.T) {
+	errOdd := errors.New("odd")
+	task := func(_ context.Context, tick int) error {
+		switch {
+		case tick == 5:
+			return utils.ErrStopped
+		case tick%2 == 1:
+			return errOdd
+		}
+		return nil
+	}
+	err := OnTickAll(context.Background(), slices.Values([]int{0, 1, 2, 3}), task)
+	var exitErr *LoopExitError
+	assert.That(t,
+		assert.ErrorIs(err, errOdd),
+		assert.True(errors.As(err, &exitErr)),
+		assert.Equal(ExitTicksEnded, exitErr.Reason),
+		assert.Equal("ticks ended: run #2 on tick 1: odd\nrun #4 on tick 3: odd", err.Error()))
+
+	assert.That(t, assert.NoError(OnTickAll(context.Background(), slices.Values([]int{0, 2}), task)))
+
+	err = OnTickAll(context.Background(), slices.Values([]int{1, 5, 7}), task)
+	assert.That(t,
+		assert.ErrorIs(err, utils.ErrStopped),
+		assert.Equal("task stopped: run #1 on tick 1: odd\nrun #2 on tick 5: stopped", err.Error()))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	err = OnTickAll(ctx, slices.Values([]int{1, 2, 3}), func(ctx context.Context, tick int) error {
+		if tick == 2 {
+			cancel(errors.New("shutdown"))
+		}
+		return task(ctx, tick)
+	})
+	assert.That(t,
+		assert.True(errors.As(err, &exitErr)),
+		assert.Equal(ExitCancelled, exitErr.Reason),
+		assert.Equal("cancelled: run #1 on tick 1: odd\nshutdown", err.Error()))
+}
@@ -0,0 +1,18 @@
+package loop
+
+import (
+	"context"
+	"slices"
+
+	"github.com/parametalol/goticks/utils"
+)
+
+// ReplayTicks calls task sequentially for every tick, e.g. historical tick
+// timestamps, as fast as possible. The ticks go through the same task wrappers
+// as the live ones, and the task errors are handled as by [OnTick]. The runs
+// are invoked with [utils.RunCauseReplay].
+func ReplayTicks[TickType any](ticks []TickType, task func(context.Context, TickType) error) error {
+	return OnTick(slices.Values(ticks), func(ctx context.Context, tick TickType) error {
+		return task(utils.WithRunCause(ctx, utils.RunCauseReplay), tick)
+	})
+}
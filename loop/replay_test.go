@@ -0,0 +1,32 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestReplayTicks(t *testing.T) {
+	start := time.Date(2025, 5, 4, 0, 0, 0, 0, time.UTC)
+	history := []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour)}
+
+	var replayed []time.Time
+	err := ReplayTicks(history, utils.NoOverlap[time.Time](
+		func(ctx context.Context, tick time.Time) error {
+			if utils.RunCauseFromContext(ctx) != utils.RunCauseReplay {
+				return errors.New("unexpected run cause")
+			}
+			replayed = append(replayed, tick)
+			if len(replayed) == 2 {
+				return utils.ErrStopped
+			}
+			return nil
+		}))
+	assert.That(t,
+		assert.ErrorIs(err, utils.ErrStopped),
+		assert.EqualSlices(history[:2], replayed))
+}
@@ -0,0 +1,181 @@
+package goticks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parametalol/goticks/utils"
+)
+
+// Metrics collects the run metrics of the tasks, configured with
+// [WithMetrics], and exposes them in the OpenMetrics text format.
+type Metrics struct {
+	mux   sync.Mutex
+	tasks map[string]*taskMetrics
+}
+
+type taskMetrics struct {
+	running   func() bool
+	nextRun   func() time.Time
+	intervals *utils.IntervalStats
+	outcomes  [3]uint64
+	count     uint64
+	sum       time.Duration
+	last      time.Time
+}
+
+// NewMetrics returns an empty metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{tasks: map[string]*taskMetrics{}}
+}
+
+// task returns the metrics of the named task, registering it if needed. The
+// run intervals are accounted in the given stats, if not nil.
+func (m *Metrics) task(name string, running func() bool, nextRun func() time.Time, intervals *utils.IntervalStats) *taskMetrics {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	tm, ok := m.tasks[name]
+	if !ok {
+		tm = &taskMetrics{}
+		m.tasks[name] = tm
+	}
+	tm.running = running
+	tm.nextRun = nextRun
+	if intervals != nil {
+		tm.intervals = intervals
+	} else if tm.intervals == nil {
+		tm.intervals = &utils.IntervalStats{}
+	}
+	return tm
+}
+
+func (m *Metrics) observe(tm *taskMetrics, start time.Time, d time.Duration) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	tm.count++
+	tm.sum += d
+	tm.last = start
+}
+
+func (m *Metrics) classify(tm *taskMetrics, outcome utils.RunOutcome) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if int(outcome) < len(tm.outcomes) {
+		tm.outcomes[outcome]++
+	}
+}
+
+// measure wraps the task to account its runs in the task metrics. The run
+// results are also passed to onRun, if not nil.
+func measure[TickType any](m *Metrics, tm *taskMetrics, onRun func(utils.RunResult), task func(context.Context, TickType) error) func(context.Context, TickType) error {
+	return utils.Classify[TickType](func(result utils.RunResult) {
+		m.classify(tm, result.Outcome)
+		if onRun != nil {
+			onRun(result)
+		}
+	}, utils.TrackIntervals[TickType](tm.intervals, func(ctx context.Context, tick TickType) error {
+		start := time.Now()
+		err := task(ctx, tick)
+		m.observe(tm, start, time.Since(start))
+		return err
+	}))
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// WriteMetrics writes the metrics in the OpenMetrics text exposition format,
+// which is also accepted by Prometheus:
+//   - goticks_runs_total{task, outcome}: the number of runs by outcome;
+//   - goticks_run_duration_seconds{task}: the summary of the run durations;
+//   - goticks_run_interval_seconds{task}: the summary of the intervals between
+//     the run starts, with the 0.95 quantile over the recent runs;
+//   - goticks_run_interval_max_seconds{task}: the maximal interval;
+//   - goticks_period_seconds{task}: the configured period, if known, see
+//     [WithIntervalStats];
+//   - goticks_last_run_timestamp_seconds{task}: the start of the last run;
+//   - goticks_next_run_timestamp_seconds{task}: the time of the next scheduled
+//     run, if known, see [RestartableWithTicker] NextRun;
+//   - goticks_running{task}: 1 if the task is running, 0 otherwise.
+func (m *Metrics) WriteMetrics(w io.Writer) error {
+	m.mux.Lock()
+	names := make([]string, 0, len(m.tasks))
+	for name := range m.tasks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	type snapshot struct {
+		name string
+		taskMetrics
+		utils.IntervalSummary
+	}
+	tasks := make([]snapshot, 0, len(names))
+	for _, name := range names {
+		tm := m.tasks[name]
+		tasks = append(tasks, snapshot{escapeLabel(name), *tm, tm.intervals.Summary()})
+	}
+	m.mux.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# TYPE goticks_runs counter\n# HELP goticks_runs Task runs by outcome.\n")
+	for _, tm := range tasks {
+		for outcome, n := range tm.outcomes {
+			fmt.Fprintf(&b, "goticks_runs_total{task=\"%s\",outcome=\"%s\"} %d\n", tm.name, utils.RunOutcome(outcome), n)
+		}
+	}
+	b.WriteString("# TYPE goticks_run_duration_seconds summary\n# UNIT goticks_run_duration_seconds seconds\n# HELP goticks_run_duration_seconds Task run durations.\n")
+	for _, tm := range tasks {
+		fmt.Fprintf(&b, "goticks_run_duration_seconds_sum{task=\"%s\"} %g\n", tm.name, tm.sum.Seconds())
+		fmt.Fprintf(&b, "goticks_run_duration_seconds_count{task=\"%s\"} %d\n", tm.name, tm.count)
+	}
+	b.WriteString("# TYPE goticks_run_interval_seconds summary\n# UNIT goticks_run_interval_seconds seconds\n# HELP goticks_run_interval_seconds Intervals between the task run starts.\n")
+	for _, tm := range tasks {
+		if tm.Count > 0 {
+			fmt.Fprintf(&b, "goticks_run_interval_seconds{task=\"%s\",quantile=\"0.95\"} %g\n", tm.name, tm.P95.Seconds())
+		}
+		fmt.Fprintf(&b, "goticks_run_interval_seconds_sum{task=\"%s\"} %g\n", tm.name, tm.Sum.Seconds())
+		fmt.Fprintf(&b, "goticks_run_interval_seconds_count{task=\"%s\"} %d\n", tm.name, tm.Count)
+	}
+	b.WriteString("# TYPE goticks_run_interval_max_seconds gauge\n# UNIT goticks_run_interval_max_seconds seconds\n# HELP goticks_run_interval_max_seconds Maximal interval between the task run starts.\n")
+	for _, tm := range tasks {
+		fmt.Fprintf(&b, "goticks_run_interval_max_seconds{task=\"%s\"} %g\n", tm.name, tm.Max.Seconds())
+	}
+	b.WriteString("# TYPE goticks_period_seconds gauge\n# UNIT goticks_period_seconds seconds\n# HELP goticks_period_seconds Configured period of the task.\n")
+	for _, tm := range tasks {
+		if tm.Period > 0 {
+			fmt.Fprintf(&b, "goticks_period_seconds{task=\"%s\"} %g\n", tm.name, tm.Period.Seconds())
+		}
+	}
+	b.WriteString("# TYPE goticks_last_run_timestamp_seconds gauge\n# UNIT goticks_last_run_timestamp_seconds seconds\n# HELP goticks_last_run_timestamp_seconds Start time of the last task run.\n")
+	for _, tm := range tasks {
+		if !tm.last.IsZero() {
+			fmt.Fprintf(&b, "goticks_last_run_timestamp_seconds{task=\"%s\"} %.3f\n", tm.name, float64(tm.last.UnixMilli())/1e3)
+		}
+	}
+	b.WriteString("# TYPE goticks_next_run_timestamp_seconds gauge\n# UNIT goticks_next_run_timestamp_seconds seconds\n# HELP goticks_next_run_timestamp_seconds Time of the next scheduled task run.\n")
+	for _, tm := range tasks {
+		if tm.nextRun == nil {
+			continue
+		}
+		if next := tm.nextRun(); !next.IsZero() {
+			fmt.Fprintf(&b, "goticks_next_run_timestamp_seconds{task=\"%s\"} %.3f\n", tm.name, float64(next.UnixMilli())/1e3)
+		}
+	}
+	b.WriteString("# TYPE goticks_running gauge\n# HELP goticks_running Whether the task is running.\n")
+	for _, tm := range tasks {
+		running := 0
+		if tm.running != nil && tm.running() {
+			running = 1
+		}
+		fmt.Fprintf(&b, "goticks_running{task=\"%s\"} %d\n", tm.name, running)
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
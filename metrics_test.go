@@ -0,0 +1,58 @@
+package goticks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	tick := make(chan time.Time)
+	done := make(chan utils.RunResult)
+	calls := 0
+	task := NewTaskFromTicks(tick, func() error {
+		calls++
+		if calls == 2 {
+			return errors.New("failed")
+		}
+		return nil
+	}, WithMetrics(m), WithName(`a "quoted" task`), WithIntervalStats(&utils.IntervalStats{Period: time.Second}), WithOnRun(func(r utils.RunResult) { done <- r }))
+	task.Start()
+	scheduled := NewTask(ticker.NewTimer(time.Hour), func() {}, WithMetrics(m), WithName("scheduled"), WithTickerStop())
+	scheduled.Start()
+	defer scheduled.Stop()
+	for range 3 {
+		tick <- time.Now()
+		<-done
+	}
+
+	var b strings.Builder
+	assert.That(t, assert.NoError(m.WriteMetrics(&b)))
+	out := b.String()
+	for _, line := range []string{
+		`goticks_runs_total{task="a \"quoted\" task",outcome="executed"} 2`,
+		`goticks_runs_total{task="a \"quoted\" task",outcome="failed"} 1`,
+		`goticks_run_duration_seconds_count{task="a \"quoted\" task"} 3`,
+		`goticks_run_interval_seconds_count{task="a \"quoted\" task"} 2`,
+		`goticks_period_seconds{task="a \"quoted\" task"} 1`,
+		`goticks_running{task="a \"quoted\" task"} 1`,
+	} {
+		assert.That(t, assert.True(strings.Contains(out, line+"\n")))
+	}
+	assert.That(t,
+		assert.True(strings.Contains(out, "goticks_last_run_timestamp_seconds{")),
+		assert.True(strings.Contains(out, `goticks_next_run_timestamp_seconds{task="scheduled"} `)),
+		assert.False(strings.Contains(out, `goticks_next_run_timestamp_seconds{task="a`)),
+		assert.True(strings.HasSuffix(out, "# EOF\n")))
+
+	task.Stop()
+	b.Reset()
+	assert.That(t, assert.NoError(m.WriteMetrics(&b)))
+	assert.That(t, assert.True(strings.Contains(b.String(), `goticks_running{task="a \"quoted\" task"} 0`)))
+}
@@ -0,0 +1,55 @@
+package goticks
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TaskFailure describes the terminal failure of a task.
+type TaskFailure struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+	// Err is the task function error, which stopped the task.
+	Err error `json:"-"`
+	// Error is the message of Err.
+	Error string `json:"error"`
+}
+
+// DefaultNotifyTimeout bounds the notification of a task failure, e.g. the
+// wait for the [FailureChan] reader, or the [FailurePoster] request.
+const DefaultNotifyTimeout = 10 * time.Second
+
+// Notifier is notified of the terminal task failures.
+type Notifier interface {
+	Notify(context.Context, TaskFailure)
+}
+
+// NotifierFunc is a function, implementing [Notifier].
+type NotifierFunc func(context.Context, TaskFailure)
+
+func (f NotifierFunc) Notify(ctx context.Context, failure TaskFailure) {
+	f(ctx, failure)
+}
+
+// FailureChan returns a notifier, that sends the failures to the channel.
+// The sending blocks the notification until the failure is received.
+func FailureChan(ch chan<- TaskFailure) Notifier {
+	return NotifierFunc(func(ctx context.Context, failure TaskFailure) {
+		select {
+		case ch <- failure:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// FailurePoster returns a notifier, that posts the failures as JSON to the
+// webhook URL with the client. The post errors are passed to onError, if not
+// nil.
+func FailurePoster(client *http.Client, url string, onError func(error)) Notifier {
+	return NotifierFunc(func(ctx context.Context, failure TaskFailure) {
+		if err := postJSON(ctx, client, url, "failure", failure); err != nil && onError != nil {
+			onError(err)
+		}
+	})
+}
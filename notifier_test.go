@@ -0,0 +1,86 @@
+package goticks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/internal/timescale"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestWithFailureNotifier(t *testing.T) {
+	failures := make(chan TaskFailure)
+	ch := make(chan int)
+	task := NewTaskFromTicks(ch, func(tick int) error {
+		if tick == 0 {
+			return utils.ErrStopped
+		}
+		return fmt.Errorf("database is gone: %w", utils.ErrStopped)
+	}, WithName("test"), WithFailureNotifier(FailureChan(failures)))
+
+	stopped := make(chan error, 1)
+	task.OnStop(func(cause error) { stopped <- cause })
+	task.Start()
+	ch <- 0
+	<-stopped
+	select {
+	case <-failures:
+		t.Fatal("unexpected notification of a self stop")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	task.Start()
+	ch <- 1
+	failure := <-failures
+	assert.That(t,
+		assert.Equal("test", failure.Name),
+		assert.ErrorIs(failure.Err, utils.ErrStopped),
+		assert.Equal("database is gone: stopped", failure.Error))
+	close(ch)
+}
+
+func TestWithFailureNotifier_Timeout(t *testing.T) {
+	t.Cleanup(timescale.Set(1000))
+	deadline := make(chan bool, 1)
+	done := make(chan error, 1)
+	ch := make(chan int)
+	task := NewTaskFromTicks(ch, func() error { return fmt.Errorf("gone: %w", utils.ErrStopped) },
+		WithName("test"), WithFailureNotifier(NotifierFunc(func(ctx context.Context, _ TaskFailure) {
+			_, ok := ctx.Deadline()
+			deadline <- ok
+			// E.g. no reader of FailureChan.
+			<-ctx.Done()
+			done <- ctx.Err()
+		})))
+	task.Start()
+	ch <- 0
+	assert.That(t,
+		assert.True(<-deadline),
+		assert.ErrorIs(<-done, context.DeadlineExceeded))
+}
+
+func TestFailurePoster(t *testing.T) {
+	received := make(chan TaskFailure, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var failure TaskFailure
+		_ = json.NewDecoder(r.Body).Decode(&failure)
+		received <- failure
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	var postErr error
+	FailurePoster(server.Client(), server.URL, func(err error) { postErr = err }).
+		Notify(context.Background(), TaskFailure{Name: "test", Error: "failed"})
+	failure := <-received
+	assert.That(t,
+		assert.Equal("test", failure.Name),
+		assert.Equal("failed", failure.Error),
+		assert.Equal("failure post failed with status 418 I'm a teapot", postErr.Error()))
+}
@@ -1,9 +1,75 @@
 package goticks
 
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/parametalol/goticks/loop"
+	"github.com/parametalol/goticks/utils"
+)
+
 type options struct {
+	parent     context.Context
 	onStart    func() error
 	onStop     func()
 	stopTicker bool
+	finalRun   bool
+
+	name        string
+	timeout     time.Duration
+	autoTimeout float64
+	retry       utils.RetryPolicy
+	isFatal     func(error) bool
+	reportSkips bool
+	idleRuns    int
+	isNoOp      func(error) bool
+	// idempotencyKey is func(TickType) string.
+	idempotencyKey any
+	keyStore       utils.KeyStore
+	inFlight       bool
+	logOut         io.Writer
+	logErr         io.Writer
+	runLogs        *utils.RunLogs
+	runLogHandler  slog.Handler
+	journal        *utils.Journal
+	onRun          func(utils.RunResult)
+	// onSuccess is called after every executed run.
+	onSuccess    func()
+	pool         *utils.Pool
+	shaper       *utils.Shaper
+	lowPriority  bool
+	healthProbe  func(context.Context) error
+	healthPolicy utils.RetryPolicy
+	startGate    func(context.Context) error
+	startPolicy  utils.RetryPolicy
+	loadShedder  *utils.LoadShedder
+	loadLowPri   bool
+	maxLoadDefer time.Duration
+	window       *utils.Window
+	windowPolicy utils.WindowPolicy
+	minGap       time.Duration
+	observer     *loop.Observer
+	sequence     *utils.TickSequence
+	// loopMiddleware is func(loop.LoopFunc[TickType]) loop.LoopFunc[TickType].
+	loopMiddleware any
+	failures       *utils.FailureStats
+	intervals      *utils.IntervalStats
+
+	startSpread       time.Duration
+	startSpreadRandom bool
+
+	heartbeatFields func() map[string]string
+	heartbeatTasks  func() []TaskStatus
+	metrics         *Metrics
+
+	notifier Notifier
+	// onFailure is called with the task function error, which has stopped
+	// the task.
+	onFailure func(error)
+
+	onTransition func(from, to state)
 }
 
 type option func(*options)
@@ -20,8 +86,333 @@ func WithOnStop(f func()) option {
 	}
 }
 
+// WithParentContext makes the task run contexts provide the values of the
+// parent context, e.g. the application logger, tracing or tenant, which the
+// context, created by the task loop, does not have. The cancellation of the
+// parent context does not affect the task, which should be stopped with
+// [Stop] instead.
+func WithParentContext(ctx context.Context) option {
+	return func(o *options) {
+		o.parent = ctx
+	}
+}
+
+// WithFinalRun makes the task run one last time on stop with
+// [utils.RunCauseFinal], after the run in progress finishes, e.g. to flush the
+// buffered state. The tick of the final run is the current time for the
+// [time.Time] ticks, and the zero value otherwise.
+// [Stop] waits for the final run at most [DefaultFinalRunTimeout], after which
+// the run context is cancelled. The task methods, called by the final run, see
+// the task stopping: Start and Stop have no effect.
+func WithFinalRun() option {
+	return func(o *options) {
+		o.finalRun = true
+	}
+}
+
 func WithTickerStop() option {
 	return func(o *options) {
 		o.stopTicker = true
 	}
 }
+
+// WithName names the task in its [TaskSetup]. [BuildAll] names the tasks by
+// their configuration.
+func WithName(name string) option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithTimeout limits the duration of every run attempt. See [utils.Timeout].
+func WithTimeout(d time.Duration) option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithAutoTimeout limits every task run by multiplier times the learnt typical
+// run duration. See [utils.AutoTimeout].
+func WithAutoTimeout(multiplier float64) option {
+	return func(o *options) {
+		o.autoTimeout = multiplier
+	}
+}
+
+// WithRetry makes the task retry the failed runs according to the policy.
+// The attempt number is propagated in the run context, as by [utils.Retry].
+func WithRetry(policy utils.RetryPolicy) option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithErrorFilter makes the task stop on the run errors, for which isFatal
+// returns true, as if they wrapped [utils.ErrStopped]. The other errors are
+// reported to the observers, the metrics and [WithOnRun], and the task keeps
+// running. The errors, wrapping [utils.ErrStopped], always stop the task.
+// See [utils.StopOn].
+func WithErrorFilter(isFatal func(error) bool) option {
+	return func(o *options) {
+		o.isFatal = isFatal
+	}
+}
+
+// WithSkipErrors makes the skipped runs return [*utils.SkipError], wrapping
+// [utils.ErrSkipped], e.g. to the loop observer, see [WithLoopObserver],
+// which can count the skips without treating them as failures. The skips are
+// classified as such by the metrics and [WithOnRun] either way. See
+// [utils.ReportSkips].
+func WithSkipErrors() option {
+	return func(o *options) {
+		o.reportSkips = true
+	}
+}
+
+// WithIdleStop makes the task stop itself after n consecutive no-op runs, for
+// which isNoOp returns true. With [WithTickerStop], the ticker is stopped as
+// well. See [utils.IdleStop].
+func WithIdleStop(n int, isNoOp func(err error) bool) option {
+	return func(o *options) {
+		o.idleRuns = n
+		o.isNoOp = isNoOp
+	}
+}
+
+// WithIdempotencyKey makes the task skip the ticks, which keys have been
+// recorded in the store by the previous runs. The tick type of the key
+// function must match the task tick type, or the task construction panics, and
+// Reload returns an error, wrapping [ErrOptionType]. See [utils.Idempotent].
+func WithIdempotencyKey[TickType any](key func(TickType) string, store utils.KeyStore) option {
+	return func(o *options) {
+		o.idempotencyKey = key
+		o.keyStore = store
+	}
+}
+
+// WithInFlight makes the task register its runs in progress under the task
+// name of [WithName], so that the stuck runs can be listed with
+// [utils.DumpInFlight]. See [utils.TrackInFlight].
+func WithInFlight() option {
+	return func(o *options) {
+		o.inFlight = true
+	}
+}
+
+// WithLog makes the task log its runs and errors to the writers under the task
+// name of [WithName], including the retries of [WithRetry]. See [utils.Log].
+func WithLog(outW, errW io.Writer) option {
+	return func(o *options) {
+		o.logOut, o.logErr = outW, errW
+	}
+}
+
+// WithRunLogs makes the task keep the logs of its recent runs, written with the
+// run logger, in the logs. See [utils.CaptureRunLog].
+func WithRunLogs(logs *utils.RunLogs, handler slog.Handler) option {
+	return func(o *options) {
+		o.runLogs, o.runLogHandler = logs, handler
+	}
+}
+
+// WithJournal records the start and the end of every task run with the
+// outcome and the task name of [WithName] in the journal, which may be shared
+// by multiple tasks. See [utils.Journaled].
+func WithJournal(j *utils.Journal) option {
+	return func(o *options) {
+		o.journal = j
+	}
+}
+
+// WithOnRun sets the function, called with the outcome of every task run.
+// See [utils.Classify].
+func WithOnRun(f func(utils.RunResult)) option {
+	return func(o *options) {
+		o.onRun = f
+	}
+}
+
+// withOnSuccess calls f after every run, which has been executed without
+// error.
+func withOnSuccess(f func()) option {
+	return func(o *options) {
+		o.onSuccess = f
+	}
+}
+
+// withOnFailure calls f in a separate goroutine with the task function error,
+// which has stopped the task.
+func withOnFailure(f func(error)) option {
+	return func(o *options) {
+		o.onFailure = f
+	}
+}
+
+// WithPool makes the task runs executed on the workers of the pool, which may be
+// shared by multiple tasks. See [utils.InPool].
+func WithPool(p *utils.Pool) option {
+	return func(o *options) {
+		o.pool = p
+	}
+}
+
+// WithShaper makes the task account its run starts in the shaper, shared with
+// other tasks, and, if lowPriority, delay them while the aggregate rate exceeds
+// the shaper limit. See [utils.Shape].
+func WithShaper(s *utils.Shaper, lowPriority bool) option {
+	return func(o *options) {
+		o.shaper = s
+		o.lowPriority = lowPriority
+	}
+}
+
+// withLowPriority makes the task low priority for the shaper and the load
+// shedder, given with their options.
+func withLowPriority() option {
+	return func(o *options) {
+		o.lowPriority = true
+		o.loadLowPri = true
+	}
+}
+
+// WithStartSpread makes [NewAdmin] and [Admin.StartAll] start the tasks over
+// the window instead of all at once, as [StartSpread] does. The tasks,
+// configured with [TaskConfig] After, are started after their dependencies as
+// usual. The option is ignored by [NewTask].
+func WithStartSpread(window time.Duration, random bool) option {
+	return func(o *options) {
+		o.startSpread = window
+		o.startSpreadRandom = random
+	}
+}
+
+// WithHealthGate makes the task skip the runs while the probe fails. If the
+// policy is not nil, the failed probe is retried according to the policy
+// before skipping the run, delaying it with backoff. See [utils.HealthGate].
+func WithHealthGate(probe func(context.Context) error, policy utils.RetryPolicy) option {
+	return func(o *options) {
+		o.healthProbe = probe
+		o.healthPolicy = policy
+	}
+}
+
+// WithStartGate holds the first task run until the gate, e.g. checking that a
+// dependency is ready, passes, retrying it according to the policy. The runs
+// are skipped while the gate fails. See [utils.StartGate].
+func WithStartGate(gate func(context.Context) error, policy utils.RetryPolicy) option {
+	return func(o *options) {
+		o.startGate = gate
+		o.startPolicy = policy
+	}
+}
+
+// WithLoadShedding makes the task, if lowPriority, skip the runs while the
+// shedder reports high load, or defer them up to maxDefer. See
+// [utils.LoadShedding].
+func WithLoadShedding(s *utils.LoadShedder, lowPriority bool, maxDefer time.Duration) option {
+	return func(o *options) {
+		o.loadShedder = s
+		o.loadLowPri = lowPriority
+		o.maxLoadDefer = maxDefer
+	}
+}
+
+// WithAllowedWindow lets the task run only between start and end of the day in
+// the location, e.g. during the business hours. The ticks outside the window
+// are deferred until it opens, or skipped, according to the policy. See
+// [utils.InWindow].
+func WithAllowedWindow(start, end utils.TimeOfDay, loc *time.Location, policy utils.WindowPolicy) option {
+	return func(o *options) {
+		o.window = &utils.Window{Start: start, End: end, Location: loc}
+		o.windowPolicy = policy
+	}
+}
+
+// WithMinGap delays the task runs to keep at least d between the end of a run
+// and the start of the next one. See [utils.MinGap].
+func WithMinGap(d time.Duration) option {
+	return func(o *options) {
+		o.minGap = d
+	}
+}
+
+// WithLoopObserver sets the observer of the task loop events. The ticks,
+// received while the task is stopped, are reported as dropped.
+func WithLoopObserver(o *loop.Observer) option {
+	return func(opts *options) {
+		opts.observer = o
+	}
+}
+
+// WithTickSequence numbers the ticks, received by the task, in the sequence,
+// and accounts the ticks, which have not produced a run: dropped by the stopped
+// task with [SkipReasonPaused], or skipped by the wrappers, e.g. by
+// [WithLoadShedding] or [WithPool] on overflow. See [utils.Sequence].
+func WithTickSequence(s *utils.TickSequence) option {
+	return func(o *options) {
+		o.sequence = s
+	}
+}
+
+// WithLoopMiddleware wraps the task loop with the middleware, e.g. to hold a
+// leader lease for the whole lifetime of the loop rather than per run. The
+// tick type of the middleware must match the task tick type, or the task
+// construction panics, and Reload returns an error, wrapping [ErrOptionType].
+// The middleware is applied to the loops, started after the change.
+func WithLoopMiddleware[TickType any](middleware func(next loop.LoopFunc[TickType]) loop.LoopFunc[TickType]) option {
+	return func(o *options) {
+		o.loopMiddleware = middleware
+	}
+}
+
+// WithFailureStats makes the task recover from panics, and account the failures
+// by their fingerprints in the stats. See [utils.TrackFailures].
+func WithFailureStats(stats *utils.FailureStats) option {
+	return func(o *options) {
+		o.failures = stats
+	}
+}
+
+// WithIntervalStats makes the task account the intervals between its run
+// starts in the stats, which are also exposed by the metrics of
+// [WithMetrics]. See [utils.TrackIntervals].
+func WithIntervalStats(stats *utils.IntervalStats) option {
+	return func(o *options) {
+		o.intervals = stats
+	}
+}
+
+// WithHeartbeatFields sets the function, returning the custom fields of the
+// [NewHeartbeat] reports.
+func WithHeartbeatFields(f func() map[string]string) option {
+	return func(o *options) {
+		o.heartbeatFields = f
+	}
+}
+
+// WithHeartbeatTasks sets the function, returning the status of the tasks for
+// the [NewHeartbeat] reports, e.g. [Admin.Status].
+func WithHeartbeatTasks(f func() []TaskStatus) option {
+	return func(o *options) {
+		o.heartbeatTasks = f
+	}
+}
+
+// WithMetrics makes the task account its runs in the metrics under the task
+// name of [WithName]. See [Metrics.WriteMetrics].
+func WithMetrics(m *Metrics) option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// WithFailureNotifier makes the task notify the notifier in a separate
+// goroutine, when it is stopped by a task function error, wrapping
+// [utils.ErrStopped], other than [utils.ErrStopped] itself. The notification
+// context is done after [DefaultNotifyTimeout]. The task name of [WithName]
+// identifies the task in the [TaskFailure].
+func WithFailureNotifier(n Notifier) option {
+	return func(o *options) {
+		o.notifier = n
+	}
+}
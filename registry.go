@@ -0,0 +1,332 @@
+package goticks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+// ErrUnknownTask is returned by [BuildAll] for a configured task, which factory
+// is not registered.
+var ErrUnknownTask = errors.New("unknown task")
+
+// ErrUnknownTicker is returned by [BuildAll] for a configured ticker, which
+// factory is not registered.
+var ErrUnknownTicker = errors.New("unknown ticker")
+
+// Factory creates the task function for the configuration.
+type Factory func(TaskConfig) (func(context.Context, time.Time) error, error)
+
+// TickerFactory creates the ticker for the configuration, e.g. from its
+// Schedule. The ticker may implement [ticker.Stoppable] and
+// [ticker.Schedulable] to support the task stop and next run time.
+type TickerFactory func(TaskConfig) (ticker.Tickable[time.Time], error)
+
+// TaskConfig is the declarative configuration of a task, built by [BuildAll].
+//
+// The durations are encoded in JSON as strings, parsed by [time.ParseDuration].
+type TaskConfig struct {
+	// Name identifies the task in the [BuildAll] result.
+	Name string
+	// Task is the name of the registered factory. Defaults to Name.
+	Task string
+	// Every is the period of the default ticker.
+	Every time.Duration
+	// Ticker is the name of the registered ticker factory, see
+	// [RegisterTickerFactory]. Defaults to the [ticker.NewTimer] ticker with
+	// the Every period.
+	Ticker string
+	// Schedule is passed to the ticker factory, e.g. the cron spec for the
+	// "cron" ticker.
+	Schedule string
+	// Timeout limits every attempt, if positive.
+	Timeout time.Duration
+	// Attempts is the number of attempts with exponential backoff, if greater
+	// than one.
+	Attempts int
+	// Params are passed to the factory as is.
+	Params map[string]string
+	// Namespace is the [Admin] namespace of the task.
+	Namespace string
+	// Shutdown is the class of the task for [Admin.StopAllContext]. Defaults
+	// to [ShutdownBestEffort].
+	Shutdown ShutdownClass
+	// After names the tasks of the [Admin] namespace, configured before this
+	// one, which must complete their first successful run before the task is
+	// started. See [Admin.StartAll].
+	After []string
+	// Cooldown is the delay, after which the task, stopped by a failure and
+	// quarantined by [Admin], is requeued, if positive. See [Admin.Requeue].
+	Cooldown time.Duration
+	// LowPriority makes the runs delayed by the shaper of [WithShaper], and
+	// shed by the load shedder of [WithLoadShedding], given to [BuildAll] or
+	// [NewAdmin].
+	LowPriority bool
+}
+
+// ShutdownClass tells how [Admin.StopAllContext] stops a task.
+type ShutdownClass string
+
+const (
+	// ShutdownBestEffort tasks are stopped immediately, cancelling the runs
+	// in progress without waiting for them.
+	ShutdownBestEffort ShutdownClass = "best-effort"
+	// ShutdownNormal tasks are waited for to finish the runs in progress
+	// until the shutdown context is done, and are stopped then.
+	ShutdownNormal ShutdownClass = "normal"
+	// ShutdownCritical tasks are waited for to finish the runs in progress,
+	// however long they take.
+	ShutdownCritical ShutdownClass = "critical"
+)
+
+func parseShutdownClass(value string) (ShutdownClass, error) {
+	switch class := ShutdownClass(value); class {
+	case "", ShutdownBestEffort, ShutdownNormal, ShutdownCritical:
+		return class, nil
+	}
+	return "", fmt.Errorf("shutdown: unknown class %q", value)
+}
+
+type taskConfigJSON struct {
+	Name        string            `json:"name"`
+	Task        string            `json:"task,omitempty"`
+	Every       string            `json:"every,omitempty"`
+	Ticker      string            `json:"ticker,omitempty"`
+	Schedule    string            `json:"schedule,omitempty"`
+	Timeout     string            `json:"timeout,omitempty"`
+	Attempts    int               `json:"attempts,omitempty"`
+	Params      map[string]string `json:"params,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Shutdown    string            `json:"shutdown,omitempty"`
+	After       []string          `json:"after,omitempty"`
+	Cooldown    string            `json:"cooldown,omitempty"`
+	LowPriority bool              `json:"low_priority,omitempty"`
+}
+
+func parseDuration(field, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", field, err)
+	}
+	return d, nil
+}
+
+func (c *TaskConfig) UnmarshalJSON(data []byte) error {
+	return c.unmarshalJSON(data, false)
+}
+
+// unmarshalJSON decodes the configuration, reporting the unknown fields, e.g.
+// the misspelled ones, if strict.
+func (c *TaskConfig) unmarshalJSON(data []byte, strict bool) error {
+	var raw taskConfigJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+	every, err := parseDuration("every", raw.Every)
+	if err != nil {
+		return err
+	}
+	timeout, err := parseDuration("timeout", raw.Timeout)
+	if err != nil {
+		return err
+	}
+	shutdown, err := parseShutdownClass(raw.Shutdown)
+	if err != nil {
+		return err
+	}
+	cooldown, err := parseDuration("cooldown", raw.Cooldown)
+	if err != nil {
+		return err
+	}
+	*c = TaskConfig{
+		Name:        raw.Name,
+		Task:        raw.Task,
+		Every:       every,
+		Ticker:      raw.Ticker,
+		Schedule:    raw.Schedule,
+		Timeout:     timeout,
+		Attempts:    raw.Attempts,
+		Params:      raw.Params,
+		Namespace:   raw.Namespace,
+		Shutdown:    shutdown,
+		After:       raw.After,
+		Cooldown:    cooldown,
+		LowPriority: raw.LowPriority,
+	}
+	return nil
+}
+
+func (c TaskConfig) MarshalJSON() ([]byte, error) {
+	raw := taskConfigJSON{Name: c.Name, Task: c.Task, Ticker: c.Ticker, Schedule: c.Schedule, Attempts: c.Attempts, Params: c.Params, Namespace: c.Namespace, Shutdown: string(c.Shutdown), After: c.After, LowPriority: c.LowPriority}
+	if c.Every > 0 {
+		raw.Every = c.Every.String()
+	}
+	if c.Timeout > 0 {
+		raw.Timeout = c.Timeout.String()
+	}
+	if c.Cooldown > 0 {
+		raw.Cooldown = c.Cooldown.String()
+	}
+	return json.Marshal(raw)
+}
+
+var registry = struct {
+	sync.Mutex
+	factories map[string]Factory
+	tickers   map[string]TickerFactory
+}{
+	factories: map[string]Factory{},
+	tickers: map[string]TickerFactory{
+		"cron": func(c TaskConfig) (ticker.Tickable[time.Time], error) {
+			schedule, err := ticker.ParseCron(c.Schedule)
+			if err != nil {
+				return nil, err
+			}
+			return ticker.FromSchedule(schedule), nil
+		},
+	},
+}
+
+// Register makes the factory available to [BuildAll] by the name. It is meant
+// to be called from the init functions, and panics if the name is registered
+// twice.
+func Register(name string, factory Factory) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, exists := registry.factories[name]; exists {
+		panic("goticks: task " + name + " is registered twice")
+	}
+	registry.factories[name] = factory
+}
+
+// RegisterTickerFactory makes the ticker factory available to [BuildAll] by
+// the name, referenced by [TaskConfig] Ticker, so that the third-party
+// schedules can be configured. The "cron" ticker, ticking on the cron spec of
+// the Schedule, see [ticker.ParseCron], is registered by default. It is meant
+// to be called from the init functions, and panics if the name is registered
+// twice.
+func RegisterTickerFactory(name string, factory TickerFactory) {
+	registry.Lock()
+	defer registry.Unlock()
+	if _, exists := registry.tickers[name]; exists {
+		panic("goticks: ticker " + name + " is registered twice")
+	}
+	registry.tickers[name] = factory
+}
+
+// LoadConfig decodes the JSON list of task configurations. The unknown fields
+// of the configurations are reported as errors.
+//
+// Example:
+//
+//	[{"name": "cleanup", "every": "1h", "timeout": "5m", "attempts": 3},
+//	 {"name": "report", "ticker": "cron", "schedule": "0 9 * * mon"}]
+func LoadConfig(r io.Reader) ([]TaskConfig, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode the task configuration: %w", err)
+	}
+	cfg := make([]TaskConfig, len(raw))
+	for i, data := range raw {
+		if err := cfg[i].unmarshalJSON(data, true); err != nil {
+			return nil, fmt.Errorf("failed to decode the task configuration %d: %w", i, err)
+		}
+	}
+	return cfg, nil
+}
+
+// BuildAll creates the tasks from the configurations with the registered
+// factories. The options are applied to every task, followed by [WithName],
+// [WithTimeout] and [WithRetry] according to the configuration. The tasks are
+// not started.
+func BuildAll(cfg []TaskConfig, opts ...option) (map[string]RestartableWithTicker[time.Time], error) {
+	tasks := make(map[string]RestartableWithTicker[time.Time], len(cfg))
+	for _, c := range cfg {
+		if _, exists := tasks[c.Name]; exists {
+			return nil, fmt.Errorf("task %q is configured twice", c.Name)
+		}
+		fn, err := build(c)
+		if err != nil {
+			return nil, fmt.Errorf("task %q: %w", c.Name, err)
+		}
+		tick, err := buildTicker(c)
+		if err != nil {
+			return nil, fmt.Errorf("task %q: %w", c.Name, err)
+		}
+		tasks[c.Name] = NewTask(tick, fn, append(slices.Clone(opts), configOptions(c)...)...)
+	}
+	return tasks, nil
+}
+
+func build(c TaskConfig) (func(context.Context, time.Time) error, error) {
+	if c.Name == "" {
+		return nil, errors.New("no name")
+	}
+	name := c.Task
+	if name == "" {
+		name = c.Name
+	}
+	registry.Lock()
+	factory, ok := registry.factories[name]
+	registry.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTask, name)
+	}
+	return factory(c)
+}
+
+// configOptions returns the options of the task configuration, which take
+// precedence over the options, given to [BuildAll].
+func configOptions(c TaskConfig) []option {
+	opts := []option{WithName(c.Name)}
+	if c.Timeout > 0 {
+		opts = append(opts, WithTimeout(c.Timeout))
+	}
+	if c.Attempts > 1 {
+		opts = append(opts, WithRetry(retryPolicy(c)))
+	}
+	if c.LowPriority {
+		opts = append(opts, withLowPriority())
+	}
+	return opts
+}
+
+// retryPolicy returns the retry policy of the configured attempts, or nil.
+func retryPolicy(c TaskConfig) utils.RetryPolicy {
+	if c.Attempts > 1 {
+		return utils.ExponentialBackoffPolicy(c.Attempts, time.Second)
+	}
+	return nil
+}
+
+func buildTicker(c TaskConfig) (ticker.Tickable[time.Time], error) {
+	if c.Ticker == "" {
+		if c.Every <= 0 {
+			return nil, errors.New("non-positive period")
+		}
+		return ticker.NewTimer(c.Every), nil
+	}
+	registry.Lock()
+	factory, ok := registry.tickers[c.Ticker]
+	registry.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTicker, c.Ticker)
+	}
+	return factory(c)
+}
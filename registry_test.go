@@ -0,0 +1,142 @@
+package goticks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/ticker"
+)
+
+// registerForTest registers or replaces the factory.
+func registerForTest(name string, factory Factory) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.factories[name] = factory
+}
+
+func TestRegister(t *testing.T) {
+	Register("test-register", nil)
+	defer func() {
+		assert.That(t, assert.Not(assert.Equal(nil, recover())))
+		registry.Lock()
+		delete(registry.factories, "test-register")
+		registry.Unlock()
+	}()
+	Register("test-register", nil)
+}
+
+func TestBuildAll(t *testing.T) {
+	calls := make(chan string, 1)
+	registerForTest("test-echo", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		if c.Params["fail"] != "" {
+			return nil, errors.New(c.Params["fail"])
+		}
+		return func(ctx context.Context, _ time.Time) error {
+			_, hasDeadline := ctx.Deadline()
+			if hasDeadline {
+				calls <- c.Name + " with timeout"
+			} else {
+				calls <- c.Name
+			}
+			return nil
+		}, nil
+	})
+
+	t.Run("load and build", func(t *testing.T) {
+		cfg, err := LoadConfig(strings.NewReader(`[
+			{"name": "test-echo", "every": "1h", "timeout": "1m", "attempts": 3},
+			{"name": "another", "task": "test-echo", "every": "2h"}
+		]`))
+		assert.That(t, assert.NoError(err))
+		assert.That(t,
+			assert.Equal(2, len(cfg)),
+			assert.Equal(time.Hour, cfg[0].Every),
+			assert.Equal(time.Minute, cfg[0].Timeout),
+			assert.Equal(3, cfg[0].Attempts),
+			assert.Equal("test-echo", cfg[1].Task))
+
+		tasks, err := BuildAll(cfg, WithTickerStop())
+		assert.That(t, assert.NoError(err), assert.Equal(2, len(tasks)))
+		setup := tasks["test-echo"].Config()
+		assert.That(t,
+			assert.Equal("test-echo", setup.Name),
+			assert.Equal("every 1h0m0s", setup.Schedule),
+			assert.Equal(time.Minute, setup.Timeout),
+			assert.Equal("utils.ExponentialBackoffPolicy", setup.Retry),
+			assert.EqualSlices([]string{"Retry", "Timeout"}, setup.Wrappers))
+
+		tasks["test-echo"].Start()
+		assert.That(t, assert.Equal("test-echo with timeout", <-calls))
+		tasks["test-echo"].Stop()
+
+		tasks["another"].Start()
+		assert.That(t, assert.Equal("another", <-calls))
+		tasks["another"].Stop()
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := LoadConfig(strings.NewReader(`[{"name": "x", "every": "often"}]`))
+		assert.That(t, assert.Not(assert.NoError(err)))
+
+		_, err = LoadConfig(strings.NewReader(`[{"name": "x", "every": "1h"}, {"name": "y", "evrey": "1h"}]`))
+		assert.That(t, assert.Equal(`failed to decode the task configuration 1: json: unknown field "evrey"`, err.Error()))
+
+		_, err = BuildAll([]TaskConfig{{Name: "unknown", Every: time.Hour}})
+		assert.That(t, assert.ErrorIs(err, ErrUnknownTask))
+
+		_, err = BuildAll([]TaskConfig{{Name: "test-echo"}})
+		assert.That(t, assert.Not(assert.NoError(err)))
+
+		_, err = BuildAll([]TaskConfig{
+			{Name: "test-echo", Every: time.Hour},
+			{Name: "test-echo", Every: time.Hour},
+		})
+		assert.That(t, assert.Not(assert.NoError(err)))
+
+		_, err = BuildAll([]TaskConfig{{Name: "test-echo", Every: time.Hour, Params: map[string]string{"fail": "bad"}}})
+		assert.That(t, assert.Equal(`task "test-echo": bad`, err.Error()))
+
+		_, err = BuildAll([]TaskConfig{{Name: "test-echo", Ticker: "unknown"}})
+		assert.That(t, assert.ErrorIs(err, ErrUnknownTicker))
+
+		_, err = BuildAll([]TaskConfig{{Name: "test-echo", Ticker: "cron", Schedule: "every day"}})
+		assert.That(t, assert.ErrorIs(err, ticker.ErrInvalidCron))
+	})
+
+	t.Run("ticker factory", func(t *testing.T) {
+		RegisterTickerFactory("test-once", func(c TaskConfig) (ticker.Tickable[time.Time], error) {
+			return ticker.NewTimerTicker(0), nil
+		})
+		defer func() {
+			assert.That(t, assert.Not(assert.Equal(nil, recover())))
+			registry.Lock()
+			delete(registry.tickers, "test-once")
+			registry.Unlock()
+		}()
+		cfg, err := LoadConfig(strings.NewReader(`[
+			{"name": "test-echo", "ticker": "test-once"},
+			{"name": "weekly", "task": "test-echo", "ticker": "cron", "schedule": "@weekly"}
+		]`))
+		assert.That(t, assert.NoError(err))
+		tasks, err := BuildAll(cfg)
+		assert.That(t, assert.NoError(err))
+		tasks["test-echo"].Start()
+		assert.That(t, assert.Equal("test-echo", <-calls))
+
+		tasks["weekly"].Start()
+		assert.That(t, assert.Equal(time.Sunday, tasks["weekly"].NextRun().Weekday()))
+		tasks["weekly"].Stop()
+
+		data, err := json.Marshal(cfg[1])
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal(`{"name":"weekly","task":"test-echo","ticker":"cron","schedule":"@weekly"}`, string(data)))
+
+		RegisterTickerFactory("test-once", nil)
+	})
+}
@@ -0,0 +1,91 @@
+package goticks
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TaskSetup describes how the task is set up, e.g. for the admin output or to
+// verify the task options in the tests.
+type TaskSetup struct {
+	// Name is the name, given with [WithName].
+	Name string
+	// Schedule describes the ticker, e.g. "every 1m0s", if it implements
+	// [fmt.Stringer].
+	Schedule string
+	// Timeout is the run attempt timeout of [WithTimeout].
+	Timeout time.Duration
+	// Retry is the name of the function, which has created the retry policy of
+	// [WithRetry], e.g. "utils.SimpleRetryPolicy".
+	Retry string
+	// Wrappers are the names of the [utils] wrappers, applied to the task
+	// function according to the options, from the outermost.
+	Wrappers []string
+}
+
+type taskSetupJSON struct {
+	Name     string   `json:"name,omitempty"`
+	Schedule string   `json:"schedule,omitempty"`
+	Timeout  string   `json:"timeout,omitempty"`
+	Retry    string   `json:"retry,omitempty"`
+	Wrappers []string `json:"wrappers,omitempty"`
+}
+
+func (s *TaskSetup) UnmarshalJSON(data []byte) error {
+	var raw taskSetupJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	timeout, err := parseDuration("timeout", raw.Timeout)
+	if err != nil {
+		return err
+	}
+	*s = TaskSetup{raw.Name, raw.Schedule, timeout, raw.Retry, raw.Wrappers}
+	return nil
+}
+
+func (s TaskSetup) MarshalJSON() ([]byte, error) {
+	raw := taskSetupJSON{Name: s.Name, Schedule: s.Schedule, Retry: s.Retry, Wrappers: s.Wrappers}
+	if s.Timeout > 0 {
+		raw.Timeout = s.Timeout.String()
+	}
+	return json.Marshal(raw)
+}
+
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$`)
+
+// funcName returns the package qualified name of the function, or of the
+// function, which has created the closure.
+func funcName(f any) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return ""
+	}
+	name := closureSuffix.ReplaceAllString(fn.Name(), "")
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// Config returns the description of the task setup.
+func (t *taskImpl[TickType]) Config() TaskSetup {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	setup := TaskSetup{
+		Name:     t.options.name,
+		Timeout:  t.timeout,
+		Wrappers: slices.Clone(t.wrappers),
+	}
+	slices.Reverse(setup.Wrappers)
+	if s, ok := t.ticker.(fmt.Stringer); ok {
+		setup.Schedule = s.String()
+	}
+	if t.options.retry != nil {
+		setup.Retry = funcName(t.options.retry)
+	}
+	return setup
+}
@@ -0,0 +1,44 @@
+package goticks
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// StartSpread starts the tasks over the window instead of all at once, to
+// avoid the startup stampede of many tasks, which tickers tick on start.
+// The start offsets are spread evenly, with the first task started
+// immediately, or are random if random is true.
+// Calling the returned cancel function prevents the pending starts. See
+// [WithStartSpread] for the tasks of [Admin].
+//
+// Example:
+//
+//	cancel := StartSpread(time.Minute, false, tasks...)
+//	defer cancel()
+func StartSpread(window time.Duration, random bool, tasks ...Task) (cancel func()) {
+	timers := make([]*time.Timer, 0, len(tasks))
+	for i, task := range tasks {
+		offset := spreadOffset(window, random, i, len(tasks))
+		timers = append(timers, time.AfterFunc(timescale.Scale(offset), task.Start))
+	}
+	return func() {
+		for _, timer := range timers {
+			timer.Stop()
+		}
+	}
+}
+
+// spreadOffset returns the start offset of the i-th of n tasks, spread over
+// the window.
+func spreadOffset(window time.Duration, random bool, i, n int) time.Duration {
+	switch {
+	case window <= 0:
+		return 0
+	case random:
+		return rand.N(window)
+	}
+	return window * time.Duration(i) / time.Duration(n)
+}
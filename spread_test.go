@@ -0,0 +1,70 @@
+package goticks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+type startRecorder chan time.Time
+
+func (r startRecorder) Start() { r <- time.Now() }
+func (r startRecorder) Stop()  {}
+
+func TestStartSpread(t *testing.T) {
+	t.Run("even", func(t *testing.T) {
+		a, b := make(startRecorder, 1), make(startRecorder, 1)
+		start := time.Now()
+		cancel := StartSpread(100*time.Millisecond, false, a, b)
+		defer cancel()
+		assert.That(t,
+			assert.True((<-a).Sub(start) < 50*time.Millisecond),
+			assert.True((<-b).Sub(start) >= 50*time.Millisecond))
+	})
+
+	t.Run("random", func(t *testing.T) {
+		a := make(startRecorder, 1)
+		start := time.Now()
+		cancel := StartSpread(10*time.Millisecond, true, a)
+		defer cancel()
+		assert.That(t, assert.True((<-a).Sub(start) < time.Second))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		a, b := make(startRecorder, 1), make(startRecorder, 1)
+		cancel := StartSpread(time.Hour, false, a, b)
+		<-a
+		cancel()
+		assert.That(t, assert.Equal(0, len(b)))
+	})
+}
+
+func TestAdmin_StartSpread(t *testing.T) {
+	started := map[string]chan time.Time{"a": make(chan time.Time, 1), "b": make(chan time.Time, 1)}
+	registerForTest("test-spread", func(c TaskConfig) (func(context.Context, time.Time) error, error) {
+		return func(context.Context, time.Time) error {
+			started[c.Name] <- time.Now()
+			return nil
+		}, nil
+	})
+	start := time.Now()
+	admin, err := NewAdmin([]TaskConfig{
+		{Name: "a", Task: "test-spread", Every: time.Hour},
+		{Name: "b", Task: "test-spread", Every: time.Hour}},
+		nil, WithStartSpread(100*time.Millisecond, false), WithTickerStop())
+	assert.That(t, assert.NoError(err))
+	defer admin.StopAll()
+	assert.That(t,
+		assert.True((<-started["a"]).Sub(start) < 50*time.Millisecond),
+		assert.True((<-started["b"]).Sub(start) >= 50*time.Millisecond))
+
+	admin.StopAll()
+	assert.That(t, assert.NoError(admin.StartAll()))
+	<-started["a"]
+	admin.StopAll()
+	time.Sleep(100 * time.Millisecond)
+	// The pending start of b is abandoned.
+	assert.That(t, assert.Equal(0, len(started["b"])))
+}
@@ -0,0 +1,51 @@
+package goticks
+
+// state is the lifecycle state of a task.
+//
+// The transitions are:
+//
+//	stopped  -> starting -> running  (Start)
+//	paused   -> starting -> running  (Start)
+//	starting -> stopped | paused     (Start, cancelled by the onStart callback)
+//	running  -> stopping -> stopped  (Stop with the ticker stop, or the loop exit)
+//	running  -> stopping -> paused   (Stop)
+//	paused   -> stopped              (the loop exit)
+type state int32
+
+const (
+	// stateStopped: no loop is running.
+	stateStopped state = iota
+	// stateStarting: the onStart callback is being called.
+	stateStarting
+	// stateRunning: the loop executes the task on ticks.
+	stateRunning
+	// stateStopping: the onStop callback is being called.
+	stateStopping
+	// statePaused: the loop is running, but the task is not executed.
+	statePaused
+)
+
+// allowedTransitions lists the states, allowed after every state.
+var allowedTransitions = map[state][]state{
+	stateStopped:  {stateStarting},
+	stateStarting: {stateRunning, stateStopped, statePaused},
+	stateRunning:  {stateStopping},
+	stateStopping: {stateStopped, statePaused},
+	statePaused:   {stateStarting, stateStopped},
+}
+
+func (s state) String() string {
+	switch s {
+	case stateStopped:
+		return "stopped"
+	case stateStarting:
+		return "starting"
+	case stateRunning:
+		return "running"
+	case stateStopping:
+		return "stopping"
+	case statePaused:
+		return "paused"
+	}
+	return "unknown"
+}
@@ -0,0 +1,94 @@
+package goticks
+
+import (
+	"math/rand/v2"
+	"sync"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/ticker"
+	"github.com/parametalol/goticks/utils"
+)
+
+func TestTask_transitions(t *testing.T) {
+	t.Run("self stop and restart", func(t *testing.T) {
+		ticker := ticker.New[int]()
+		hooks := &DebugHooks{}
+		var ticks []int
+		stops := 0
+		task := NewTask(ticker, func(tick int) error {
+			ticks = append(ticks, tick)
+			if tick == 2 {
+				return utils.ErrStopped
+			}
+			return nil
+		}, WithOnStop(func() { stops++ }), WithDebugHooks(hooks))
+
+		task.Start()
+		for tick := range 4 {
+			ticker.Tick(tick).Wait()
+		}
+		task.Stop()
+		task.Start()
+		ticker.Tick(4).Wait()
+		task.Stop()
+
+		assert.That(t, assert.NoError(hooks.Check()))
+		assert.That(t,
+			assert.EqualSlices([]int{0, 1, 2, 4}, ticks),
+			assert.Equal(2, stops))
+	})
+
+	for _, stopTicker := range []bool{false, true} {
+		name := "stress"
+		opts := []option{}
+		if stopTicker {
+			name += " WithTickerStop"
+			opts = append(opts, WithTickerStop())
+		}
+		t.Run(name, func(t *testing.T) {
+			iterations := 1000
+			if testing.Short() {
+				iterations = 100
+			}
+			ticker := ticker.New[int]()
+			hooks := &DebugHooks{}
+			var mux sync.Mutex
+			starts, stops := 0, 0
+			task := NewTask(ticker, func(tick int) error {
+				if tick%7 == 0 {
+					return utils.ErrStopped
+				}
+				return nil
+			}, append(opts,
+				WithOnStart(func() error { mux.Lock(); starts++; mux.Unlock(); return nil }),
+				WithOnStop(func() { mux.Lock(); stops++; mux.Unlock() }),
+				WithDebugHooks(hooks))...)
+
+			var wg sync.WaitGroup
+			for worker := range 4 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range iterations {
+						switch rand.IntN(3) {
+						case 0:
+							task.Start()
+						case 1:
+							task.Stop()
+						default:
+							ticker.Tick(worker*iterations + i).Wait()
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			task.Stop()
+
+			assert.That(t, assert.NoError(hooks.Check()))
+			mux.Lock()
+			defer mux.Unlock()
+			assert.That(t, assert.Equal(starts, stops))
+		})
+	}
+}
@@ -3,13 +3,37 @@ package goticks
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/parametalol/goticks/internal/timescale"
 	"github.com/parametalol/goticks/loop"
 	"github.com/parametalol/goticks/ticker"
 	"github.com/parametalol/goticks/utils"
 )
 
+// ErrNotStarted is returned by the task Error method before the first start.
+var ErrNotStarted = errors.New("not started")
+
+// ErrNotRunning is returned by the task TriggerNow method, if the task is not
+// running.
+var ErrNotRunning = errors.New("not running")
+
+// ErrNeedsReset is returned by the task StartE method, if the task has been
+// stopped by a failure, and has not been reset since.
+var ErrNeedsReset = errors.New("needs reset")
+
+// ErrNotPeriodic is returned by the task SetPeriod method, if the ticker has
+// no period to change.
+var ErrNotPeriodic = errors.New("not a periodic ticker")
+
+// ErrOptionType is returned by the task Reload method for an option of another
+// tick type, e.g. of [WithLoopMiddleware] or [WithIdempotencyKey].
+var ErrOptionType = errors.New("option does not match the tick type")
+
 type Task interface {
 	Start()
 	Stop()
@@ -18,18 +42,71 @@ type Task interface {
 type taskImpl[TickType any] struct {
 	ticker ticker.Tickable[TickType]
 	task   func(context.Context, TickType) error
+	// fn is the adapted task function, and run is fn wrapped according to the
+	// options.
+	fn  func(context.Context, TickType) error
+	run atomic.Pointer[func(context.Context, TickType) error]
+	// observer is the loop observer of the options.
+	observer atomic.Pointer[loop.Observer]
+	// parent is the parent context of the options.
+	parent atomic.Pointer[context.Context]
+	// sequence is the tick sequence of the options.
+	sequence atomic.Pointer[utils.TickSequence]
+	// loopFn runs the loop, wrapped with the loop middleware of the options.
+	loopFn loop.LoopFunc[TickType]
+	// timed is fn wrapped with the timeout and the auto timeout of the
+	// multiplier, kept over reloads to preserve the learnt durations.
+	timed      func(context.Context, TickType) error
+	timeout    time.Duration
+	multiplier float64
+	// wrappers are the names of the wrappers of run, from the innermost.
+	wrappers []string
 
 	options options
 
-	once    atomic.Bool
+	// mux serializes the lifecycle transitions.
+	mux   sync.Mutex
+	state atomic.Int32
+	// loop is the generation of the running loop, or 0 if there is none.
+	loop  uint64
+	loops uint64
+	// ctx is cancelled when the task stops, and is replaced for the next run
+	// cycle.
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	// cycle is ctx, readable without the lock.
+	cycle atomic.Pointer[context.Context]
+	// err is the cause of the last stop, nil while running.
+	err error
+	// started is set on start, and reset by the first run after it.
 	started atomic.Bool
+	// inflight is the number of the runs in progress, and softStop requests
+	// the task stop when it drops to zero.
+	inflight atomic.Int32
+	softStop atomic.Bool
+	// idle is closed when inflight drops to zero, and is replaced when it
+	// grows from zero, under idleMux.
+	idleMux sync.Mutex
+	idle    chan struct{}
 }
 
 var _ Task = (*taskImpl[any])(nil)
 
 type RestartableWithTicker[TickType any] interface {
 	ticker.Restartable
+	StartE() error
+	Reset()
 	Ticker() ticker.Tickable[TickType]
+	Reload(opts ...option) error
+	StopAfterCurrentRun()
+	WaitContext(ctx context.Context) error
+	WaitTimeout(d time.Duration) error
+	OnStop(f func(cause error)) (stop func() bool)
+	Error() error
+	NextRun() time.Time
+	Config() TaskSetup
+	TriggerNow(ctx context.Context, wait bool) error
+	SetPeriod(d time.Duration) error
 }
 
 // NewTask returns an instance of a restartable task, executed on the ticker
@@ -43,57 +120,712 @@ type RestartableWithTicker[TickType any] interface {
 // started on [Start], but the previously stopped consumers, except the current
 // task, will not restart.
 //
+// If the task function returns [utils.ErrStopped], the task stops as if [Stop]
+// was called, and can be started again. If the error only wraps it, the task
+// stops with the failure, and is not started again until it is reset.
+//
+// The onStart and onStop callbacks must not call [Start] or [Stop].
+//
 // Example:
 //
 //	NewTask(ticker.NewTimer(time.Second), task).Start() // run task every second
 func NewTask[TickType any, Fn utils.Func[TickType]](ticker ticker.Tickable[TickType], fn Fn, opts ...option) RestartableWithTicker[TickType] {
 	task := &taskImpl[TickType]{
 		ticker: ticker,
+		fn:     utils.Adapt[TickType](fn),
+		err:    ErrNotStarted,
 	}
+	task.ctx, task.cancel = context.WithCancelCause(context.Background())
+	ctx := task.ctx
+	task.cycle.Store(&ctx)
 	for _, opt := range opts {
 		opt(&task.options)
 	}
-	adaptedTask := utils.Adapt[TickType](fn)
+	if err := checkOptions[TickType](&task.options); err != nil {
+		panic("goticks: " + err.Error())
+	}
+	task.wrap()
 	task.task = func(ctx context.Context, tick TickType) error {
-		if !task.started.Load() {
-			return nil
+		if parent := task.parent.Load(); parent != nil {
+			ctx = valuesContext{ctx, *parent}
+		}
+		if sequence := task.sequence.Load(); sequence != nil {
+			return utils.Sequence[TickType](sequence, task.receive)(ctx, tick)
 		}
-		return adaptedTask(ctx, tick)
+		return task.receive(ctx, tick)
 	}
 	return task
 }
 
-// Start the task execution loop, once.
+// valuesContext is the run context, which also provides the values of the
+// parent context of [WithParentContext].
+type valuesContext struct {
+	context.Context
+	parent context.Context
+}
+
+func (c valuesContext) Value(key any) any {
+	if value := c.Context.Value(key); value != nil {
+		return value
+	}
+	return c.parent.Value(key)
+}
+
+// SkipReasonPaused is the reason of the ticks, dropped by a stopped task, which
+// ticker is not stopped. See [WithTickSequence].
+const SkipReasonPaused = "paused"
+
+// receive runs the task on the tick, received by the loop, unless the task is
+// stopped.
+func (t *taskImpl[TickType]) receive(ctx context.Context, tick TickType) error {
+	observer := t.observer.Load()
+	observer.ReportTickReceived()
+	// The cycle is loaded before the state check, so that it is the current
+	// one, or the cancelled previous one.
+	cycle := t.cycle.Load()
+	t.runStarted()
+	defer t.runFinished()
+	if t.getState() != stateRunning {
+		observer.ReportTickDropped()
+		utils.Skip(ctx, SkipReasonPaused)
+		return nil
+	}
+	// The first scheduled run after the start is caused by the start.
+	if utils.RunCauseFromContext(ctx) == utils.RunCauseTick && t.started.Swap(false) {
+		ctx = utils.WithRunCause(ctx, utils.RunCauseStart)
+	}
+	// The run is cancelled by the task stop, as the TriggerNow runs are.
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stop := context.AfterFunc(*cycle, func() { cancel(context.Cause(*cycle)) })
+	defer stop()
+	observer.ReportRunStarted()
+	ctx = utils.WithBackground(ctx, *cycle, t.backgroundStarted)
+	err := (*t.run.Load())(ctx, tick)
+	observer.ReportRunFinished(err)
+	return err
+}
+
+// checkOptions returns an error, wrapping [ErrOptionType], if an option of a
+// tick type does not match the task tick type.
+func checkOptions[TickType any](o *options) error {
+	if o.idempotencyKey != nil {
+		if _, ok := o.idempotencyKey.(func(TickType) string); !ok {
+			return fmt.Errorf("idempotency key function %T: %w", o.idempotencyKey, ErrOptionType)
+		}
+	}
+	if o.loopMiddleware != nil {
+		if _, ok := o.loopMiddleware.(func(loop.LoopFunc[TickType]) loop.LoopFunc[TickType]); !ok {
+			return fmt.Errorf("loop middleware %T: %w", o.loopMiddleware, ErrOptionType)
+		}
+	}
+	return nil
+}
+
+// wrap builds the executed function from the task function and the options,
+// checked by checkOptions.
+func (t *taskImpl[TickType]) wrap() {
+	if t.timed == nil || t.timeout != t.options.timeout || t.multiplier != t.options.autoTimeout {
+		t.timeout = t.options.timeout
+		t.multiplier = t.options.autoTimeout
+		t.timed = t.fn
+		if t.timeout > 0 {
+			t.timed = utils.Timeout[TickType](t.timeout, t.timed)
+		}
+		if t.multiplier > 0 {
+			t.timed = utils.AutoTimeout[TickType](t.multiplier, t.timed)
+		}
+	}
+	run := t.timed
+	var wrappers []string
+	if t.timeout > 0 {
+		wrappers = append(wrappers, "Timeout")
+	}
+	if t.multiplier > 0 {
+		wrappers = append(wrappers, "AutoTimeout")
+	}
+	if t.options.inFlight {
+		wrappers = append(wrappers, "TrackInFlight")
+		run = utils.TrackInFlight[TickType](t.options.name, run)
+	}
+	if t.options.runLogs != nil {
+		wrappers = append(wrappers, "CaptureRunLog")
+		run = utils.CaptureRunLog[TickType](t.options.runLogs, t.options.runLogHandler, run)
+	}
+	if t.options.logOut != nil || t.options.logErr != nil {
+		wrappers = append(wrappers, "Log")
+		run = utils.Log[TickType](orDiscard(t.options.logOut), orDiscard(t.options.logErr), t.options.name, run)
+	}
+	if t.options.retry != nil {
+		wrappers = append(wrappers, "Retry")
+		run = utils.Retry[TickType](t.options.retry, run)
+	}
+	if t.options.idleRuns > 0 && t.options.isNoOp != nil {
+		wrappers = append(wrappers, "IdleStop")
+		run = utils.IdleStop[TickType](t.options.idleRuns, t.options.isNoOp, run)
+	}
+	if key, ok := t.options.idempotencyKey.(func(TickType) string); ok {
+		wrappers = append(wrappers, "Idempotent")
+		run = utils.Idempotent[TickType](key, t.options.keyStore, run)
+	}
+	if t.options.failures != nil {
+		wrappers = append(wrappers, "TrackFailures")
+		run = utils.TrackFailures[TickType](t.options.failures, run)
+	}
+	if t.options.shaper != nil {
+		wrappers = append(wrappers, "Shape")
+		run = utils.Shape[TickType](t.options.shaper, t.options.lowPriority, run)
+	}
+	if t.options.pool != nil {
+		wrappers = append(wrappers, "InPool")
+		run = utils.InPool[TickType](t.options.pool, run)
+	}
+	if t.options.minGap > 0 {
+		wrappers = append(wrappers, "MinGap")
+		run = utils.MinGap[TickType](t.options.minGap, run)
+	}
+	if t.options.healthProbe != nil {
+		wrappers = append(wrappers, "HealthGate")
+		run = utils.HealthGate[TickType](t.options.healthProbe, t.options.healthPolicy, run)
+	}
+	if t.options.startGate != nil {
+		wrappers = append(wrappers, "StartGate")
+		run = utils.StartGate[TickType](t.options.startGate, t.options.startPolicy, run)
+	}
+	if t.options.loadShedder != nil {
+		wrappers = append(wrappers, "LoadShedding")
+		run = utils.LoadShedding[TickType](t.options.loadShedder, t.options.loadLowPri, t.options.maxLoadDefer, run)
+	}
+	if t.options.window != nil {
+		wrappers = append(wrappers, "InWindow")
+		run = utils.InWindow[TickType](*t.options.window, t.options.windowPolicy, run)
+	}
+	if t.options.journal != nil {
+		wrappers = append(wrappers, "Journaled")
+		run = utils.Journaled[TickType](t.options.journal, t.options.name, run)
+	}
+	if t.options.metrics != nil {
+		tm := t.options.metrics.task(t.options.name, func() bool {
+			return t.getState() == stateRunning
+		}, t.NextRun, t.options.intervals)
+		wrappers = append(wrappers, "Metrics")
+		run = measure(t.options.metrics, tm, t.options.onRun, run)
+	} else {
+		if t.options.intervals != nil {
+			wrappers = append(wrappers, "TrackIntervals")
+			run = utils.TrackIntervals[TickType](t.options.intervals, run)
+		}
+		if t.options.onRun != nil {
+			wrappers = append(wrappers, "Classify")
+			run = utils.Classify[TickType](t.options.onRun, run)
+		}
+	}
+	if t.options.onSuccess != nil {
+		onSuccess := t.options.onSuccess
+		wrappers = append(wrappers, "Classify")
+		run = utils.Classify[TickType](func(result utils.RunResult) {
+			if result.Outcome == utils.RunExecuted {
+				onSuccess()
+			}
+		}, run)
+	}
+	if t.options.isFatal != nil {
+		wrappers = append(wrappers, "StopOn")
+		run = utils.StopOn[TickType](t.options.isFatal, run)
+	}
+	if t.options.reportSkips {
+		wrappers = append(wrappers, "ReportSkips")
+		run = utils.ReportSkips[TickType](run)
+	}
+	t.run.Store(&run)
+	t.wrappers = wrappers
+	t.observer.Store(t.options.observer)
+	t.sequence.Store(t.options.sequence)
+	if t.options.parent != nil {
+		t.parent.Store(&t.options.parent)
+	} else {
+		t.parent.Store(nil)
+	}
+	t.loopFn = loop.OnTick[TickType]
+	if middleware, ok := t.options.loopMiddleware.(func(loop.LoopFunc[TickType]) loop.LoopFunc[TickType]); ok {
+		t.loopFn = middleware(t.loopFn)
+	}
+}
+
+func orDiscard(w io.Writer) io.Writer {
+	if w == nil {
+		return io.Discard
+	}
+	return w
+}
+
+// NewTaskFromTicks returns a task, executed on the ticks received from the
+// channel. See [ticker.FromChan] and [NewTask].
+//
+// Example:
+//
+//	NewTaskFromTicks(time.Tick(time.Second), task).Start()
+func NewTaskFromTicks[TickType any, Fn utils.Func[TickType]](ticks <-chan TickType, fn Fn, opts ...option) RestartableWithTicker[TickType] {
+	return NewTask(ticker.FromChan(ticks), fn, opts...)
+}
+
+func (t *taskImpl[TickType]) getState() state {
+	return state(t.state.Load())
+}
+
+// transition changes the task state. Must be called under the lock.
+func (t *taskImpl[TickType]) transition(to state) {
+	from := state(t.state.Swap(int32(to)))
+	if t.options.onTransition != nil {
+		t.options.onTransition(from, to)
+	}
+}
+
+// Start the task execution. The ticks loop is started if it is not running.
+// The task, stopped by a failure, is not started, see StartE.
 func (t *taskImpl[TickType]) Start() {
-	if t.started.Swap(true) {
-		return
+	_ = t.StartE()
+}
+
+// StartE starts the task as Start does, and returns an error, wrapping
+// [ErrNeedsReset] and the failure, if the task has been stopped by a failure
+// and has not been reset since, or the error of the [WithOnStart] callback,
+// which has cancelled the start.
+func (t *taskImpl[TickType]) StartE() error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	from := t.getState()
+	// The task is being stopped, if the final run has released the lock.
+	if from == stateRunning || from == stateStopping {
+		return nil
 	}
-	if t.options.onStart != nil && errors.Is(t.options.onStart(), utils.ErrStopped) {
-		t.started.Store(false)
-		return
+	if err := failure(t.err); err != nil {
+		return fmt.Errorf("%w: %w", ErrNeedsReset, err)
 	}
-	if !t.once.Swap(true) {
+	t.transition(stateStarting)
+	if t.options.onStart != nil {
+		if err := t.options.onStart(); errors.Is(err, utils.ErrStopped) {
+			t.transition(from)
+			return err
+		}
+	}
+	t.started.Store(true)
+	t.softStop.Store(false)
+	t.transition(stateRunning)
+	t.err = nil
+	if t.loop == 0 {
+		t.loops++
+		t.loop = t.loops
+		generation := t.loop
 		ticks := t.ticker.Ticks()
+		loopFn := t.loopFn
 		go func() {
-			_ = loop.OnTick(ticks, t.task)
+			err := loopFn(ticks, t.task)
+			reason := loop.ExitTicksEnded
+			var exit *loop.LoopExitError
+			if errors.As(err, &exit) {
+				reason, err = exit.Reason, exit.Err
+			}
+			// The task reports its own errors, not the loop runs.
+			var runErr *loop.RunError[TickType]
+			if errors.As(err, &runErr) {
+				err = runErr.Err
+			}
+			t.observer.Load().ReportLoopExited(reason, err)
+			t.loopExited(generation, err)
 		}()
 	}
+	return nil
 }
 
-// Stop all running loops by stopping the ticker.
+// Stop the task execution, and the ticker if [WithTickerStop] is provided. The
+// context of the run in progress, if any, is cancelled with
+// [utils.ErrStopped], see [StopAfterCurrentRun] to let the run finish.
 func (t *taskImpl[TickType]) Stop() {
-	if !t.started.Swap(false) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.stop()
+}
+
+// StopAfterCurrentRun stops the task as [Stop] does, but lets the run in
+// progress, if any, finish first. The method does not wait for the run.
+func (t *taskImpl[TickType]) StopAfterCurrentRun() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.getState() != stateRunning {
+		return
+	}
+	t.softStop.Store(true)
+	if t.inflight.Load() == 0 {
+		t.stop()
+	}
+}
+
+func (t *taskImpl[TickType]) runStarted() {
+	t.idleMux.Lock()
+	defer t.idleMux.Unlock()
+	if t.inflight.Add(1) == 1 {
+		t.idle = make(chan struct{})
+	}
+}
+
+// backgroundStarted counts the run, continued in background by a wrapper, e.g.
+// [utils.Takeover], as in progress until it finishes. The run error, wrapping
+// [utils.ErrStopped], stops the task as if returned by the task function,
+// unless the task has been stopped meanwhile.
+func (t *taskImpl[TickType]) backgroundStarted() func(error) {
+	t.runStarted()
+	return func(err error) {
+		t.runFinished()
+		t.stopOnError(err)
+	}
+}
+
+// stopOnError stops the running task with the error of a run outside of the
+// loop, if the error wraps [utils.ErrStopped], as the loop does.
+func (t *taskImpl[TickType]) stopOnError(err error) {
+	if !errors.Is(err, utils.ErrStopped) {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.getState() == stateRunning {
+		t.stopWith(err)
+		t.notifyFailure(err)
+	}
+}
+
+// runFinished stops the task if requested by [StopAfterCurrentRun] and no
+// other run is in progress.
+func (t *taskImpl[TickType]) runFinished() {
+	t.idleMux.Lock()
+	idle := t.inflight.Add(-1) == 0
+	if idle {
+		close(t.idle)
+	}
+	t.idleMux.Unlock()
+	if !idle || !t.softStop.Load() {
+		return
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.softStop.Load() && t.inflight.Load() == 0 {
+		t.stop()
+	}
+}
+
+// stop stops the running task. Must be called under the lock.
+func (t *taskImpl[TickType]) stop() {
+	t.stopWith(utils.ErrStopped)
+}
+
+// stopWith stops the running task with the cause. Must be called under the
+// lock.
+func (t *taskImpl[TickType]) stopWith(cause error) {
+	t.softStop.Store(false)
+	if t.getState() != stateRunning {
 		return
 	}
+	t.transition(stateStopping)
+	t.finalRun()
 	if t.options.stopTicker {
 		if ticker, isStoppable := t.ticker.(ticker.Stoppable); isStoppable {
 			ticker.Stop()
-			t.once.Store(false)
+			// The exit of the current loop won't affect the task anymore.
+			t.loop = 0
 		}
 	}
 	if t.options.onStop != nil {
 		t.options.onStop()
 	}
+	if t.loop == 0 {
+		t.transition(stateStopped)
+	} else {
+		t.transition(statePaused)
+	}
+	t.endCycle(cause)
+}
+
+// DefaultFinalRunTimeout bounds the wait for the runs in progress and the final
+// run of [WithFinalRun] on stop.
+const DefaultFinalRunTimeout = 10 * time.Second
+
+// finalRun calls the task function one last time, if required by
+// [WithFinalRun], after the runs in progress finish, waiting at most
+// [DefaultFinalRunTimeout] for both. Must be called under the lock in the
+// stopping state, so that no new run is started. The lock is released for the
+// run, so that the task function may call the task methods.
+func (t *taskImpl[TickType]) finalRun() {
+	if !t.options.finalRun {
+		return
+	}
+	run := *t.run.Load()
+	var ctx context.Context = context.Background()
+	if parent := t.parent.Load(); parent != nil {
+		ctx = context.WithoutCancel(*parent)
+	}
+	t.mux.Unlock()
+	defer t.mux.Lock()
+	ctx, cancel := context.WithTimeout(ctx, timescale.Scale(DefaultFinalRunTimeout))
+	defer cancel()
+	_ = t.WaitContext(ctx)
+	_ = run(utils.WithRunCause(ctx, utils.RunCauseFinal), nowTick[TickType]())
+}
+
+// nowTick returns the current time, if the ticks are of [time.Time], or the
+// zero tick otherwise.
+func nowTick[TickType any]() TickType {
+	var tick TickType
+	if now, ok := any(time.Now()).(TickType); ok {
+		tick = now
+	}
+	return tick
+}
+
+// endCycle cancels the task context with the cause, and prepares a new one.
+// Must be called under the lock.
+func (t *taskImpl[TickType]) endCycle(cause error) {
+	t.err = cause
+	t.cancel(cause)
+	t.ctx, t.cancel = context.WithCancelCause(context.Background())
+	ctx := t.ctx
+	t.cycle.Store(&ctx)
+}
+
+// loopExited stops the task if the loop of the given generation is the
+// current one. The loop error is the stop cause if it wraps
+// [utils.ErrStopped].
+func (t *taskImpl[TickType]) loopExited(generation uint64, err error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.loop != generation {
+		return
+	}
+	t.loop = 0
+	switch t.getState() {
+	case stateRunning:
+		t.transition(stateStopping)
+		t.finalRun()
+		if t.options.stopTicker {
+			if ticker, isStoppable := t.ticker.(ticker.Stoppable); isStoppable {
+				ticker.Stop()
+			}
+		}
+		if t.options.onStop != nil {
+			t.options.onStop()
+		}
+		t.transition(stateStopped)
+		if !errors.Is(err, utils.ErrStopped) {
+			err = utils.ErrStopped
+		}
+		t.notifyFailure(err)
+		t.endCycle(err)
+	case statePaused:
+		t.transition(stateStopped)
+	}
+}
+
+// notifyFailure reports the stop cause, other than [utils.ErrStopped] itself,
+// to the notifier and the failure callback of the options.
+func (t *taskImpl[TickType]) notifyFailure(err error) {
+	if err == utils.ErrStopped {
+		return
+	}
+	if n := t.options.notifier; n != nil {
+		failure := TaskFailure{
+			Name:  t.options.name,
+			Time:  time.Now(),
+			Err:   err,
+			Error: err.Error(),
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), timescale.Scale(DefaultNotifyTimeout))
+			defer cancel()
+			n.Notify(ctx, failure)
+		}()
+	}
+	if f := t.options.onFailure; f != nil {
+		go f(err)
+	}
+}
+
+// WaitContext waits for the runs in progress to finish, and returns the
+// context cause if the context is done first, or [ErrNotStarted] if the task
+// has never been started. Stopping the task beforehand prevents the new runs.
+func (t *taskImpl[TickType]) WaitContext(ctx context.Context) error {
+	if errors.Is(t.Error(), ErrNotStarted) {
+		return ErrNotStarted
+	}
+	t.idleMux.Lock()
+	idle := t.idle
+	if t.inflight.Load() == 0 {
+		idle = nil
+	}
+	t.idleMux.Unlock()
+	if idle == nil {
+		return nil
+	}
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// WaitTimeout is [WaitContext] with a timeout, returning
+// [context.DeadlineExceeded] on expiration, or [ErrNotStarted].
+func (t *taskImpl[TickType]) WaitTimeout(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return t.WaitContext(ctx)
+}
+
+// Reload applies the options to the task without stopping it. The in-flight
+// run is not affected, and the following ticks are executed with the new
+// options. The state, learnt by the wrappers, is kept unless their options have
+// changed.
+// The ticker period can be changed independently with SetPeriod.
+// It returns an error, wrapping [ErrOptionType], and keeps the options, if an
+// option does not match the task tick type.
+func (t *taskImpl[TickType]) Reload(opts ...option) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	o := t.options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := checkOptions[TickType](&o); err != nil {
+		return err
+	}
+	t.options = o
+	t.wrap()
+	return nil
+}
+
+// TriggerNow runs the running task out of band with [utils.RunCauseManual],
+// as on a tick: the current time for the [time.Time] ticks, or the zero tick.
+// The tick is not sent to the ticker, so neither its other consumers nor the
+// schedule are affected, and the run may overlap with the scheduled ones.
+// The run context is cancelled when the task stops, and the run error,
+// wrapping [utils.ErrStopped], stops the task as on a tick.
+// If wait is true, TriggerNow waits for the run to finish and returns its
+// error, and the run is also cancelled with the context. Otherwise it returns
+// immediately. It returns [ErrNotRunning] if the task is not running.
+func (t *taskImpl[TickType]) TriggerNow(ctx context.Context, wait bool) error {
+	t.mux.Lock()
+	if t.getState() != stateRunning {
+		t.mux.Unlock()
+		return ErrNotRunning
+	}
+	taskCtx := t.ctx
+	t.mux.Unlock()
+	if !wait {
+		ctx = context.WithoutCancel(ctx)
+	}
+	ctx, cancel := context.WithCancelCause(utils.WithRunCause(ctx, utils.RunCauseManual))
+	stop := context.AfterFunc(taskCtx, func() { cancel(context.Cause(taskCtx)) })
+	tick := nowTick[TickType]()
+	run := func() error {
+		defer cancel(nil)
+		defer stop()
+		err := t.task(ctx, tick)
+		t.stopOnError(err)
+		return err
+	}
+	if !wait {
+		go func() { _ = run() }()
+		return nil
+	}
+	return run()
+}
+
+// SetPeriod changes the period of the running task ticker, e.g. of
+// [ticker.NewTimer], without restarting the task: the next tick is due in d,
+// and the run in progress is not affected. It returns [ErrNotRunning] if the
+// task is not running, and [ErrNotPeriodic] if the ticker does not implement
+// Reset(time.Duration), as [ticker.TimeTicker] does.
+func (t *taskImpl[TickType]) SetPeriod(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("non-positive period %v", d)
+	}
+	periodic, ok := t.ticker.(interface{ Reset(time.Duration) })
+	if !ok {
+		return ErrNotPeriodic
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.getState() != stateRunning {
+		return ErrNotRunning
+	}
+	periodic.Reset(d)
+	return nil
+}
+
+// OnStop arranges to call f in its own goroutine once, when the task is
+// stopped next time, either by [Stop] or by the task function error, wrapping
+// [utils.ErrStopped], which is passed as the cause. Calling the returned stop
+// function prevents the call, as with [context.AfterFunc].
+func (t *taskImpl[TickType]) OnStop(f func(cause error)) (stop func() bool) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	ctx := t.ctx
+	return context.AfterFunc(ctx, func() {
+		f(context.Cause(ctx))
+	})
+}
+
+// Error returns [ErrNotStarted] if the task has never been started, nil if it
+// is running, or the cause of the last stop otherwise: [utils.ErrStopped] or
+// the task function error, wrapping it.
+func (t *taskImpl[TickType]) Error() error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.err
+}
+
+// Reset clears the failure of the task, stopped by it, so that the task can be
+// started again, and drops the state, learnt by the wrappers, e.g. the run
+// durations of [WithAutoTimeout], and the statistics and the logs, provided
+// with [WithFailureStats], [WithIntervalStats] and [WithRunLogs]. The
+// [Metrics] are kept, as they may be shared. Reset has no effect on the task,
+// which is not stopped by a failure.
+func (t *taskImpl[TickType]) Reset() {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if failure(t.err) == nil {
+		return
+	}
+	t.err = utils.ErrStopped
+	if t.options.failures != nil {
+		t.options.failures.Reset()
+	}
+	if t.options.intervals != nil {
+		t.options.intervals.Reset()
+	}
+	if t.options.runLogs != nil {
+		t.options.runLogs.Reset()
+	}
+	t.timed = nil
+	t.wrap()
+}
+
+// failure returns the task stop cause, unless the task has been stopped, or
+// never started.
+func failure(err error) error {
+	if err == utils.ErrStopped || err == ErrNotStarted {
+		return nil
+	}
+	return err
+}
+
+// NextRun returns the time of the next scheduled run, or zero time if the task
+// is not running, or the ticker does not implement [ticker.Schedulable].
+func (t *taskImpl[TickType]) NextRun() time.Time {
+	if scheduled, ok := t.ticker.(ticker.Schedulable); ok && t.getState() == stateRunning {
+		return scheduled.Next()
+	}
+	return time.Time{}
 }
 
 // Ticker returns the ticker, used for the task initialization.
@@ -1,14 +1,22 @@
 package goticks
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log/slog"
 	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/parametalol/curry/assert"
+	"github.com/parametalol/goticks/loop"
 	"github.com/parametalol/goticks/ticker"
 	"github.com/parametalol/goticks/utils"
 )
@@ -65,6 +73,334 @@ func TestTask(t *testing.T) {
 	})
 }
 
+func TestNewTaskFromTicks(t *testing.T) {
+	ch := make(chan int)
+	stopped := make(chan struct{})
+	var ticks []int
+	NewTaskFromTicks(ch, func(tick int) {
+		ticks = append(ticks, tick)
+	}, WithOnStop(func() { close(stopped) })).Start()
+
+	for tick := range 3 {
+		ch <- tick
+	}
+	close(ch)
+	<-stopped
+
+	assert.That(t,
+		assert.EqualSlices([]int{0, 1, 2}, ticks))
+}
+
+func TestTask_OneShot(t *testing.T) {
+	runs := 0
+	stopped := make(chan error, 1)
+	task := NewTask(ticker.NewTimerTicker(time.Millisecond), func() { runs++ })
+	task.OnStop(func(cause error) { stopped <- cause })
+	task.Start()
+	assert.That(t,
+		assert.ErrorIs(<-stopped, utils.ErrStopped),
+		assert.Equal(1, runs))
+}
+
+func TestTask_RunCause(t *testing.T) {
+	ch := make(chan int)
+	causes := make(chan utils.RunCause)
+	task := NewTaskFromTicks(ch, func(ctx context.Context, _ int) {
+		causes <- utils.RunCauseFromContext(ctx)
+	})
+	task.Start()
+	ch <- 0
+	assert.That(t, assert.Equal(utils.RunCauseStart, <-causes))
+	ch <- 1
+	assert.That(t, assert.Equal(utils.RunCauseTick, <-causes))
+	task.Stop()
+	task.Start()
+	ch <- 2
+	assert.That(t, assert.Equal(utils.RunCauseStart, <-causes))
+	close(ch)
+}
+
+func TestTask_StopAfterCurrentRun(t *testing.T) {
+	ch := make(chan int)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var cancelled bool
+	task := NewTaskFromTicks(ch, func(ctx context.Context, _ int) {
+		started <- struct{}{}
+		<-release
+		cancelled = ctx.Err() != nil
+	})
+	stopped := make(chan error, 1)
+	task.OnStop(func(cause error) { stopped <- cause })
+
+	task.Start()
+	go func() { ch <- 0 }()
+	<-started
+	task.StopAfterCurrentRun()
+	assert.That(t, assert.NoError(task.Error()))
+	close(release)
+	assert.That(t,
+		assert.ErrorIs(<-stopped, utils.ErrStopped),
+		assert.False(cancelled))
+
+	task.Start()
+	task.StopAfterCurrentRun()
+	assert.That(t, assert.ErrorIs(task.Error(), utils.ErrStopped))
+	close(ch)
+}
+
+func TestTask_StopCancelsRun(t *testing.T) {
+	ch := make(chan int)
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+	task := NewTaskFromTicks(ch, func(ctx context.Context, _ int) {
+		close(started)
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+	})
+	task.Start()
+	go func() { ch <- 0 }()
+	<-started
+	task.Stop()
+	assert.That(t,
+		assert.ErrorIs(<-cause, utils.ErrStopped),
+		assert.NoError(task.WaitTimeout(time.Second)))
+	close(ch)
+}
+
+func TestTask_WaitContext(t *testing.T) {
+	ch := make(chan int)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	task := NewTaskFromTicks(ch, func() {
+		close(started)
+		<-release
+	})
+	assert.That(t, assert.ErrorIs(task.WaitTimeout(time.Millisecond), ErrNotStarted))
+
+	task.Start()
+	assert.That(t, assert.NoError(task.WaitTimeout(time.Millisecond)))
+	go func() { ch <- 0 }()
+	<-started
+	task.Stop()
+	assert.That(t, assert.ErrorIs(task.WaitTimeout(time.Millisecond), context.DeadlineExceeded))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(utils.ErrStopped)
+	assert.That(t, assert.ErrorIs(task.WaitContext(ctx), utils.ErrStopped))
+
+	close(release)
+	assert.That(t, assert.NoError(task.WaitContext(context.Background())))
+	close(ch)
+}
+
+func TestTask_Reload(t *testing.T) {
+	ticker := ticker.New[int]()
+
+	var deadlines []bool
+	stopped := false
+	task := NewTask(ticker, func(ctx context.Context) {
+		_, ok := ctx.Deadline()
+		deadlines = append(deadlines, ok)
+	})
+	task.Start()
+	ticker.Tick(0).Wait()
+
+	assert.That(t, assert.NoError(task.Reload(WithAutoTimeout(10), WithOnStop(func() { stopped = true }))))
+	for tick := range 4 {
+		ticker.Tick(tick).Wait()
+	}
+	task.Stop()
+
+	assert.That(t,
+		assert.EqualSlices([]bool{false, false, false, false, true}, deadlines),
+		assert.True(stopped))
+}
+
+func TestTask_SetPeriod(t *testing.T) {
+	ticks := make(chan utils.RunCause, 10)
+	task := NewTask(ticker.NewTimer(time.Hour), func(ctx context.Context) {
+		ticks <- utils.RunCauseFromContext(ctx)
+	}, WithTickerStop())
+	assert.That(t, assert.ErrorIs(task.SetPeriod(time.Millisecond), ErrNotRunning))
+
+	task.Start()
+	defer task.Stop()
+	assert.That(t, assert.Equal(utils.RunCauseStart, <-ticks))
+	start := time.Now()
+	assert.That(t,
+		assert.NoError(task.SetPeriod(20*time.Millisecond)),
+		assert.True(task.NextRun().Before(start.Add(time.Minute))),
+		assert.Equal("every 20ms", task.Config().Schedule))
+	// No immediate run on the period change.
+	assert.That(t, assert.Equal(utils.RunCauseTick, <-ticks))
+	assert.That(t, assert.True(time.Since(start) >= 20*time.Millisecond))
+
+	assert.That(t,
+		assert.Not(assert.NoError(task.SetPeriod(0))),
+		assert.ErrorIs(NewTask(ticker.New[int](), func() {}).SetPeriod(time.Second), ErrNotPeriodic))
+}
+
+func TestTask_OnStopError(t *testing.T) {
+	ticker := ticker.New[int]()
+	errFatal := fmt.Errorf("fatal: %w", utils.ErrStopped)
+	task := NewTask(ticker, func(tick int) error {
+		if tick == 1 {
+			return errFatal
+		}
+		return nil
+	})
+	assert.That(t, assert.ErrorIs(task.Error(), ErrNotStarted))
+	causes := make(chan error, 2)
+	task.OnStop(func(cause error) { causes <- cause })
+	task.OnStop(func(error) { t.Error("unexpected call") })()
+
+	task.Start()
+	ticker.Tick(0).Wait()
+	assert.That(t, assert.NoError(task.Error()))
+	ticker.Tick(1).Wait()
+	assert.That(t,
+		assert.ErrorIs(<-causes, errFatal),
+		assert.ErrorIs(task.Error(), errFatal))
+
+	task.OnStop(func(cause error) { causes <- cause })
+	task.Reset()
+	task.Start()
+	task.Stop()
+	assert.That(t, assert.Equal(utils.ErrStopped, <-causes))
+}
+
+func TestTask_Reset(t *testing.T) {
+	ticker := ticker.New[int]()
+	errFatal := fmt.Errorf("fatal: %w", utils.ErrStopped)
+	stats := &utils.FailureStats{}
+	logs := &utils.RunLogs{}
+	task := NewTask(ticker, func(tick int) error {
+		if tick == 1 {
+			return errFatal
+		}
+		return nil
+	}, WithFailureStats(stats), WithRunLogs(logs, slog.NewTextHandler(io.Discard, nil)))
+	stopped := make(chan error, 1)
+	task.OnStop(func(cause error) { stopped <- cause })
+	assert.That(t, assert.NoError(task.StartE()))
+	ticker.Tick(1).Wait()
+	<-stopped
+
+	err := task.StartE()
+	task.Start()
+	assert.That(t,
+		assert.ErrorIs(err, ErrNeedsReset),
+		assert.ErrorIs(err, errFatal),
+		assert.ErrorIs(task.Error(), errFatal),
+		assert.Equal(1, len(stats.All())),
+		assert.Equal(1, len(logs.Recent())))
+
+	task.Reset()
+	assert.That(t,
+		assert.Equal(utils.ErrStopped, task.Error()),
+		assert.Equal(0, len(stats.All())),
+		assert.Equal(0, len(logs.Recent())),
+		assert.NoError(task.StartE()),
+		assert.NoError(task.Error()))
+	task.Stop()
+	task.Reset()
+	assert.That(t,
+		assert.Equal(utils.ErrStopped, task.Error()),
+		assert.NoError(task.StartE()))
+	task.Stop()
+}
+
+func TestTask_TriggerNow(t *testing.T) {
+	ch := make(chan int)
+	type run struct {
+		tick  int
+		cause utils.RunCause
+	}
+	runs := make(chan run, 1)
+	causes := make(chan error, 1)
+	errManual := errors.New("manual")
+	fail := true
+	task := NewTaskFromTicks(ch, func(ctx context.Context, tick int) error {
+		cause := utils.RunCauseFromContext(ctx)
+		runs <- run{tick, cause}
+		if cause != utils.RunCauseManual {
+			return nil
+		}
+		if fail {
+			return fmt.Errorf("%w: %w", errManual, utils.ErrStopped)
+		}
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return nil
+	})
+	assert.That(t, assert.ErrorIs(task.TriggerNow(context.Background(), true), ErrNotRunning))
+
+	task.Start()
+	assert.That(t, assert.ErrorIs(task.TriggerNow(context.Background(), true), errManual))
+	assert.That(t,
+		assert.Equal(run{0, utils.RunCauseManual}, <-runs),
+		assert.ErrorIs(task.Error(), errManual))
+
+	fail = false
+	task.Reset()
+	task.Start()
+	ch <- 1
+	assert.That(t, assert.Equal(run{1, utils.RunCauseStart}, <-runs))
+
+	assert.That(t, assert.NoError(task.TriggerNow(context.Background(), false)))
+	assert.That(t, assert.Equal(run{0, utils.RunCauseManual}, <-runs))
+	task.Stop()
+	assert.That(t,
+		assert.ErrorIs(<-causes, utils.ErrStopped),
+		assert.NoError(task.WaitTimeout(time.Second)),
+		assert.ErrorIs(task.Error(), utils.ErrStopped))
+}
+
+func TestTask_NextRun(t *testing.T) {
+	timer := ticker.NewTimer(time.Hour)
+	ticks := make(chan time.Time, 1)
+	task := NewTask(timer, func(tick time.Time) { ticks <- tick },
+		WithTickerStop())
+	assert.That(t, assert.True(task.NextRun().IsZero()))
+
+	task.Start()
+	tick := <-ticks
+	assert.That(t, assert.Equal(tick.Add(time.Hour), task.NextRun()))
+
+	task.Stop()
+	assert.That(t, assert.True(task.NextRun().IsZero()))
+	assert.That(t, assert.True(NewTask(ticker.New[int](), func() {}).NextRun().IsZero()))
+}
+
+func TestTask_Config(t *testing.T) {
+	task := NewTask(ticker.NewTimerTicker(time.Minute), func() {},
+		WithName("report"),
+		WithRetry(utils.SimpleRetryPolicy(3)),
+		WithHealthGate(func(context.Context) error { return nil }, nil),
+		WithOnRun(func(utils.RunResult) {}))
+	setup := task.Config()
+	assert.That(t,
+		assert.Equal("report", setup.Name),
+		assert.Equal("once after 1m0s", setup.Schedule),
+		assert.Equal(time.Duration(0), setup.Timeout),
+		assert.Equal("utils.SimpleRetryPolicy", setup.Retry),
+		assert.EqualSlices([]string{"Classify", "HealthGate", "Retry"}, setup.Wrappers))
+
+	assert.That(t, assert.NoError(task.Reload(WithTimeout(time.Second), WithRetry(nil))))
+	setup = task.Config()
+	assert.That(t,
+		assert.Equal(time.Second, setup.Timeout),
+		assert.Equal("", setup.Retry),
+		assert.EqualSlices([]string{"Classify", "HealthGate", "Timeout"}, setup.Wrappers))
+
+	cron, err := ticker.ParseCron("0 9 * * mon")
+	assert.That(t, assert.NoError(err))
+	assert.That(t,
+		assert.Equal("cron 0 9 * * mon", NewTask(ticker.FromSchedule(cron), func() {}).Config().Schedule),
+		assert.Equal("", NewTask(ticker.New[int](), func() {}).Config().Schedule))
+}
+
 func Test_options(t *testing.T) {
 	t.Run("on start", func(t *testing.T) {
 		ticker := ticker.New[int]()
@@ -160,4 +496,519 @@ func Test_options(t *testing.T) {
 		assert.That(t,
 			assert.EqualSlices([]int{1, 101}, ticks))
 	})
+
+	t.Run("WithAutoTimeout", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		var deadlines []bool
+		NewTask(ticker, func(ctx context.Context) {
+			_, ok := ctx.Deadline()
+			deadlines = append(deadlines, ok)
+		}, WithAutoTimeout(10)).Start()
+
+		for tick := range 4 {
+			ticker.Tick(tick).Wait()
+		}
+		assert.That(t,
+			assert.EqualSlices([]bool{false, false, false, true}, deadlines))
+	})
+
+	t.Run("WithRetry and WithLog", func(t *testing.T) {
+		ch := make(chan int)
+		var out, errOut strings.Builder
+		attempts := make(chan int, 3)
+		task := NewTaskFromTicks(ch, func(ctx context.Context) error {
+			attempt, _ := ctx.Value(utils.AttemptNumber).(int)
+			attempts <- attempt
+			return errors.New("failed")
+		}, WithRetry(utils.SimpleRetryPolicy(3)), WithLog(&out, &errOut), WithName("test"))
+		stopped := make(chan struct{})
+		task.OnStop(func(error) { close(stopped) })
+		task.Start()
+		ch <- 0
+		close(ch)
+		<-stopped
+		close(attempts)
+		var got []int
+		for attempt := range attempts {
+			got = append(got, attempt)
+		}
+		assert.That(t,
+			assert.EqualSlices([]int{0, 1, 2}, got),
+			assert.Equal("Calling test\nRetry 1 of test\nRetry 2 of test\n", out.String()),
+			assert.True(strings.HasSuffix(errOut.String(), "failed after retry 2 with error: failed\n")))
+	})
+
+	t.Run("WithIdleStop", func(t *testing.T) {
+		timer := ticker.NewTimer(time.Millisecond)
+		runs := 0
+		task := NewTask(timer, func() { runs++ },
+			WithIdleStop(3, func(err error) bool { return err == nil }), WithTickerStop())
+		stopped := make(chan error, 1)
+		task.OnStop(func(cause error) { stopped <- cause })
+		task.Start()
+		assert.That(t,
+			assert.ErrorIs(<-stopped, utils.ErrIdle),
+			assert.Equal(3, runs),
+			assert.True(timer.Next().IsZero()))
+	})
+
+	t.Run("WithIdempotencyKey", func(t *testing.T) {
+		ticker := ticker.New[int]()
+		var ticks []int
+		task := NewTask(ticker, func(tick int) { ticks = append(ticks, tick) },
+			WithIdempotencyKey(strconv.Itoa, &utils.MemoryKeyStore{}))
+		task.Start()
+		for _, tick := range []int{1, 1, 2} {
+			ticker.Tick(tick).Wait()
+		}
+		assert.That(t,
+			assert.EqualSlices([]int{1, 2}, ticks),
+			assert.ErrorIs(task.Reload(WithIdempotencyKey(func(string) string { return "" }, &utils.MemoryKeyStore{})), ErrOptionType))
+
+		defer func() {
+			assert.That(t, assert.Not(assert.Equal(nil, recover())))
+		}()
+		NewTask(ticker, func() {}, WithIdempotencyKey(func(string) string { return "" }, &utils.MemoryKeyStore{}))
+	})
+
+	t.Run("WithInFlight", func(t *testing.T) {
+		ticker := ticker.New[int]()
+		var names []string
+		NewTask(ticker, func() {
+			for _, run := range utils.InFlight() {
+				names = append(names, run.Task)
+			}
+		}, WithName("tracked"), WithInFlight()).Start()
+		ticker.Tick(1).Wait()
+		assert.That(t,
+			assert.True(slices.Contains(names, "tracked")),
+			assert.False(slices.ContainsFunc(utils.InFlight(), func(run utils.InFlightRun) bool {
+				return run.Task == "tracked"
+			})))
+	})
+
+	t.Run("WithFinalRun", func(t *testing.T) {
+		ticker := ticker.New[int]()
+		var buffer, flushed []int
+		var task RestartableWithTicker[int]
+		var finalErr error
+		task = NewTask(ticker, func(ctx context.Context, tick int) {
+			if utils.RunCauseFromContext(ctx) == utils.RunCauseFinal {
+				flushed = append(flushed, buffer...)
+				buffer = nil
+				// The task methods don't deadlock in the final run.
+				finalErr = task.Error()
+				task.Stop()
+				task.Start()
+				return
+			}
+			buffer = append(buffer, tick)
+		}, WithFinalRun())
+		task.Start()
+		ticker.Tick(1).Wait()
+		ticker.Tick(2).Wait()
+		task.Stop()
+		assert.That(t,
+			assert.EqualSlices([]int{1, 2}, flushed),
+			assert.Equal(0, len(buffer)),
+			assert.NoError(finalErr),
+			assert.Equal(utils.ErrStopped, task.Error()))
+	})
+
+	t.Run("WithRunLogs", func(t *testing.T) {
+		ticker := ticker.New[int]()
+		logs := &utils.RunLogs{}
+		NewTask(ticker, func(ctx context.Context) error {
+			utils.LoggerFromContext(ctx).Debug("details")
+			return errors.New("failed")
+		}, WithRunLogs(logs, slog.NewTextHandler(io.Discard, nil))).Start()
+		ticker.Tick(1).Wait()
+		failed, ok := logs.LastFailed()
+		assert.That(t,
+			assert.True(ok),
+			assert.Equal(1, len(failed.Records)),
+			assert.Equal("details", failed.Records[0].Message))
+	})
+
+	t.Run("WithLoopMiddleware", func(t *testing.T) {
+		ticker := ticker.New[int]()
+		var events []string
+		task := NewTask(ticker, func(tick int) { events = append(events, "run "+strconv.Itoa(tick)) },
+			WithLoopMiddleware(func(next loop.LoopFunc[int]) loop.LoopFunc[int] {
+				return func(ticks iter.Seq[int], task func(context.Context, int) error) error {
+					events = append(events, "lease")
+					defer func() { events = append(events, "release") }()
+					return next(ticks, task)
+				}
+			}))
+		stopped := make(chan error, 1)
+		task.OnStop(func(cause error) { stopped <- cause })
+		task.Start()
+		ticker.Tick(1).Wait()
+		ticker.Tick(2).Wait()
+		ticker.Stop()
+		<-stopped
+		assert.That(t,
+			assert.EqualSlices([]string{"lease", "run 1", "run 2", "release"}, events),
+			assert.ErrorIs(task.Reload(WithLoopMiddleware(func(next loop.LoopFunc[string]) loop.LoopFunc[string] { return next })), ErrOptionType))
+
+		defer func() {
+			assert.That(t, assert.Not(assert.Equal(nil, recover())))
+		}()
+		NewTask(ticker, func() {}, WithLoopMiddleware(func(next loop.LoopFunc[string]) loop.LoopFunc[string] { return next }))
+	})
+
+	t.Run("WithOnRun", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		var outcomes []utils.RunOutcome
+		NewTask(ticker, func(tick int) error {
+			if tick == 1 {
+				return errors.New("test")
+			}
+			return nil
+		}, WithOnRun(func(r utils.RunResult) {
+			outcomes = append(outcomes, r.Outcome)
+		})).Start()
+
+		for tick := range 3 {
+			ticker.Tick(tick).Wait()
+		}
+		assert.That(t,
+			assert.EqualSlices([]utils.RunOutcome{
+				utils.RunExecuted, utils.RunFailed, utils.RunExecuted,
+			}, outcomes))
+	})
+
+	t.Run("WithShaper", func(t *testing.T) {
+		shaper := utils.NewShaper(10)
+		ticker := ticker.New[int]()
+		for range 2 {
+			NewTask(ticker, func() {}, WithShaper(shaper, false)).Start()
+		}
+		ticker.Tick(0).Wait()
+		assert.That(t, assert.Equal(2, shaper.Rate()))
+	})
+
+	t.Run("WithPool", func(t *testing.T) {
+		pool := utils.NewPool(1, 0, utils.OverflowSkip)
+		ticker := ticker.New[int]()
+
+		var outcomes []utils.RunOutcome
+		var mux sync.Mutex
+		release, skipped := make(chan struct{}), make(chan struct{})
+		for range 2 {
+			NewTask(ticker, func() { <-release }, WithPool(pool),
+				WithOnRun(func(r utils.RunResult) {
+					mux.Lock()
+					defer mux.Unlock()
+					outcomes = append(outcomes, r.Outcome)
+					if r.Outcome == utils.RunSkipped {
+						close(skipped)
+					}
+				})).Start()
+		}
+		w := ticker.Tick(0)
+		<-skipped
+		close(release)
+		w.Wait()
+		assert.That(t,
+			assert.EqualSlices([]utils.RunOutcome{
+				utils.RunSkipped, utils.RunExecuted,
+			}, outcomes))
+	})
+
+	t.Run("WithHealthGate", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		healthy := false
+		var ticks []int
+		NewTask(ticker, func(tick int) {
+			ticks = append(ticks, tick)
+		}, WithHealthGate(func(context.Context) error {
+			if !healthy {
+				return errors.New("down")
+			}
+			return nil
+		}, nil)).Start()
+
+		ticker.Tick(0).Wait()
+		healthy = true
+		ticker.Tick(1).Wait()
+		assert.That(t,
+			assert.EqualSlices([]int{1}, ticks))
+	})
+
+	t.Run("WithHealthGate policy", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		probes := 0
+		var ticks []int
+		NewTask(ticker, func(tick int) {
+			ticks = append(ticks, tick)
+		}, WithHealthGate(func(context.Context) error {
+			if probes++; probes < 2 {
+				return errors.New("down")
+			}
+			return nil
+		}, utils.SimpleRetryPolicy(2))).Start()
+
+		ticker.Tick(0).Wait()
+		assert.That(t,
+			assert.Equal(2, probes),
+			assert.EqualSlices([]int{0}, ticks))
+	})
+
+	t.Run("WithLoadShedding", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		high := true
+		shedder := utils.NewLoadShedder(func() bool { return high })
+		var ticks []int
+		NewTask(ticker, func(tick int) {
+			ticks = append(ticks, tick)
+		}, WithLoadShedding(shedder, true, 0)).Start()
+
+		ticker.Tick(0).Wait()
+		high = false
+		ticker.Tick(1).Wait()
+		assert.That(t,
+			assert.EqualSlices([]int{1}, ticks),
+			assert.Equal(uint64(1), shedder.Shed()))
+	})
+
+	t.Run("WithTickSequence", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		var reasons []string
+		sequence := &utils.TickSequence{OnGap: func(_ uint64, reason string) {
+			reasons = append(reasons, reason)
+		}}
+		var seqs []uint64
+		task := NewTask(ticker, func(ctx context.Context) {
+			seq, _ := utils.TickSeqFromContext(ctx)
+			seqs = append(seqs, seq)
+		}, WithTickSequence(sequence))
+		task.Start()
+
+		ticker.Tick(0).Wait()
+		task.Stop()
+		ticker.Tick(1).Wait()
+		task.Start()
+		ticker.Tick(2).Wait()
+		assert.That(t,
+			assert.EqualSlices([]uint64{1, 3}, seqs),
+			assert.EqualSlices([]string{SkipReasonPaused}, reasons),
+			assert.Equal(uint64(1), sequence.Gaps()))
+	})
+
+	t.Run("WithParentContext", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		type tenantKey struct{}
+		parent, cancel := context.WithCancel(context.WithValue(context.Background(), tenantKey{}, "acme"))
+		cancel()
+		var tenants []any
+		var errs []error
+		NewTask(ticker, func(ctx context.Context) {
+			tenants = append(tenants, ctx.Value(tenantKey{}))
+			errs = append(errs, ctx.Err())
+		}, WithParentContext(parent)).Start()
+
+		ticker.Tick(0).Wait()
+		assert.That(t,
+			assert.EqualSlices([]any{"acme"}, tenants),
+			assert.EqualSlices([]error{nil}, errs))
+	})
+
+	t.Run("WithErrorFilter", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		errFatal := errors.New("fatal")
+		var ticks []int
+		task := NewTask(ticker, func(tick int) error {
+			ticks = append(ticks, tick)
+			if tick == 1 {
+				return errFatal
+			}
+			return errors.New("transient")
+		}, WithErrorFilter(func(err error) bool { return errors.Is(err, errFatal) }))
+		stopped := make(chan error, 1)
+		task.OnStop(func(cause error) { stopped <- cause })
+		task.Start()
+
+		ticker.Tick(0).Wait()
+		ticker.Tick(1).Wait()
+		cause := <-stopped
+		assert.That(t,
+			assert.EqualSlices([]int{0, 1}, ticks),
+			assert.ErrorIs(cause, errFatal),
+			assert.ErrorIs(task.Error(), utils.ErrStopped))
+	})
+
+	t.Run("WithSkipErrors", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		errFailed := errors.New("failed")
+		healthy := false
+		var errs []error
+		var results []utils.RunResult
+		NewTask(ticker, func() error { return errFailed },
+			WithSkipErrors(),
+			WithHealthGate(func(context.Context) error {
+				if !healthy {
+					return errors.New("down")
+				}
+				return nil
+			}, nil),
+			WithOnRun(func(r utils.RunResult) { results = append(results, r) }),
+			WithLoopObserver(&loop.Observer{RunFinished: func(err error) { errs = append(errs, err) }}),
+		).Start()
+		ticker.Tick(1).Wait()
+		healthy = true
+		ticker.Tick(2).Wait()
+		assert.That(t,
+			assert.Equal(2, len(errs)),
+			assert.ErrorIs(errs[0], utils.ErrSkipped),
+			assert.Equal("skipped: unhealthy", errs[0].Error()),
+			assert.ErrorIs(errs[1], errFailed),
+			assert.Not(assert.ErrorIs(errs[1], utils.ErrSkipped)),
+			assert.EqualSlices([]utils.RunResult{
+				{Outcome: utils.RunSkipped, Reason: utils.SkipReasonUnhealthy},
+				{Outcome: utils.RunFailed, Err: errFailed},
+			}, results))
+
+		// A run with a skipped step is not a skip.
+		var observed []error
+		off := utils.When[int](func(context.Context, int) bool { return false }, "off", func() {})
+		NewTask(ticker, utils.Seq(off, utils.Adapt[int](func() {})),
+			WithSkipErrors(),
+			WithLoopObserver(&loop.Observer{RunFinished: func(err error) { observed = append(observed, err) }}),
+		).Start()
+		ticker.Tick(3).Wait()
+		assert.That(t, assert.EqualSlices([]error{nil}, observed))
+	})
+
+	t.Run("WithStartGate", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		ready := false
+		var ticks []int
+		NewTask(ticker, func(tick int) {
+			ticks = append(ticks, tick)
+		}, WithStartGate(func(context.Context) error {
+			if !ready {
+				return errors.New("migrating")
+			}
+			return nil
+		}, nil)).Start()
+
+		ticker.Tick(0).Wait()
+		ready = true
+		ticker.Tick(1).Wait()
+		ready = false
+		ticker.Tick(2).Wait()
+		assert.That(t,
+			assert.EqualSlices([]int{1, 2}, ticks))
+	})
+
+	t.Run("WithJournal", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		var b strings.Builder
+		NewTask(ticker, func() {}, WithJournal(utils.NewJournal(&b)), WithName("audit")).Start()
+
+		ticker.Tick(0).Wait()
+		lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+		assert.That(t,
+			assert.Equal(2, len(lines)),
+			assert.True(strings.Contains(lines[1], `"task":"audit","event":"end","run":1,"outcome":"executed"`)))
+	})
+
+	t.Run("WithAllowedWindow", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		// The window opens 12 hours from now.
+		hour := (time.Now().UTC().Hour() + 12) % 24
+		var ticks []int
+		NewTask(ticker, func(tick int) {
+			ticks = append(ticks, tick)
+		}, WithAllowedWindow(utils.At(hour, 0), utils.At((hour+1)%24, 0), time.UTC, utils.WindowSkip)).Start()
+
+		ticker.Tick(0).Wait()
+		assert.That(t, assert.Equal(0, len(ticks)))
+	})
+
+	t.Run("WithMinGap", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		var starts []time.Time
+		NewTask(ticker, func() {
+			starts = append(starts, time.Now())
+		}, WithMinGap(20*time.Millisecond)).Start()
+
+		ticker.Tick(0).Wait()
+		ticker.Tick(1).Wait()
+		assert.That(t,
+			assert.Equal(2, len(starts)),
+			assert.True(starts[1].Sub(starts[0]) >= 20*time.Millisecond))
+	})
+
+	t.Run("WithLoopObserver", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		events := make(chan string, 10)
+		task := NewTask(ticker, func(tick int) error {
+			if tick == 2 {
+				return utils.ErrStopped
+			}
+			return nil
+		}, WithLoopObserver(&loop.Observer{
+			TickReceived: func() { events <- "received" },
+			RunStarted:   func() { events <- "started" },
+			RunFinished:  func(error) { events <- "finished" },
+			TickDropped:  func() { events <- "dropped" },
+			LoopExited:   func(r loop.ExitReason, _ error) { events <- r.String() },
+		}))
+		task.Start()
+		ticker.Tick(0).Wait()
+		task.Stop()
+		ticker.Tick(1).Wait()
+		stopped := make(chan struct{})
+		task.OnStop(func(error) { close(stopped) })
+		task.Start()
+		ticker.Tick(2).Wait()
+		<-stopped
+		close(events)
+
+		var got []string
+		for e := range events {
+			got = append(got, e)
+		}
+		assert.That(t,
+			assert.EqualSlices([]string{
+				"received", "started", "finished",
+				"received", "dropped",
+				"received", "started", "finished",
+				"task stopped",
+			}, got))
+	})
+
+	t.Run("WithFailureStats", func(t *testing.T) {
+		ticker := ticker.New[int]()
+
+		stats := &utils.FailureStats{}
+		NewTask(ticker, func(tick int) {
+			panic(tick)
+		}, WithFailureStats(stats)).Start()
+
+		ticker.Tick(0).Wait()
+		ticker.Tick(0).Wait()
+		ticker.Tick(1).Wait()
+		all := stats.All()
+		assert.That(t,
+			assert.Equal(2, len(all)),
+			assert.Equal(3, all[0].Count+all[1].Count))
+	})
 }
@@ -1,7 +1,10 @@
 package ticker
 
 import (
+	"context"
+	"iter"
 	"testing"
+	"time"
 )
 
 // BenchmarkTicker_TickWait measures the overhead of sending and acknowledging ticks.
@@ -18,3 +21,39 @@ func BenchmarkTicker_TickWait(b *testing.B) {
 		t.Tick(i).Wait()
 	}
 }
+
+const jitterPeriod = time.Millisecond
+
+// reportJitter reports the mean absolute deviation of the intervals between
+// the ticks from the period.
+func reportJitter(b *testing.B, ticks iter.Seq[time.Time]) {
+	var last time.Time
+	var deviation time.Duration
+	n := 0
+	for tick := range ticks {
+		if n > 0 {
+			deviation += (tick.Sub(last) - jitterPeriod).Abs()
+		}
+		last = tick
+		if n++; n > b.N {
+			break
+		}
+	}
+	b.ReportMetric(float64(deviation.Nanoseconds())/float64(b.N), "jitter-ns/tick")
+}
+
+// BenchmarkTimer_Jitter measures the tick jitter of [NewTimer] for a short
+// period.
+func BenchmarkTimer_Jitter(b *testing.B) {
+	b.ReportAllocs()
+	t := NewTimer(jitterPeriod)
+	defer t.Stop()
+	reportJitter(b, t.Ticks())
+}
+
+// BenchmarkHighResTicks_Jitter measures the tick jitter of [HighResTicks] for
+// a short period.
+func BenchmarkHighResTicks_Jitter(b *testing.B) {
+	b.ReportAllocs()
+	reportJitter(b, HighResTicks(context.Background(), jitterPeriod))
+}
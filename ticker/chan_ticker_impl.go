@@ -0,0 +1,45 @@
+package ticker
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+type chanTickerImpl[TickType any] struct {
+	tickerImpl[TickType]
+	ch <-chan TickType
+
+	forwardOnce sync.Once
+	closed      atomic.Bool
+}
+
+var _ Ticker[any] = (*chanTickerImpl[any])(nil)
+
+// FromChan creates a ticker that forwards the ticks from the channel to the
+// consumers. The forwarding starts on the first call to Ticks, and every tick
+// is processed by the consumers before the next one is read.
+// When the channel is closed, the ticker is stopped, and the later consumers
+// receive no ticks.
+func FromChan[TickType any](ch <-chan TickType) Ticker[TickType] {
+	return &chanTickerImpl[TickType]{ch: ch}
+}
+
+func (t *chanTickerImpl[TickType]) Ticks() iter.Seq[TickType] {
+	ticks := t.tickerImpl.Ticks()
+	if t.closed.Load() {
+		t.tickerImpl.Stop()
+	}
+	t.forwardOnce.Do(func() {
+		go t.forward()
+	})
+	return ticks
+}
+
+func (t *chanTickerImpl[TickType]) forward() {
+	for tick := range t.ch {
+		t.Tick(tick).Wait()
+	}
+	t.closed.Store(true)
+	t.tickerImpl.Stop()
+}
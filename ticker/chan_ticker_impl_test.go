@@ -0,0 +1,34 @@
+package ticker
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestFromChan(t *testing.T) {
+	t.Run("forward and close", func(t *testing.T) {
+		ch := make(chan int)
+		ticker := FromChan(ch)
+		ticks := ticker.Ticks()
+		go func() {
+			for i := range 3 {
+				ch <- i
+			}
+			close(ch)
+		}()
+		assert.That(t,
+			assert.EqualSlices([]int{0, 1, 2}, slices.Collect(ticks)))
+	})
+
+	t.Run("ticks after close", func(t *testing.T) {
+		ch := make(chan int)
+		close(ch)
+		ticker := FromChan(ch)
+		assert.That(t,
+			assert.Equal(0, len(slices.Collect(ticker.Ticks()))))
+		assert.That(t,
+			assert.Equal(0, len(slices.Collect(ticker.Ticks()))))
+	})
+}
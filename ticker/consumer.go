@@ -1,6 +1,9 @@
 package ticker
 
-import "iter"
+import (
+	"iter"
+	"sync"
+)
 
 type tack[TickType any] struct {
 	tick  TickType
@@ -13,6 +16,8 @@ type consumer[TickType any] struct {
 	tickCh  chan tack[TickType]
 	closeCh chan struct{}
 	doneCh  chan struct{}
+
+	closeOnce sync.Once
 }
 
 func newConsumer[TickType any]() *consumer[TickType] {
@@ -29,7 +34,6 @@ func (c *consumer[TickType]) send(tick TickType) {
 	select {
 	case <-c.doneCh:
 	case <-c.closeCh:
-		close(c.tickCh)
 	case c.tickCh <- tack:
 		<-tack.ackCh
 	}
@@ -38,7 +42,7 @@ func (c *consumer[TickType]) send(tick TickType) {
 // close is the writer method that closes the consumer.
 // The closed consumer won't receive more ticks, and cannot be reopened.
 func (c *consumer[TickType]) close() {
-	close(c.closeCh)
+	c.closeOnce.Do(func() { close(c.closeCh) })
 }
 
 // ticks returns an iterator that consumes all ticks and notifies the writer
@@ -48,11 +52,8 @@ func (c *consumer[TickType]) ticks() iter.Seq[TickType] {
 		defer close(c.doneCh)
 		for {
 			select {
-			case tickAck, ok := <-c.tickCh:
-				if !ok {
-					return
-				}
-				ok = yield(tickAck.tick)
+			case tickAck := <-c.tickCh:
+				ok := yield(tickAck.tick)
 				close(tickAck.ackCh)
 				if !ok {
 					return
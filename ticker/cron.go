@@ -0,0 +1,374 @@
+package ticker
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron is wrapped by the errors of [ParseCron].
+var ErrInvalidCron = errors.New("invalid cron spec")
+
+// cronField describes a field of the cron spec.
+type cronField struct {
+	name, plural string
+	min, max     int
+	// names are the value names, e.g. "jan", starting from min.
+	names []string
+}
+
+var (
+	cronMinute  = cronField{name: "minute", plural: "minutes", min: 0, max: 59}
+	cronHour    = cronField{name: "hour", plural: "hours", min: 0, max: 23}
+	cronDay     = cronField{name: "day of month", plural: "days of month", min: 1, max: 31}
+	cronMonth   = cronField{name: "month", plural: "months", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronWeekday = cronField{name: "day of week", plural: "days of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronSchedule is the schedule of a cron spec. The fields are the bit sets of
+// the matching values.
+type cronSchedule struct {
+	spec                              string
+	fields                            []string
+	minute, hour, day, month, weekday uint64
+	// anyDay and anyWeekday tell whether the day fields are not restricted.
+	anyDay, anyWeekday bool
+	// fixedTime tells whether the minute and the hour fields have no
+	// wildcards and steps, so that the spec ticks at fixed times of the day.
+	fixedTime bool
+	dst       DSTPolicy
+}
+
+// DSTPolicy tells how a cron schedule handles the local times, skipped by the
+// spring-forward and repeated by the fall-back daylight saving time
+// transitions. The zero policy skips the skipped times, and ticks at the first
+// occurrence of the repeated ones, as the classic cron does.
+type DSTPolicy struct {
+	Skipped  SkippedTimePolicy
+	Repeated RepeatedTimePolicy
+}
+
+// SkippedTimePolicy tells how a cron schedule handles the local times, skipped
+// by the spring-forward transition, e.g. 2:30 in the zone, which clocks jump
+// from 2:00 to 3:00.
+type SkippedTimePolicy int
+
+const (
+	// SkippedTimeSkip does not tick at the skipped times.
+	SkippedTimeSkip SkippedTimePolicy = iota
+	// SkippedTimeNextValid ticks once at the transition, i.e. at the first
+	// valid local time after the skipped ones, e.g. at 3:00 instead of 2:30.
+	SkippedTimeNextValid
+)
+
+// RepeatedTimePolicy tells how a cron schedule handles the local times,
+// repeated by the fall-back transition, e.g. 2:30 in the zone, which clocks
+// jump from 3:00 back to 2:00.
+type RepeatedTimePolicy int
+
+const (
+	// RepeatedTimeOnce ticks at the first occurrence of the repeated times, if
+	// the spec has fixed minutes and hours, e.g. "30 2 * * *". The specs with
+	// the wildcards or the steps in these fields, e.g. "*/5 * * * *", tick at
+	// both occurrences, so that the repeated hour has no gap.
+	RepeatedTimeOnce RepeatedTimePolicy = iota
+	// RepeatedTimeTwice ticks at both occurrences of the repeated times.
+	RepeatedTimeTwice
+)
+
+var _ Schedule = (*cronSchedule)(nil)
+
+// String returns the cron spec, e.g. "cron 0 9 * * mon".
+func (s *cronSchedule) String() string {
+	return "cron " + s.spec
+}
+
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if s == name {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid value %q", f.name, s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s: value %d out of range %d-%d", f.name, v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// parse returns the bit set of the values, matching the field spec.
+func (f cronField) parse(spec string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(spec, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: invalid step %q", f.name, part[i+1:])
+			}
+		}
+		lo, hi := f.min, f.max
+		switch i := strings.IndexByte(rng, '-'); {
+		case rng == "*":
+		case i >= 0:
+			var err error
+			if lo, err = f.value(rng[:i]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(rng[i+1:]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s: invalid range %q", f.name, rng)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// ParseCronDST is [ParseCron] with the explicit policy of the daylight saving
+// time transitions of the schedule location.
+func ParseCronDST(spec string, policy DSTPolicy) (Schedule, error) {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return nil, err
+	}
+	schedule.(*cronSchedule).dst = policy
+	return schedule, nil
+}
+
+// ParseCron parses the standard 5-field cron spec: minute, hour, day of month,
+// month and day of week, or one of the macros, such as @daily. The fields
+// accept the lists, the ranges, the steps, and the three-letter names of the
+// months and the days of week. The week starts on Sunday, which is either 0
+// or 7. As in the traditional cron, if both the day of month and the day of
+// week are restricted, either of them must match.
+// The returned schedule computes the tick times in the location of the from
+// time. The local times, skipped by the daylight saving time transitions, are
+// skipped, and the repeated ones tick once, see [RepeatedTimeOnce] and
+// [ParseCronDST].
+// See [FromSchedule] for the cron ticker.
+func ParseCron(spec string) (Schedule, error) {
+	expanded := strings.ToLower(strings.TrimSpace(spec))
+	if macro, ok := cronMacros[expanded]; ok {
+		expanded = macro
+	}
+	fields := strings.Fields(expanded)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: expected 5 fields, got %d", ErrInvalidCron, spec, len(fields))
+	}
+	s := &cronSchedule{spec: spec, fields: fields}
+	for i, field := range []struct {
+		cronField
+		set *uint64
+	}{
+		{cronMinute, &s.minute},
+		{cronHour, &s.hour},
+		{cronDay, &s.day},
+		{cronMonth, &s.month},
+		{cronWeekday, &s.weekday},
+	} {
+		set, err := field.parse(fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %w", ErrInvalidCron, spec, err)
+		}
+		*field.set = set
+	}
+	// Sunday is both 0 and 7.
+	if s.weekday&(1<<7) != 0 {
+		s.weekday |= 1
+	}
+	s.anyDay = fields[2] == "*"
+	s.anyWeekday = fields[4] == "*"
+	s.fixedTime = !strings.ContainsAny(fields[0]+fields[1], "*/")
+	return s, nil
+}
+
+// matches tells whether the wall clock of t matches the spec.
+func (s *cronSchedule) matches(t time.Time) bool {
+	return s.month&(1<<t.Month()) != 0 && s.dayMatches(t) &&
+		s.hour&(1<<t.Hour()) != 0 && s.minute&(1<<t.Minute()) != 0
+}
+
+// skippedMatch tells whether t is the spring-forward transition, and the spec
+// matches any of the skipped local times.
+func (s *cronSchedule) skippedMatch(t time.Time) bool {
+	before := t.Add(-time.Minute)
+	_, offset := t.Zone()
+	if _, offsetBefore := before.Zone(); offsetBefore >= offset {
+		return false
+	}
+	// The wall clocks are compared in UTC to avoid the transition.
+	wall := func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, time.UTC)
+	}
+	for skipped := wall(before).Add(time.Minute); skipped.Before(wall(t)); skipped = skipped.Add(time.Minute) {
+		if s.matches(skipped) {
+			return true
+		}
+	}
+	return false
+}
+
+// repeated tells whether the wall clock of t has occurred before, because of a
+// fall-back transition.
+func repeated(t time.Time) bool {
+	_, offset := t.Zone()
+	// The transitions shift the clocks by less than 3 hours.
+	_, offsetBefore := t.Add(-3 * time.Hour).Zone()
+	if offsetBefore <= offset {
+		return false
+	}
+	first := t.Add(-time.Duration(offsetBefore-offset) * time.Second)
+	return first.Day() == t.Day() && first.Hour() == t.Hour() && first.Minute() == t.Minute()
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	day := s.day&(1<<t.Day()) != 0
+	weekday := s.weekday&(1<<int(t.Weekday())) != 0
+	if s.anyDay || s.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// cronSearchLimit bounds the search of the next tick, e.g. for the specs,
+// which never match, such as February 30.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// NextN returns the first n tick times not before from, or less, if the spec
+// matches no time within the next years.
+func (s *cronSchedule) NextN(from time.Time, n int) []time.Time {
+	var ticks []time.Time
+	t := from.Truncate(time.Minute)
+	if t.Before(from) {
+		t = t.Add(time.Minute)
+	}
+	limit := from.Add(cronSearchLimit)
+	for len(ticks) < n && t.Before(limit) {
+		y, m, d := t.Date()
+		switch {
+		case s.dst.Skipped == SkippedTimeNextValid && s.skippedMatch(t):
+			ticks = append(ticks, t)
+			t = t.Add(time.Minute)
+		case s.month&(1<<m) == 0:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<t.Hour()) == 0:
+			// Not time.Date, which would skip the first occurrence of a
+			// repeated hour.
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case s.minute&(1<<t.Minute()) == 0,
+			s.dst.Repeated == RepeatedTimeOnce && s.fixedTime && repeated(t):
+			t = t.Add(time.Minute)
+		default:
+			ticks = append(ticks, t)
+			t = t.Add(time.Minute)
+		}
+	}
+	return ticks
+}
+
+// describe returns the description of the field values, or an empty string if
+// the field is not restricted.
+func (f cronField) describe(spec string, set uint64) string {
+	if spec == "*" {
+		return ""
+	}
+	if strings.HasPrefix(spec, "*/") {
+		return "every " + spec[2:] + " " + f.plural
+	}
+	var values []string
+	for set != 0 {
+		v := bits.TrailingZeros64(set)
+		set &^= 1 << v
+		if f.names != nil && v-f.min < len(f.names) {
+			values = append(values, strings.ToUpper(f.names[v-f.min][:1])+f.names[v-f.min][1:])
+		} else if f.names == nil || v != f.max {
+			values = append(values, strconv.Itoa(v))
+		}
+	}
+	if len(values) == 1 {
+		return f.name + " " + values[0]
+	}
+	return f.plural + " " + strings.Join(values, ", ")
+}
+
+// ExplainCron returns the human-readable description of the cron spec with the
+// next three tick times in the local time, or the parsing error message.
+//
+// Example:
+//
+//	ExplainCron("*/15 9-17 * * mon-fri")
+//	// every 15 minutes, hours 9, 10, 11, 12, 13, 14, 15, 16, 17, days of week Mon, Tue, Wed, Thu, Fri; next: ...
+func ExplainCron(spec string) string {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		return err.Error()
+	}
+	s := schedule.(*cronSchedule)
+	var parts []string
+	if s.minute == 1<<bits.TrailingZeros64(s.minute) && s.hour == 1<<bits.TrailingZeros64(s.hour) {
+		parts = append(parts, fmt.Sprintf("at %02d:%02d", bits.TrailingZeros64(s.hour), bits.TrailingZeros64(s.minute)))
+	} else {
+		minute := cronMinute.describe(s.fields[0], s.minute)
+		if minute == "" {
+			minute = "every minute"
+		}
+		parts = append(parts, minute)
+		if hour := cronHour.describe(s.fields[1], s.hour); hour != "" {
+			parts = append(parts, hour)
+		}
+	}
+	for i, field := range []struct {
+		cronField
+		set uint64
+	}{
+		{cronDay, s.day},
+		{cronMonth, s.month},
+		{cronWeekday, s.weekday},
+	} {
+		if d := field.describe(s.fields[i+2], field.set); d != "" {
+			parts = append(parts, d)
+		}
+	}
+	var next []string
+	for _, tick := range s.NextN(time.Now(), 3) {
+		next = append(next, tick.Format("Mon 2006-01-02 15:04 MST"))
+	}
+	if len(next) == 0 {
+		return strings.Join(parts, ", ") + "; never"
+	}
+	return strings.Join(parts, ", ") + "; next: " + strings.Join(next, ", ")
+}
@@ -0,0 +1,119 @@
+package ticker
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2026, 10, 16, 16, 59, 30, 0, time.UTC) // Friday.
+	next := func(spec string, n int) []time.Time {
+		s, err := ParseCron(spec)
+		assert.That(t, assert.NoError(err))
+		return s.NextN(from, n)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	assert.That(t,
+		assert.EqualSlices([]time.Time{at(16, 17, 0), at(16, 17, 1)}, next("* * * * *", 2)),
+		assert.EqualSlices([]time.Time{at(16, 17, 0), at(16, 17, 15), at(16, 17, 30)}, next("*/15 9-17 * * mon-fri", 3)),
+		assert.EqualSlices([]time.Time{at(19, 9, 0)}, next("0 9 * * MON", 1)),
+		assert.EqualSlices([]time.Time{at(18, 0, 0)}, next("@weekly", 1)),
+		assert.EqualSlices([]time.Time{at(18, 0, 0)}, next("0 0 * * 7", 1)),
+		assert.EqualSlices([]time.Time{time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)}, next("@monthly", 1)),
+		// Either the day of month or the day of week matches.
+		assert.EqualSlices([]time.Time{at(17, 0, 0), at(18, 0, 0)}, next("0 0 17 * sun", 2)),
+		assert.EqualSlices([]time.Time{time.Date(2027, 1, 1, 0, 5, 0, 0, time.UTC)}, next("5/30 0 1 jan *", 1)),
+		assert.Equal(0, len(next("0 0 30 feb *", 1))))
+
+	for spec, message := range map[string]string{
+		"* * * *":        `invalid cron spec "* * * *": expected 5 fields, got 4`,
+		"60 * * * *":     `invalid cron spec "60 * * * *": minute: value 60 out of range 0-59`,
+		"* x * * *":      `invalid cron spec "* x * * *": hour: invalid value "x"`,
+		"* * 5-1 * *":    `invalid cron spec "* * 5-1 * *": day of month: invalid range "5-1"`,
+		"* * * */0 *":    `invalid cron spec "* * * */0 *": month: invalid step "0"`,
+		"* * * * mon-xx": `invalid cron spec "* * * * mon-xx": day of week: invalid value "xx"`,
+	} {
+		_, err := ParseCron(spec)
+		assert.That(t,
+			assert.True(errors.Is(err, ErrInvalidCron)),
+			assert.Equal(message, err.Error()))
+	}
+}
+
+func TestExplainCron(t *testing.T) {
+	explain := func(spec string) string {
+		description, _, _ := strings.Cut(ExplainCron(spec), ";")
+		return description
+	}
+	assert.That(t,
+		assert.Equal("every 15 minutes, hours 9, 10, 11, 12, 13, 14, 15, 16, 17, days of week Mon, Tue, Wed, Thu, Fri", explain("*/15 9-17 * * mon-fri")),
+		assert.Equal("at 00:00, day of week Sun", explain("@weekly")),
+		assert.Equal("every minute", explain("* * * * *")),
+		assert.Equal("minutes 0, 30, every 2 hours, month Jan", explain("0,30 */2 * 1 *")),
+		assert.True(strings.Contains(ExplainCron("@daily"), "; next: ")),
+		assert.True(strings.HasSuffix(ExplainCron("0 0 30 feb *"), "; never")),
+		assert.Equal(`invalid cron spec "x": expected 5 fields, got 1`, ExplainCron("x")))
+}
+
+func TestParseCronDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.That(t, assert.NoError(err))
+	next := func(spec string, policy DSTPolicy, from time.Time, n int) []time.Time {
+		s, err := ParseCronDST(spec, policy)
+		assert.That(t, assert.NoError(err))
+		return s.NextN(from, n)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, berlin)
+	}
+	// The clocks jump from 2:00 to 3:00 on March 31, and from 3:00 back to
+	// 2:00 on October 27.
+	transition := time.Date(2024, 3, 31, 1, 0, 0, 0, time.UTC)
+	firstHalfPast2 := time.Date(2024, 10, 27, 0, 30, 0, 0, time.UTC).In(berlin)
+	secondHalfPast2 := time.Date(2024, 10, 27, 1, 30, 0, 0, time.UTC).In(berlin)
+	equal := func(expected, actual []time.Time) assert.Assertion {
+		return assert.True(slices.EqualFunc(expected, actual, time.Time.Equal))
+	}
+
+	t.Run("skipped", func(t *testing.T) {
+		from := at(3, 30, 12, 0)
+		assert.That(t,
+			equal([]time.Time{at(4, 1, 2, 30)}, next("30 2 * * *", DSTPolicy{}, from, 1)),
+			equal([]time.Time{transition, at(4, 1, 2, 30)},
+				next("30 2 * * *", DSTPolicy{Skipped: SkippedTimeNextValid}, from, 2)),
+			// The transition time itself is not skipped.
+			equal([]time.Time{transition, at(4, 1, 3, 0)},
+				next("0 3 * * *", DSTPolicy{Skipped: SkippedTimeNextValid}, from, 2)),
+			equal([]time.Time{at(3, 31, 1, 45), transition, at(3, 31, 3, 15)},
+				next("*/15 1-3 * * *", DSTPolicy{Skipped: SkippedTimeNextValid}, at(3, 31, 1, 40), 3)),
+			equal([]time.Time{at(3, 31, 1, 45), transition, at(3, 31, 3, 15)},
+				next("*/15 1-3 * * *", DSTPolicy{}, at(3, 31, 1, 40), 3)))
+	})
+
+	t.Run("repeated", func(t *testing.T) {
+		from := at(10, 26, 12, 0)
+		assert.That(t,
+			equal([]time.Time{firstHalfPast2, secondHalfPast2, at(10, 28, 2, 30)},
+				next("30 2 * * *", DSTPolicy{Repeated: RepeatedTimeTwice}, from, 3)),
+			equal([]time.Time{firstHalfPast2, at(10, 28, 2, 30)},
+				next("30 2 * * *", DSTPolicy{}, from, 2)),
+			// The specs with the wildcards or the steps tick at both
+			// occurrences.
+			equal([]time.Time{firstHalfPast2, secondHalfPast2, at(10, 27, 3, 30)},
+				next("30 * * * *", DSTPolicy{}, firstHalfPast2.Add(-time.Minute), 3)),
+			equal([]time.Time{firstHalfPast2, firstHalfPast2.Add(30 * time.Minute), secondHalfPast2},
+				next("*/30 2 * * *", DSTPolicy{}, firstHalfPast2.Add(-time.Minute), 3)),
+			// The second occurrence is not repeated, if the search starts after
+			// the first one.
+			equal([]time.Time{at(10, 28, 2, 30)},
+				next("30 2 * * *", DSTPolicy{Repeated: RepeatedTimeOnce}, firstHalfPast2.Add(time.Minute), 1)))
+	})
+}
@@ -0,0 +1,41 @@
+package ticker
+
+import (
+	"context"
+	"iter"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// HighResTicks returns the ticks of a [time.Ticker] with the period d, read
+// directly by the consumer with no intermediate goroutine or channel, which
+// reduces the jitter and the allocations for short periods, e.g. below 10ms.
+// The first tick is immediate. The sequence ends when the context is done.
+//
+// Unlike the [Tickable] tickers, the sequence has a single consumer, and
+// cannot be stopped, reset or ticked externally. As with [time.Ticker], the
+// ticks are dropped while the consumer is busy.
+//
+// Example:
+//
+//	loop.OnTick(ticker.HighResTicks(ctx, time.Millisecond), task)
+func HighResTicks(ctx context.Context, d time.Duration) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		if ctx.Err() != nil || !yield(time.Now()) {
+			return
+		}
+		timer := time.NewTicker(timescale.Scale(d))
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case tick := <-timer.C:
+				if !yield(tick) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package ticker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestHighResTicks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ticks []time.Time
+	for tick := range HighResTicks(ctx, time.Millisecond) {
+		ticks = append(ticks, tick)
+		if len(ticks) == 3 {
+			cancel()
+		}
+	}
+	assert.That(t,
+		assert.Equal(3, len(ticks)),
+		assert.True(ticks[2].After(ticks[0])))
+
+	n := 0
+	for range HighResTicks(ctx, time.Millisecond) {
+		n++
+	}
+	assert.That(t, assert.Equal(0, n))
+}
@@ -0,0 +1,65 @@
+package ticker
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type limitTickerImpl[TickType any] struct {
+	tickerImpl[TickType]
+	inner Ticker[TickType]
+	n     int
+
+	forwardOnce sync.Once
+	closed      atomic.Bool
+}
+
+var _ Ticker[any] = (*limitTickerImpl[any])(nil)
+
+// Limit creates a ticker that forwards the first n ticks of the inner ticker to
+// the consumers, and stops both tickers after that. The forwarding starts on the
+// first call to Ticks, and every tick is processed by the consumers before the
+// next one is forwarded.
+func Limit[TickType any](ticker Ticker[TickType], n int) Ticker[TickType] {
+	return &limitTickerImpl[TickType]{inner: ticker, n: n}
+}
+
+// NewCountedTimer creates a ticker that ticks n times on a timer, and stops.
+func NewCountedTimer(d time.Duration, n int) Ticker[time.Time] {
+	return Limit(NewTimer(d), n)
+}
+
+func (t *limitTickerImpl[TickType]) Ticks() iter.Seq[TickType] {
+	ticks := t.tickerImpl.Ticks()
+	if t.closed.Load() {
+		t.tickerImpl.Stop()
+	}
+	t.forwardOnce.Do(func() {
+		innerTicks := t.inner.Ticks()
+		go t.forward(innerTicks)
+	})
+	return ticks
+}
+
+func (t *limitTickerImpl[TickType]) forward(ticks iter.Seq[TickType]) {
+	if t.n > 0 {
+		i := 0
+		for tick := range ticks {
+			t.Tick(tick).Wait()
+			if i++; i == t.n {
+				break
+			}
+		}
+	}
+	t.closed.Store(true)
+	t.inner.Stop()
+	t.tickerImpl.Stop()
+}
+
+// Stop stops the inner ticker and terminates consumers.
+func (t *limitTickerImpl[TickType]) Stop() {
+	t.inner.Stop()
+	t.tickerImpl.Stop()
+}
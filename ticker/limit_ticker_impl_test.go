@@ -0,0 +1,39 @@
+package ticker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestLimit(t *testing.T) {
+	t.Run("manual ticker", func(t *testing.T) {
+		inner := New[int]()
+		ticker := Limit(inner, 2)
+		ticks := ticker.Ticks()
+		go func() {
+			for i := range 5 {
+				inner.Tick(i).Wait()
+			}
+		}()
+		assert.That(t,
+			assert.EqualSlices([]int{0, 1}, slices.Collect(ticks)),
+			assert.Equal(0, len(slices.Collect(ticker.Ticks()))))
+	})
+
+	t.Run("counted timer", func(t *testing.T) {
+		ticker := NewCountedTimer(time.Millisecond, 3)
+		assert.That(t,
+			assert.Equal(3, len(slices.Collect(ticker.Ticks()))))
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		ticker := NewCountedTimer(time.Hour, 3)
+		ticks := ticker.Ticks()
+		time.AfterFunc(10*time.Millisecond, ticker.Stop)
+		assert.That(t,
+			assert.Equal(1, len(slices.Collect(ticks))))
+	})
+}
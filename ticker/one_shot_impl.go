@@ -0,0 +1,36 @@
+package ticker
+
+import (
+	"sync"
+	"time"
+)
+
+// onceAfter is the schedule of a single tick after the delay since the first
+// query, i.e. since the start of the [FromSchedule] ticker.
+type onceAfter struct {
+	delay time.Duration
+
+	once sync.Once
+	at   time.Time
+}
+
+func (s *onceAfter) NextN(from time.Time, n int) []time.Time {
+	s.once.Do(func() { s.at = from.Add(s.delay) })
+	if n < 1 || s.at.Before(from) {
+		return nil
+	}
+	return []time.Time{s.at}
+}
+
+// String describes the delay, e.g. "once after 1m0s".
+func (s *onceAfter) String() string {
+	return "once after " + s.delay.String()
+}
+
+// NewTimerTicker creates a ticker that ticks once after the delay, and stops,
+// e.g. to run a task once with the task lifecycle management. Unlike
+// [NewTimer], the first tick is not sent immediately. The timer is started on
+// the first call to Ticks. It is the [FromSchedule] ticker of a single tick.
+func NewTimerTicker(delay time.Duration) Ticker[time.Time] {
+	return FromSchedule(&onceAfter{delay: delay})
+}
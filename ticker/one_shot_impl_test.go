@@ -0,0 +1,34 @@
+package ticker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestNewTimerTicker(t *testing.T) {
+	t.Run("one tick", func(t *testing.T) {
+		ticker := NewTimerTicker(10 * time.Millisecond)
+		start := time.Now()
+		ticks := ticker.Ticks()
+		next := ticker.(Schedulable).Next()
+		got := slices.Collect(ticks)
+		assert.That(t,
+			assert.Equal(1, len(got)),
+			assert.True(got[0].Sub(start) >= 10*time.Millisecond),
+			assert.True(!next.Before(start.Add(10*time.Millisecond))),
+			assert.True(ticker.(Schedulable).Next().IsZero()),
+			assert.Equal(0, len(slices.Collect(ticker.Ticks()))))
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		ticker := NewTimerTicker(time.Hour)
+		ticks := ticker.Ticks()
+		time.AfterFunc(10*time.Millisecond, ticker.Stop)
+		assert.That(t,
+			assert.Equal(0, len(slices.Collect(ticks))),
+			assert.True(ticker.(Schedulable).Next().IsZero()))
+	})
+}
@@ -0,0 +1,36 @@
+package ticker
+
+import "time"
+
+// Schedule computes the tick times, e.g. to preview the upcoming ticks.
+type Schedule interface {
+	// NextN returns the first n tick times not before from.
+	NextN(from time.Time, n int) []time.Time
+}
+
+// Periodic is the schedule of the ticks every Period, starting at Start, as of
+// a [NewTimer] ticker, started at Start.
+type Periodic struct {
+	Start  time.Time
+	Period time.Duration
+}
+
+var _ Schedule = Periodic{}
+
+// NextN returns the first n tick times not before from, or nil if the period
+// is not positive.
+func (p Periodic) NextN(from time.Time, n int) []time.Time {
+	if p.Period <= 0 || n <= 0 {
+		return nil
+	}
+	first := p.Start
+	if from.After(p.Start) {
+		// Round up the number of the periods since the start.
+		first = p.Start.Add((from.Sub(p.Start) + p.Period - 1) / p.Period * p.Period)
+	}
+	ticks := make([]time.Time, n)
+	for i := range ticks {
+		ticks[i] = first.Add(time.Duration(i) * p.Period)
+	}
+	return ticks
+}
@@ -0,0 +1,23 @@
+package ticker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestPeriodic_NextN(t *testing.T) {
+	start := time.Date(2025, 5, 4, 10, 0, 0, 0, time.UTC)
+	p := Periodic{Start: start, Period: time.Hour}
+
+	assert.That(t,
+		assert.EqualSlices([]time.Time{start, start.Add(time.Hour)},
+			p.NextN(start.Add(-time.Minute), 2)),
+		assert.EqualSlices([]time.Time{start.Add(time.Hour), start.Add(2 * time.Hour)},
+			p.NextN(start.Add(time.Minute), 2)),
+		assert.EqualSlices([]time.Time{start.Add(2 * time.Hour)},
+			p.NextN(start.Add(2*time.Hour), 1)),
+		assert.Equal(0, len(p.NextN(start, 0))),
+		assert.Equal(0, len(Periodic{Start: start}.NextN(start, 3))))
+}
@@ -0,0 +1,103 @@
+package ticker
+
+import (
+	"fmt"
+	"iter"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+type scheduleTickerImpl struct {
+	tickerImpl[time.Time]
+	schedule Schedule
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stop      chan struct{}
+	closed    atomic.Bool
+	next      atomic.Pointer[time.Time]
+}
+
+var (
+	_ Ticker[time.Time] = (*scheduleTickerImpl)(nil)
+	_ Schedulable       = (*scheduleTickerImpl)(nil)
+)
+
+// FromSchedule creates a ticker that ticks at the times of the schedule. The
+// ticker hibernates on a single timer until the next tick time, however far it
+// is, instead of polling the schedule, so that a large number of rarely
+// ticking tickers costs no CPU while idle. The ticker stops when the schedule
+// has no more ticks. The timer is started on the first call to Ticks.
+func FromSchedule(schedule Schedule) Ticker[time.Time] {
+	return &scheduleTickerImpl{schedule: schedule, stop: make(chan struct{})}
+}
+
+func (t *scheduleTickerImpl) Ticks() iter.Seq[time.Time] {
+	ticks := t.tickerImpl.Ticks()
+	if t.closed.Load() {
+		t.tickerImpl.Stop()
+	}
+	t.startOnce.Do(func() {
+		start := time.Now()
+		// The first tick time is known when Ticks returns.
+		if next := t.schedule.NextN(start, 1); len(next) > 0 {
+			t.next.Store(&next[0])
+		}
+		go t.run(start)
+	})
+	return ticks
+}
+
+// run ticks at the schedule times not before from.
+func (t *scheduleTickerImpl) run(from time.Time) {
+	defer t.tickerImpl.Stop()
+	defer t.closed.Store(true)
+	defer t.next.Store(nil)
+	for {
+		next := t.schedule.NextN(from, 1)
+		if len(next) == 0 {
+			return
+		}
+		t.next.Store(&next[0])
+		timer := time.NewTimer(timescale.Scale(time.Until(next[0])))
+		select {
+		case <-t.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		t.Tick(next[0]).Wait()
+		// The same tick is not repeated, if the schedule has a finer
+		// resolution than the clock.
+		from = next[0].Add(time.Nanosecond)
+	}
+}
+
+// Stop cancels the next tick and terminates consumers.
+func (t *scheduleTickerImpl) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+	t.closed.Store(true)
+	t.next.Store(nil)
+	t.tickerImpl.Stop()
+}
+
+// String describes the schedule, if it implements [fmt.Stringer], e.g. "cron
+// 0 9 * * mon".
+func (t *scheduleTickerImpl) String() string {
+	if s, ok := t.schedule.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return "schedule"
+}
+
+// Next returns the time of the next tick, or zero time if no tick is
+// scheduled.
+func (t *scheduleTickerImpl) Next() time.Time {
+	if next := t.next.Load(); next != nil {
+		return *next
+	}
+	return time.Time{}
+}
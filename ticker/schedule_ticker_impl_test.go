@@ -0,0 +1,46 @@
+package ticker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+// listSchedule is the schedule of the listed tick times.
+type listSchedule []time.Time
+
+func (s listSchedule) NextN(from time.Time, n int) []time.Time {
+	var ticks []time.Time
+	for _, tick := range s {
+		if !tick.Before(from) && len(ticks) < n {
+			ticks = append(ticks, tick)
+		}
+	}
+	return ticks
+}
+
+func TestFromSchedule(t *testing.T) {
+	t.Run("ticks at the schedule", func(t *testing.T) {
+		now := time.Now()
+		schedule := listSchedule{now.Add(-time.Millisecond), now.Add(10 * time.Millisecond), now.Add(20 * time.Millisecond)}
+		ticks := slices.Collect(FromSchedule(schedule).Ticks())
+		assert.That(t,
+			assert.EqualSlices([]time.Time{schedule[1], schedule[2]}, ticks),
+			assert.False(time.Now().Before(schedule[2])))
+	})
+
+	t.Run("hibernate and stop", func(t *testing.T) {
+		start := time.Now()
+		ticker := FromSchedule(Periodic{Start: start.Add(7 * 24 * time.Hour), Period: 7 * 24 * time.Hour})
+		ticks := ticker.Ticks()
+		time.AfterFunc(10*time.Millisecond, func() {
+			assert.That(t, assert.Equal(start.Add(7*24*time.Hour), ticker.(Schedulable).Next()))
+			ticker.Stop()
+		})
+		assert.That(t,
+			assert.Equal(0, len(slices.Collect(ticks))),
+			assert.True(ticker.(Schedulable).Next().IsZero()))
+	})
+}
@@ -2,7 +2,6 @@ package ticker
 
 import (
 	"iter"
-
 	"time"
 )
 
@@ -28,6 +27,14 @@ type Waitable interface {
 	Wait()
 }
 
+// Schedulable is implemented by the tickers that know the time of the next
+// tick.
+type Schedulable interface {
+	// Next returns the time of the next tick, or zero time if no tick is
+	// scheduled.
+	Next() time.Time
+}
+
 type Ticker[TickType any] interface {
 	Tickable[TickType]
 	Stoppable
@@ -38,5 +45,6 @@ type TimeTicker interface {
 	Tickable[time.Time]
 	Restartable
 	Waitable
+	Schedulable
 	Reset(time.Duration)
 }
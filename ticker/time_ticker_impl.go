@@ -5,6 +5,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
 )
 
 type timeTickerImpl struct {
@@ -12,8 +14,12 @@ type timeTickerImpl struct {
 	resetCh  chan time.Duration
 	duration atomic.Int64
 
+	// mux serializes the dispatcher loop start and reset.
+	mux     sync.Mutex
 	running atomic.Bool
 	runWg   sync.WaitGroup
+
+	next atomic.Pointer[time.Time]
 }
 
 var _ TimeTicker = (*timeTickerImpl)(nil)
@@ -38,10 +44,19 @@ func (t *timeTickerImpl) Ticks() iter.Seq[time.Time] {
 // Start the loop tick dispatcher loop, if it is not yet running. If called on a
 // stopped, the ticks are restarted with the last non-zero period.
 func (t *timeTickerImpl) Start() {
-	if !t.running.Swap(true) {
-		t.runWg.Add(1)
-		go t.run()
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.start()
+}
+
+// start must be called under the lock.
+func (t *timeTickerImpl) start() {
+	if t.running.Load() || t.duration.Load() == 0 {
+		return
 	}
+	t.running.Store(true)
+	t.runWg.Add(1)
+	go t.run()
 }
 
 // Stop stops the timer and terminates consumers.
@@ -54,28 +69,53 @@ func (t *timeTickerImpl) Stop() {
 // If d == 0, the ticker timer will be stopped. If called on a stopped
 // ticker with d != 0, the ticks are restarted.
 func (t *timeTickerImpl) Reset(d time.Duration) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
 	if d != 0 {
 		// Do not store 0, so that [Start] starts normally.
 		t.duration.Store(int64(d))
 	}
-	select {
-	case t.resetCh <- d:
-		if d == 0 {
-			t.runWg.Wait()
+	if !t.running.Load() {
+		if d != 0 {
+			t.start()
 		}
-	default:
-		t.Start()
+		return
+	}
+	// The running loop always gets back to receiving from the channel.
+	t.resetCh <- d
+	if d == 0 {
+		t.runWg.Wait()
+		t.running.Store(false)
+	} else {
+		t.setNext(time.Now().Add(timescale.Scale(d)))
+	}
+}
+
+// String describes the ticker period, e.g. "every 1m0s".
+func (t *timeTickerImpl) String() string {
+	return "every " + time.Duration(t.duration.Load()).String()
+}
+
+// Next returns the time of the next tick, or zero time if the ticker is not
+// running.
+func (t *timeTickerImpl) Next() time.Time {
+	if next := t.next.Load(); next != nil {
+		return *next
 	}
+	return time.Time{}
+}
+
+func (t *timeTickerImpl) setNext(next time.Time) {
+	t.next.Store(&next)
 }
 
 func (t *timeTickerImpl) run() {
-	defer t.running.Store(false)
 	defer t.runWg.Done()
-	d := time.Duration(t.duration.Load())
-	if d == 0 {
-		return
-	}
-	t.Tick(time.Now())
+	defer t.next.Store(nil)
+	d := timescale.Scale(time.Duration(t.duration.Load()))
+	now := time.Now()
+	t.setNext(now.Add(d))
+	t.Tick(now)
 
 	timer := time.NewTicker(d)
 	defer timer.Stop()
@@ -85,12 +125,14 @@ func (t *timeTickerImpl) run() {
 			if !ok {
 				return
 			}
+			t.setNext(tick.Add(d))
 			t.Tick(tick)
-		case d := <-t.resetCh:
-			if d == 0 {
+		case reset := <-t.resetCh:
+			if reset == 0 {
 				return
 			}
-			timer.Reset(time.Duration(d))
+			d = timescale.Scale(reset)
+			timer.Reset(d)
 		}
 	}
 }
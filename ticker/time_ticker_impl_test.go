@@ -1,6 +1,7 @@
 package ticker
 
 import (
+	"iter"
 	"slices"
 	"sync/atomic"
 	"testing"
@@ -42,3 +43,34 @@ func TestNewTimer(t *testing.T) {
 		t.Errorf("i expected to be %d, got %d", 3, len(times))
 	}
 }
+
+func TestTicker_Next(t *testing.T) {
+	timer := NewTimer(time.Hour)
+	assert.That(t,
+		assert.True(timer.Next().IsZero()))
+
+	ticks := timer.Ticks()
+	next, stop := iter.Pull(ticks)
+	tick, _ := next()
+	assert.That(t,
+		assert.Equal(tick.Add(time.Hour), timer.Next()))
+
+	timer.Reset(time.Minute)
+	assert.That(t,
+		assert.True(timer.Next().Before(tick.Add(time.Hour))))
+
+	stop()
+	timer.Stop()
+	assert.That(t,
+		assert.True(timer.Next().IsZero()))
+}
+
+func TestTicker_StopStopped(t *testing.T) {
+	timer := NewTimer(time.Hour)
+	timer.Start()
+	timer.Stop()
+	timer.Stop()
+	assert.That(t,
+		assert.True(timer.Next().IsZero()),
+		assert.False(timer.(*timeTickerImpl).running.Load()))
+}
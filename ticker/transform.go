@@ -0,0 +1,111 @@
+package ticker
+
+import (
+	"iter"
+	"time"
+)
+
+// TickTransformer transforms a tick, or drops it by returning false.
+type TickTransformer[TickType any] func(TickType) (TickType, bool)
+
+// Transform returns the ticks, transformed by the transformers in order.
+// The built-in transformers are stateful, and should not be shared by
+// sequences.
+//
+// Example:
+//
+//	loop.OnTick(Transform(timer.Ticks(), BusinessHours(), Throttle(time.Minute)), task)
+func Transform[TickType any](ticks iter.Seq[TickType], transformers ...TickTransformer[TickType]) iter.Seq[TickType] {
+	return func(yield func(TickType) bool) {
+	next:
+		for tick := range ticks {
+			for _, transform := range transformers {
+				var ok bool
+				if tick, ok = transform(tick); !ok {
+					continue next
+				}
+			}
+			if !yield(tick) {
+				return
+			}
+		}
+	}
+}
+
+// Throttle drops the ticks that come earlier than d after the last passed tick.
+func Throttle(d time.Duration) TickTransformer[time.Time] {
+	var last time.Time
+	return func(tick time.Time) (time.Time, bool) {
+		if !last.IsZero() && tick.Sub(last) < d {
+			return tick, false
+		}
+		last = tick
+		return tick, true
+	}
+}
+
+// Offset shifts the tick time by d.
+func Offset(d time.Duration) TickTransformer[time.Time] {
+	return func(tick time.Time) (time.Time, bool) {
+		return tick.Add(d), true
+	}
+}
+
+// Dedupe drops the ticks equal to the last passed one.
+func Dedupe[TickType comparable]() TickTransformer[TickType] {
+	var last TickType
+	passed := false
+	return func(tick TickType) (TickType, bool) {
+		if passed && tick == last {
+			return tick, false
+		}
+		last, passed = tick, true
+		return tick, true
+	}
+}
+
+// wallClock returns the wall clock time of day of t, which, unlike the time
+// elapsed since midnight, is not shifted on the daylight saving transition
+// days.
+func wallClock(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute +
+		time.Duration(second)*time.Second + time.Duration(t.Nanosecond())
+}
+
+// DailyWindow passes the ticks with the wall clock time of day, in the tick
+// location, within [start, end). If end < start, the window spans midnight.
+func DailyWindow(start, end time.Duration) TickTransformer[time.Time] {
+	return func(tick time.Time) (time.Time, bool) {
+		sinceMidnight := wallClock(tick)
+		if start <= end {
+			return tick, sinceMidnight >= start && sinceMidnight < end
+		}
+		return tick, sinceMidnight >= start || sinceMidnight < end
+	}
+}
+
+// Weekdays passes the ticks on the given days of the week.
+func Weekdays(days ...time.Weekday) TickTransformer[time.Time] {
+	return func(tick time.Time) (time.Time, bool) {
+		for _, day := range days {
+			if tick.Weekday() == day {
+				return tick, true
+			}
+		}
+		return tick, false
+	}
+}
+
+// BusinessHours passes the ticks from Monday to Friday, 9:00 to 17:00 in the
+// tick location.
+func BusinessHours() TickTransformer[time.Time] {
+	weekdays := Weekdays(time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+	hours := DailyWindow(9*time.Hour, 17*time.Hour)
+	return func(tick time.Time) (time.Time, bool) {
+		if _, ok := weekdays(tick); !ok {
+			return tick, false
+		}
+		return hours(tick)
+	}
+}
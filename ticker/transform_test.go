@@ -0,0 +1,87 @@
+package ticker
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestTransform(t *testing.T) {
+	// Monday.
+	monday := time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return monday.Add(d) }
+
+	t.Run("throttle", func(t *testing.T) {
+		ticks := slices.Values([]time.Time{at(0), at(time.Second), at(time.Minute), at(time.Minute + time.Second)})
+		assert.That(t,
+			assert.EqualSlices([]time.Time{at(0), at(time.Minute)},
+				slices.Collect(Transform(ticks, Throttle(time.Minute)))))
+	})
+
+	t.Run("offset", func(t *testing.T) {
+		ticks := slices.Values([]time.Time{at(0), at(time.Hour)})
+		assert.That(t,
+			assert.EqualSlices([]time.Time{at(time.Minute), at(time.Hour + time.Minute)},
+				slices.Collect(Transform(ticks, Offset(time.Minute)))))
+	})
+
+	t.Run("dedupe", func(t *testing.T) {
+		ticks := slices.Values([]int{1, 1, 2, 2, 2, 1})
+		assert.That(t,
+			assert.EqualSlices([]int{1, 2, 1},
+				slices.Collect(Transform(ticks, Dedupe[int]()))))
+	})
+
+	t.Run("daily window", func(t *testing.T) {
+		ticks := slices.Values([]time.Time{at(time.Hour), at(22 * time.Hour), at(12 * time.Hour)})
+		assert.That(t,
+			assert.EqualSlices([]time.Time{at(time.Hour), at(22 * time.Hour)},
+				slices.Collect(Transform(ticks, DailyWindow(21*time.Hour, 2*time.Hour)))))
+	})
+
+	t.Run("daily window on DST days", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		assert.That(t, assert.NoError(err))
+		// The clocks jump from 2:00 to 3:00 on March 31, and from 3:00 back to
+		// 2:00 on October 27.
+		ticks := slices.Values([]time.Time{
+			time.Date(2024, 3, 31, 8, 30, 0, 0, berlin),
+			time.Date(2024, 3, 31, 9, 30, 0, 0, berlin),
+			time.Date(2024, 10, 27, 9, 30, 0, 0, berlin),
+			time.Date(2024, 10, 27, 10, 30, 0, 0, berlin),
+		})
+		assert.That(t,
+			assert.EqualSlices([]time.Time{
+				time.Date(2024, 3, 31, 9, 30, 0, 0, berlin),
+				time.Date(2024, 10, 27, 9, 30, 0, 0, berlin),
+			}, slices.Collect(Transform(ticks, DailyWindow(9*time.Hour, 10*time.Hour)))))
+	})
+
+	t.Run("business hours", func(t *testing.T) {
+		ticks := slices.Values([]time.Time{
+			at(8 * time.Hour),
+			at(9 * time.Hour),
+			at(17 * time.Hour),
+			at(5*24*time.Hour + 10*time.Hour), // Saturday.
+		})
+		assert.That(t,
+			assert.EqualSlices([]time.Time{at(9 * time.Hour)},
+				slices.Collect(Transform(ticks, BusinessHours()))))
+	})
+
+	t.Run("chain and break", func(t *testing.T) {
+		ticks := slices.Values([]int{1, 1, 2, 3, 3, 4})
+		double := func(tick int) (int, bool) { return tick * 2, true }
+		var result []int
+		for tick := range Transform(ticks, Dedupe[int](), double) {
+			if tick > 6 {
+				break
+			}
+			result = append(result, tick)
+		}
+		assert.That(t,
+			assert.EqualSlices([]int{2, 4, 6}, result))
+	})
+}
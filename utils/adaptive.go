@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// adaptiveDecrease is the multiplicative decrease factor of the limit.
+const adaptiveDecrease = 0.5
+
+// AdaptiveLimiter limits the concurrency of the calls with the additive
+// increase, multiplicative decrease algorithm: the limit grows by one per
+// limit successful calls with the latency within the target, and is halved on
+// an error or a latency spike.
+type AdaptiveLimiter struct {
+	mux      sync.Mutex
+	limit    float64
+	min, max int
+	latency  time.Duration
+	inFlight int
+	// released is closed and replaced when a call finishes.
+	released chan struct{}
+}
+
+// NewAdaptiveLimiter returns a limiter with the limit in [lower, upper],
+// starting from lower, and the target call latency.
+func NewAdaptiveLimiter(lower, upper int, latency time.Duration) *AdaptiveLimiter {
+	lower = max(lower, 1)
+	return &AdaptiveLimiter{
+		limit:    float64(lower),
+		min:      lower,
+		max:      max(upper, lower),
+		latency:  latency,
+		released: make(chan struct{}),
+	}
+}
+
+// Limit returns the current concurrency limit.
+func (l *AdaptiveLimiter) Limit() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of the calls in progress.
+func (l *AdaptiveLimiter) InFlight() int {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	return l.inFlight
+}
+
+func (l *AdaptiveLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mux.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mux.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mux.Unlock()
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, err error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.inFlight--
+	if err != nil || l.latency > 0 && latency > l.latency {
+		l.limit = max(float64(l.min), l.limit*adaptiveDecrease)
+	} else {
+		l.limit = min(float64(l.max), l.limit+1/l.limit)
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// AdaptiveConcurrency limits the concurrent calls of the task with the limiter.
+// It is meant for the work, fanned out by a task on every tick. The calls wait
+// for the limiter, or return the context cause if the context is cancelled.
+// The task panic releases the limiter as a failure.
+func AdaptiveConcurrency[TickType any, Fn Func[TickType]](limiter *AdaptiveLimiter, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("AdaptiveConcurrency", task)
+	return func(ctx context.Context, tick TickType) (err error) {
+		if err := limiter.acquire(ctx); err != nil {
+			return err
+		}
+		start := time.Now()
+		// The error is replaced by the panic, which is propagated.
+		err = ErrPanic
+		defer func() {
+			limiter.release(time.Since(start), err)
+		}()
+		return adaptedTask(ctx, tick)
+	}
+}
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestAdaptiveConcurrency(t *testing.T) {
+	t.Run("increase and decrease", func(t *testing.T) {
+		limiter := NewAdaptiveLimiter(1, 4, time.Second)
+		fail := false
+		task := AdaptiveConcurrency[any](limiter, func() error {
+			if fail {
+				return errors.New("test")
+			}
+			return nil
+		})
+		for range 20 {
+			_ = task(context.Background(), nil)
+		}
+		assert.That(t, assert.Equal(4, limiter.Limit()))
+
+		fail = true
+		_ = task(context.Background(), nil)
+		assert.That(t, assert.Equal(2, limiter.Limit()))
+		for range 5 {
+			_ = task(context.Background(), nil)
+		}
+		assert.That(t,
+			assert.Equal(1, limiter.Limit()),
+			assert.Equal(0, limiter.InFlight()))
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		limiter := NewAdaptiveLimiter(2, 2, 0)
+		task := AdaptiveConcurrency[any](limiter, func() { panic("test") })
+		func() {
+			defer func() { _ = recover() }()
+			_ = task(context.Background(), nil)
+		}()
+		assert.That(t, assert.Equal(0, limiter.InFlight()))
+	})
+
+	t.Run("limit concurrency", func(t *testing.T) {
+		limiter := NewAdaptiveLimiter(2, 2, 0)
+		var mux sync.Mutex
+		running, maxRunning := 0, 0
+		task := AdaptiveConcurrency[any](limiter, func() {
+			mux.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mux.Unlock()
+			time.Sleep(time.Millisecond)
+			mux.Lock()
+			running--
+			mux.Unlock()
+		})
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = task(context.Background(), nil)
+			}()
+		}
+		wg.Wait()
+		assert.That(t, assert.Equal(2, maxRunning))
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		limiter := NewAdaptiveLimiter(1, 1, 0)
+		_ = limiter.acquire(context.Background())
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(ErrStopped)
+		err := AdaptiveConcurrency[any](limiter, func() {})(ctx, nil)
+		assert.That(t, assert.ErrorIs(err, ErrStopped))
+	})
+}
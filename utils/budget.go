@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// ErrBudgetExceeded is wrapped by [BudgetViolation].
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+const heapAllocsMetric = "/gc/heap/allocs:bytes"
+
+// BudgetLimits configures the [Budget] wrapper. Zero values mean no limit.
+type BudgetLimits struct {
+	// Duration is the maximum duration of a single run.
+	Duration time.Duration
+	// Alloc is the maximum number of heap bytes allocated during a single run.
+	// The allocations are measured process-wide, so concurrent activity is
+	// accounted for as well.
+	Alloc uint64
+	// SampleInterval is the period of the resource sampling. Defaults to 10ms.
+	SampleInterval time.Duration
+	// Abort makes the wrapper cancel the task context on violation. Otherwise
+	// the violation is only reported.
+	Abort bool
+}
+
+// BudgetViolation describes a run that exceeded its [BudgetLimits].
+type BudgetViolation struct {
+	Limits   BudgetLimits
+	Duration time.Duration
+	Alloc    uint64
+}
+
+func (v *BudgetViolation) Error() string {
+	return fmt.Sprintf("%s: run took %v and allocated %d bytes", ErrBudgetExceeded, v.Duration, v.Alloc)
+}
+
+func (v *BudgetViolation) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+func (l *BudgetLimits) exceeded(d time.Duration, alloc uint64) bool {
+	return l.Duration > 0 && d > l.Duration || l.Alloc > 0 && alloc > l.Alloc
+}
+
+func readHeapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// Budget samples the run duration and the heap allocations while the task is
+// running, and calls report once per run if the limits are exceeded. With
+// [BudgetLimits.Abort] the task context is cancelled with the
+// [*BudgetViolation] cause, which is also returned if the task returns no
+// error.
+// The enforcement is best effort: the task has to respect the context
+// cancellation, and the allocations are sampled process-wide.
+func Budget[TickType any, Fn Func[TickType]](limits BudgetLimits, report func(*BudgetViolation), task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Budget", task)
+	if limits.SampleInterval <= 0 {
+		limits.SampleInterval = 10 * time.Millisecond
+	}
+	return func(ctx context.Context, tick TickType) error {
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		start := time.Now()
+		startAlloc := readHeapAllocs()
+		var once sync.Once
+		var violation *BudgetViolation
+		check := func() bool {
+			d, alloc := time.Since(start), readHeapAllocs()-startAlloc
+			if !limits.exceeded(d, alloc) {
+				return false
+			}
+			once.Do(func() {
+				violation = &BudgetViolation{limits, d, alloc}
+				if report != nil {
+					report(violation)
+				}
+				if limits.Abort {
+					cancel(violation)
+				}
+			})
+			return true
+		}
+
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sampler := time.NewTicker(timescale.Scale(limits.SampleInterval))
+			defer sampler.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-sampler.C:
+					if check() {
+						return
+					}
+				}
+			}
+		}()
+
+		err := adaptedTask(ctx, tick)
+		close(done)
+		wg.Wait()
+		check()
+		if err == nil && limits.Abort && violation != nil {
+			return violation
+		}
+		return err
+	}
+}
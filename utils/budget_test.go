@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+var sink [][]byte
+
+func TestBudget(t *testing.T) {
+	t.Run("within budget", func(t *testing.T) {
+		var violations []*BudgetViolation
+		err := Budget[any](BudgetLimits{Duration: time.Second, Abort: true},
+			func(v *BudgetViolation) { violations = append(violations, v) },
+			func() {})(context.Background(), nil)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal(0, len(violations)))
+	})
+
+	t.Run("abort on duration", func(t *testing.T) {
+		var violations []*BudgetViolation
+		var cause error
+		err := Budget[any](BudgetLimits{Duration: 10 * time.Millisecond, SampleInterval: time.Millisecond, Abort: true},
+			func(v *BudgetViolation) { violations = append(violations, v) },
+			func(ctx context.Context) {
+				<-ctx.Done()
+				cause = context.Cause(ctx)
+			})(context.Background(), nil)
+
+		var violation *BudgetViolation
+		assert.That(t,
+			assert.ErrorIs(err, ErrBudgetExceeded),
+			assert.ErrorIs(cause, ErrBudgetExceeded),
+			assert.True(errors.As(err, &violation)),
+			assert.True(violation.Duration >= 10*time.Millisecond),
+			assert.Equal(1, len(violations)))
+	})
+
+	t.Run("report allocations", func(t *testing.T) {
+		var violations []*BudgetViolation
+		err := Budget[any](BudgetLimits{Alloc: 1 << 20},
+			func(v *BudgetViolation) { violations = append(violations, v) },
+			func(ctx context.Context) error {
+				for range 4 {
+					sink = append(sink, make([]byte, 1<<20))
+				}
+				sink = nil
+				return ctx.Err()
+			})(context.Background(), nil)
+
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal(1, len(violations)))
+		assert.That(t,
+			assert.True(violations[0].Alloc > 1<<20))
+	})
+}
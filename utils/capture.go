@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// OutputStream identifies the standard output stream, captured by
+// [CaptureOutput].
+type OutputStream int
+
+const (
+	Stdout OutputStream = iota
+	Stderr
+)
+
+func (s OutputStream) String() string {
+	switch s {
+	case Stdout:
+		return "stdout"
+	case Stderr:
+		return "stderr"
+	}
+	return "unknown"
+}
+
+type outputCtxKey struct{}
+
+type output struct {
+	stdout, stderr io.Writer
+}
+
+// sinkWriter passes the written data to the sink.
+type sinkWriter struct {
+	stream OutputStream
+	sink   func(OutputStream, []byte)
+}
+
+func (w sinkWriter) Write(p []byte) (int, error) {
+	w.sink(w.stream, p)
+	return len(p), nil
+}
+
+// OutputFromContext returns the writers of the run output, provided by
+// [CaptureOutput], or [os.Stdout] and [os.Stderr] if there are none.
+func OutputFromContext(ctx context.Context) (stdout, stderr io.Writer) {
+	if o, ok := ctx.Value(outputCtxKey{}).(*output); ok {
+		return o.stdout, o.stderr
+	}
+	return os.Stdout, os.Stderr
+}
+
+// CaptureOutput passes the output of the task runs to the sink, so that the
+// output is attributed to the right task. The data, passed to the sink, must
+// not be retained.
+//
+// The tasks write the output to the writers of the run, provided with
+// [OutputFromContext], e.g. passing them to the commands they start. The
+// process [os.Stdout] and [os.Stderr] are not redirected, as replacing them
+// would race with the other goroutines, writing to them.
+func CaptureOutput[TickType any, Fn Func[TickType]](sink func(OutputStream, []byte), task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("CaptureOutput", task)
+	return func(ctx context.Context, tick TickType) error {
+		ctx = context.WithValue(ctx, outputCtxKey{}, &output{
+			stdout: sinkWriter{Stdout, sink},
+			stderr: sinkWriter{Stderr, sink},
+		})
+		return adaptedTask(ctx, tick)
+	}
+}
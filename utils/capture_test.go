@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestCaptureOutput(t *testing.T) {
+	var mux sync.Mutex
+	captured := map[OutputStream]*strings.Builder{Stdout: {}, Stderr: {}}
+	sink := func(stream OutputStream, data []byte) {
+		mux.Lock()
+		defer mux.Unlock()
+		captured[stream].Write(data)
+	}
+	stdout := os.Stdout
+	err := CaptureOutput[any](sink, func(ctx context.Context) {
+		out, errW := OutputFromContext(ctx)
+		fmt.Fprintln(out, "output")
+		fmt.Fprint(errW, "error")
+		fmt.Fprint(out, "context")
+	})(context.Background(), nil)
+
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal(stdout, os.Stdout),
+		assert.Equal("output\ncontext", captured[Stdout].String()),
+		assert.Equal("error", captured[Stderr].String()))
+
+	out, errW := OutputFromContext(context.Background())
+	assert.That(t,
+		assert.Equal[any](os.Stdout, out),
+		assert.Equal[any](os.Stderr, errW))
+}
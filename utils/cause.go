@@ -0,0 +1,57 @@
+package utils
+
+import "context"
+
+// RunCause tells how a task run has been invoked.
+type RunCause int
+
+const (
+	// RunCauseTick is the cause of the runs on the scheduled ticks, and the
+	// default for the contexts without a cause.
+	RunCauseTick RunCause = iota
+	// RunCauseStart is the cause of the first run after the task start.
+	RunCauseStart
+	// RunCauseManual is the cause of the runs triggered manually, e.g. by an
+	// explicit call of the task function with a context from [WithRunCause].
+	RunCauseManual
+	// RunCauseRetry is the cause of the repeated attempts of [Retry].
+	RunCauseRetry
+	// RunCauseReplay is the cause of the runs on the replayed or backfilled
+	// ticks.
+	RunCauseReplay
+	// RunCauseFinal is the cause of the last run on the task stop, e.g. to
+	// flush the buffered state.
+	RunCauseFinal
+)
+
+func (c RunCause) String() string {
+	switch c {
+	case RunCauseTick:
+		return "tick"
+	case RunCauseStart:
+		return "start"
+	case RunCauseManual:
+		return "manual"
+	case RunCauseRetry:
+		return "retry"
+	case RunCauseReplay:
+		return "replay"
+	case RunCauseFinal:
+		return "final"
+	}
+	return "unknown"
+}
+
+type runCauseCtxKey struct{}
+
+// WithRunCause returns a copy of the context, carrying the run cause.
+func WithRunCause(ctx context.Context, cause RunCause) context.Context {
+	return context.WithValue(ctx, runCauseCtxKey{}, cause)
+}
+
+// RunCauseFromContext returns the run cause, carried by the context, or
+// [RunCauseTick] if there is none.
+func RunCauseFromContext(ctx context.Context) RunCause {
+	cause, _ := ctx.Value(runCauseCtxKey{}).(RunCause)
+	return cause
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestRunCause(t *testing.T) {
+	ctx := context.Background()
+	assert.That(t,
+		assert.Equal(RunCauseTick, RunCauseFromContext(ctx)),
+		assert.Equal(RunCauseManual, RunCauseFromContext(WithRunCause(ctx, RunCauseManual))),
+		assert.Equal("replay", RunCauseReplay.String()))
+
+	var causes []RunCause
+	_ = Retry[any](SimpleRetryPolicy(3), func(ctx context.Context) error {
+		causes = append(causes, RunCauseFromContext(ctx))
+		return errors.New("failed")
+	})(WithRunCause(ctx, RunCauseStart), nil)
+	assert.That(t, assert.EqualSlices([]RunCause{RunCauseStart, RunCauseRetry, RunCauseRetry}, causes))
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
 )
 
 func ExampleLog() {
@@ -35,3 +36,42 @@ func ExampleAdapt() {
 	// hello
 	// error
 }
+
+// This example retries the task, every attempt of which is limited by the
+// timeout.
+func ExampleRetry() {
+	attempt := 0
+	f := Retry[any](SimpleRetryPolicy(3),
+		Timeout[any](time.Second,
+			func(ctx context.Context) error {
+				attempt++
+				fmt.Println("attempt", attempt, "on", RunCauseFromContext(ctx))
+				if attempt < 3 {
+					return errors.New("unavailable")
+				}
+				return nil
+			}))
+
+	fmt.Println("Error:", f(context.Background(), nil))
+
+	// Output:
+	// attempt 1 on tick
+	// attempt 2 on retry
+	// attempt 3 on retry
+	// Error: <nil>
+}
+
+// This example stops the task on the errors, which are not worth retrying.
+func ExampleStopOn() {
+	errForbidden := errors.New("forbidden")
+	f := StopOn[any](func(err error) bool { return errors.Is(err, errForbidden) },
+		func() error {
+			return errForbidden
+		})
+
+	err := f(context.Background(), nil)
+	fmt.Println(err, errors.Is(err, ErrStopped))
+
+	// Output:
+	// stopped: forbidden true
+}
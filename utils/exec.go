@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// execWaitDelay bounds the wait for the output of the command after it exits,
+// e.g. when it has been killed, but its children still hold the output.
+const execWaitDelay = time.Second
+
+// Exec returns a task that runs the command, created by the factory on every
+// tick, and waits for it to exit. The command is killed when the context is
+// cancelled, e.g. by [Timeout], in which case the context cause is returned.
+// The output of the command children, which outlive it, is waited for up to a
+// second, unless the factory sets the command WaitDelay.
+// The command output is written to outW and errW, unless the factory sets the
+// command Stdout or Stderr. If outW or errW is nil, the writers of
+// [OutputFromContext] are used, e.g. of [CaptureOutput].
+func Exec[TickType any](command func(TickType) *exec.Cmd, outW, errW io.Writer) func(context.Context, TickType) error {
+	return func(ctx context.Context, tick TickType) error {
+		cmd := command(tick)
+		stdout, stderr := OutputFromContext(ctx)
+		if cmd.Stdout == nil {
+			cmd.Stdout = stdout
+			if outW != nil {
+				cmd.Stdout = outW
+			}
+		}
+		if cmd.Stderr == nil {
+			cmd.Stderr = stderr
+			if errW != nil {
+				cmd.Stderr = errW
+			}
+		}
+		if cmd.WaitDelay == 0 {
+			cmd.WaitDelay = execWaitDelay
+		}
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- cmd.Wait()
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			_ = cmd.Process.Kill()
+			<-done
+			return context.Cause(ctx)
+		}
+	}
+}
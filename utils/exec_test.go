@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestExec(t *testing.T) {
+	t.Run("output", func(t *testing.T) {
+		var out, errOut bytes.Buffer
+		err := Exec(func(tick string) *exec.Cmd {
+			return exec.Command("sh", "-c", "echo "+tick+"; echo error >&2")
+		}, &out, &errOut)(context.Background(), "hello")
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal("hello\n", out.String()),
+			assert.Equal("error\n", errOut.String()))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		err := Exec(func(any) *exec.Cmd {
+			return exec.Command("false")
+		}, nil, nil)(context.Background(), nil)
+		assert.That(t, assert.Not(assert.NoError(err)))
+	})
+
+	t.Run("kill on timeout", func(t *testing.T) {
+		start := time.Now()
+		err := Timeout[any](10*time.Millisecond, Exec(func(any) *exec.Cmd {
+			return exec.Command("sleep", "10")
+		}, nil, nil))(context.Background(), nil)
+		assert.That(t,
+			assert.ErrorIs(err, ErrTimeout),
+			assert.True(time.Since(start) < 5*time.Second))
+	})
+
+	t.Run("kill with children", func(t *testing.T) {
+		start := time.Now()
+		err := Timeout[any](100*time.Millisecond, Exec(func(any) *exec.Cmd {
+			return exec.Command("sh", "-c", "sleep 3; echo late")
+		}, &bytes.Buffer{}, nil))(context.Background(), nil)
+		assert.That(t,
+			assert.ErrorIs(err, ErrTimeout),
+			assert.True(time.Since(start) < 2*time.Second))
+	})
+
+	t.Run("captured output", func(t *testing.T) {
+		var out bytes.Buffer
+		err := CaptureOutput[any](func(stream OutputStream, p []byte) {
+			if stream == Stdout {
+				out.Write(p)
+			}
+		}, Exec(func(any) *exec.Cmd {
+			return exec.Command("echo", "hello")
+		}, nil, nil))(context.Background(), nil)
+		assert.That(t,
+			assert.NoError(err),
+			assert.Equal("hello\n", out.String()))
+	})
+}
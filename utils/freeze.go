@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// SkipReasonFrozen is the reason of the runs skipped by [FreezeGuard] with
+// [FreezeSkip].
+const SkipReasonFrozen = "frozen"
+
+// FreezePolicy defines what [FreezeGuard] does on the first tick after a
+// freeze.
+type FreezePolicy int
+
+const (
+	// FreezeRun runs the task as usual, only reporting the freeze.
+	FreezeRun FreezePolicy = iota
+	// FreezeSkip skips the run, reporting [SkipReasonFrozen].
+	FreezeSkip
+	// FreezeCatchUp runs the task for every tick, missed during the freeze,
+	// before the current one, but for not more than the latest
+	// [FreezeCatchUpLimit] ticks.
+	FreezeCatchUp
+)
+
+// FreezeCatchUpLimit is the maximum number of the missed ticks, which
+// [FreezeGuard] runs the task for with [FreezeCatchUp].
+const FreezeCatchUpLimit = 100
+
+// FreezeGuard detects the wall-clock gaps between the runs, longer than the
+// period by more than the threshold, e.g. when the process has been paused by
+// a debugger, SIGSTOP or a container freeze, calls onFreeze, if not nil, with
+// the gap, and handles the first tick after the gap according to the policy.
+// The gap is measured from the end of the previous run, so that the slow runs
+// are not taken for freezes, and not across a task restart, i.e. the first run
+// after the task start, see [RunCauseStart], is never taken for a freeze.
+func FreezeGuard[Fn Func[time.Time]](period, threshold time.Duration, policy FreezePolicy, onFreeze func(time.Duration), task Fn) func(context.Context, time.Time) error {
+	adaptedTask := adaptIn[time.Time]("FreezeGuard", task)
+	var mux sync.Mutex
+	var lastTick, lastFinished time.Time
+	return func(ctx context.Context, tick time.Time) error {
+		period := timescale.Scale(period)
+		mux.Lock()
+		if RunCauseFromContext(ctx) == RunCauseStart {
+			lastTick, lastFinished = time.Time{}, time.Time{}
+		}
+		previous := lastTick
+		gap := time.Since(lastFinished)
+		frozen := !lastFinished.IsZero() && gap > period+timescale.Scale(threshold)
+		lastTick = tick
+		mux.Unlock()
+		defer func() {
+			mux.Lock()
+			defer mux.Unlock()
+			lastFinished = time.Now()
+		}()
+
+		if !frozen {
+			return adaptedTask(ctx, tick)
+		}
+		if onFreeze != nil {
+			onFreeze(gap)
+		}
+		switch policy {
+		case FreezeSkip:
+			Skip(ctx, SkipReasonFrozen)
+			return nil
+		case FreezeCatchUp:
+			if period <= 0 || !tick.After(previous) {
+				break
+			}
+			n := int((tick.Sub(previous) - 1) / period)
+			for i := max(n-FreezeCatchUpLimit+1, 1); i <= n; i++ {
+				if err := adaptedTask(ctx, previous.Add(time.Duration(i)*period)); err != nil {
+					return err
+				}
+			}
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
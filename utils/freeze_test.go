@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestFreezeGuard(t *testing.T) {
+	period := 10 * time.Millisecond
+	start := time.Now()
+
+	t.Run("catch up", func(t *testing.T) {
+		var ticks []time.Time
+		var gaps []time.Duration
+		task := FreezeGuard(period, period, FreezeCatchUp,
+			func(gap time.Duration) { gaps = append(gaps, gap) },
+			func(_ context.Context, tick time.Time) error {
+				ticks = append(ticks, tick)
+				return nil
+			})
+		assert.That(t, assert.NoError(task(context.Background(), start)))
+		time.Sleep(5 * period)
+		assert.That(t, assert.NoError(task(context.Background(), start.Add(4*period))))
+		assert.That(t,
+			assert.Equal(1, len(gaps)),
+			assert.EqualSlices([]time.Time{
+				start, start.Add(period), start.Add(2 * period), start.Add(3 * period), start.Add(4 * period),
+			}, ticks))
+	})
+
+	t.Run("catch up limit", func(t *testing.T) {
+		var ticks []time.Time
+		task := FreezeGuard(period, period, FreezeCatchUp, nil,
+			func(_ context.Context, tick time.Time) error {
+				ticks = append(ticks, tick)
+				return nil
+			})
+		_ = task(context.Background(), start)
+		time.Sleep(3 * period)
+		last := start.Add(1000 * period)
+		_ = task(context.Background(), last)
+		assert.That(t,
+			assert.Equal(FreezeCatchUpLimit+2, len(ticks)),
+			assert.Equal(last.Add(-FreezeCatchUpLimit*period), ticks[1]),
+			assert.Equal(last, ticks[len(ticks)-1]))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		var results []RunResult
+		task := Classify[time.Time](func(r RunResult) { results = append(results, r) },
+			FreezeGuard(period, period, FreezeSkip, nil, func() {}))
+		_ = task(context.Background(), start)
+		_ = task(context.Background(), start.Add(period))
+		time.Sleep(3 * period)
+		_ = task(context.Background(), start.Add(2*period))
+		assert.That(t, assert.EqualSlices([]RunResult{
+			{RunExecuted, "", nil},
+			{RunExecuted, "", nil},
+			{RunSkipped, SkipReasonFrozen, nil},
+		}, results))
+	})
+
+	t.Run("restart", func(t *testing.T) {
+		var gaps []time.Duration
+		var ticks []time.Time
+		task := FreezeGuard(period, period, FreezeCatchUp,
+			func(gap time.Duration) { gaps = append(gaps, gap) },
+			func(_ context.Context, tick time.Time) error {
+				ticks = append(ticks, tick)
+				return nil
+			})
+		_ = task(context.Background(), start)
+		time.Sleep(3 * period)
+		_ = task(WithRunCause(context.Background(), RunCauseStart), start.Add(10*period))
+		assert.That(t,
+			assert.Equal(0, len(gaps)),
+			assert.EqualSlices([]time.Time{start, start.Add(10 * period)}, ticks))
+	})
+}
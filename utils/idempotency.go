@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SkipReasonDuplicate is the reason of the runs skipped by [Idempotent].
+const SkipReasonDuplicate = "duplicate"
+
+// KeyStore records the idempotency keys of the executed runs.
+type KeyStore interface {
+	// Add records the key, and returns false if it has been recorded before.
+	Add(ctx context.Context, key string) (bool, error)
+}
+
+// MemoryKeyStore is an in-memory [KeyStore].
+type MemoryKeyStore struct {
+	mux  sync.Mutex
+	keys map[string]struct{}
+}
+
+func (s *MemoryKeyStore) Add(_ context.Context, key string) (bool, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.has(key) {
+		return false, nil
+	}
+	s.add(key)
+	return true, nil
+}
+
+// has tells whether the key has been recorded. Must be called under the lock.
+func (s *MemoryKeyStore) has(key string) bool {
+	_, ok := s.keys[key]
+	return ok
+}
+
+// add records the key. Must be called under the lock.
+func (s *MemoryKeyStore) add(key string) {
+	if s.keys == nil {
+		s.keys = make(map[string]struct{})
+	}
+	s.keys[key] = struct{}{}
+}
+
+// FileKeyStore is a [KeyStore], that appends the keys to a file, one per line,
+// so that they survive restarts. The keys and the file are guarded by the lock
+// of the embedded MemoryKeyStore.
+type FileKeyStore struct {
+	MemoryKeyStore
+	file *os.File
+}
+
+// OpenFileKeyStore opens or creates the file, and loads the recorded keys.
+func OpenFileKeyStore(path string) (*FileKeyStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileKeyStore{file: file}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		s.add(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileKeyStore) Add(_ context.Context, key string) (bool, error) {
+	if strings.ContainsAny(key, "\r\n") {
+		return false, errors.New("the key contains a line break")
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.has(key) {
+		return false, nil
+	}
+	if _, err := s.file.WriteString(key + "\n"); err != nil {
+		return false, err
+	}
+	s.add(key)
+	return true, s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileKeyStore) Close() error {
+	return s.file.Close()
+}
+
+// Idempotent records the key of every tick in the store before the run, and
+// skips the runs of the ticks, which keys have been recorded before, reporting
+// [SkipReasonDuplicate]. As the key is recorded before the run, the ticks are
+// processed at most once, even if the run fails. The store error fails the
+// run.
+func Idempotent[TickType any, Fn Func[TickType]](key func(TickType) string, store KeyStore, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Idempotent", task)
+	return func(ctx context.Context, tick TickType) error {
+		added, err := store.Add(ctx, key(tick))
+		if err != nil {
+			return err
+		}
+		if !added {
+			Skip(ctx, SkipReasonDuplicate)
+			return nil
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
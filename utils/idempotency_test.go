@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	store, err := OpenFileKeyStore(path)
+	assert.That(t, assert.NoError(err))
+
+	var processed []int
+	var results []RunResult
+	task := Classify[int](func(r RunResult) { results = append(results, r) },
+		Idempotent(strconv.Itoa, store, func(_ context.Context, tick int) error {
+			processed = append(processed, tick)
+			return nil
+		}))
+	for _, tick := range []int{1, 2, 1} {
+		assert.That(t, assert.NoError(task(context.Background(), tick)))
+	}
+	assert.That(t,
+		assert.EqualSlices([]int{1, 2}, processed),
+		assert.Equal(RunResult{RunSkipped, SkipReasonDuplicate, nil}, results[2]),
+		assert.NoError(store.Close()))
+
+	// The keys survive the reopening.
+	store, err = OpenFileKeyStore(path)
+	assert.That(t, assert.NoError(err))
+	defer store.Close()
+	added, err := store.Add(context.Background(), "2")
+	assert.That(t, assert.NoError(err), assert.False(added))
+	added, err = store.Add(context.Background(), "3")
+	assert.That(t, assert.NoError(err), assert.True(added))
+	_, err = store.Add(context.Background(), "a\nb")
+	assert.That(t, assert.Not(assert.NoError(err)))
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InFlightRun describes a run in progress, tracked by [TrackInFlight].
+type InFlightRun struct {
+	Task  string
+	ID    uint64
+	Start time.Time
+	// goroutine is the ID of the goroutine, executing the run.
+	goroutine string
+}
+
+var (
+	inFlightRuns  sync.Map // ID -> *InFlightRun
+	inFlightRunID atomic.Uint64
+)
+
+// goroutineID returns the ID of the current goroutine, parsed from its stack
+// header.
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	fields := bytes.Fields(buf)
+	if len(fields) < 2 {
+		return ""
+	}
+	return string(fields[1])
+}
+
+// TrackInFlight registers the task runs under the name while they are in
+// progress, for [InFlight] and [DumpInFlight]. The runs are also labeled with
+// the task name and the run ID for the profiler, see [pprof.Do].
+func TrackInFlight[TickType any, Fn Func[TickType]](name string, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("TrackInFlight", task)
+	return func(ctx context.Context, tick TickType) error {
+		run := &InFlightRun{
+			Task:      name,
+			ID:        inFlightRunID.Add(1),
+			Start:     time.Now(),
+			goroutine: goroutineID(),
+		}
+		inFlightRuns.Store(run.ID, run)
+		defer inFlightRuns.Delete(run.ID)
+		var err error
+		pprof.Do(ctx, pprof.Labels("task", name, "run", strconv.FormatUint(run.ID, 10)), func(ctx context.Context) {
+			err = adaptedTask(ctx, tick)
+		})
+		return err
+	}
+}
+
+// InFlight returns the tracked runs in progress, the oldest first.
+func InFlight() []InFlightRun {
+	var runs []InFlightRun
+	inFlightRuns.Range(func(_, value any) bool {
+		runs = append(runs, *value.(*InFlightRun))
+		return true
+	})
+	slices.SortFunc(runs, func(a, b InFlightRun) int { return a.Start.Compare(b.Start) })
+	return runs
+}
+
+// allStacks returns the stacks of all goroutines by their IDs.
+func allStacks() map[string][]byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[string][]byte)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if fields := bytes.Fields(stack); len(fields) > 1 {
+			stacks[string(fields[1])] = stack
+		}
+	}
+	return stacks
+}
+
+// DumpInFlight writes the tracked runs in progress with their ages and the
+// stacks of the goroutines, executing them, e.g. to find the stuck runs when
+// waiting for the tasks hangs.
+func DumpInFlight(w io.Writer) error {
+	runs := InFlight()
+	if len(runs) == 0 {
+		return nil
+	}
+	stacks := allStacks()
+	for _, run := range runs {
+		if _, err := fmt.Fprintf(w, "task %s run #%d running for %v\n%s\n\n",
+			run.Task, run.ID, time.Since(run.Start).Round(time.Millisecond), stacks[run.goroutine]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
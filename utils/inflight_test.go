@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func stuckInTest(release chan struct{}) {
+	<-release
+}
+
+func TestTrackInFlight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- TrackInFlight[any]("stuck", func() {
+			close(started)
+			stuckInTest(release)
+		})(context.Background(), nil)
+	}()
+	<-started
+
+	runs := InFlight()
+	var b strings.Builder
+	assert.That(t,
+		assert.Equal(1, len(runs)),
+		assert.Equal("stuck", runs[0].Task),
+		assert.NoError(DumpInFlight(&b)))
+	assert.That(t,
+		assert.True(strings.HasPrefix(b.String(), "task stuck run #")),
+		assert.True(strings.Contains(b.String(), "utils.stuckInTest")))
+
+	close(release)
+	assert.That(t,
+		assert.NoError(<-done),
+		assert.Equal(0, len(InFlight())))
+}
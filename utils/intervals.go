@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultIntervalWindow is the number of the recent intervals, kept by
+// [IntervalStats] for the percentiles, if the window is not set.
+const DefaultIntervalWindow = 100
+
+// IntervalStats accounts the intervals between the run starts, measured by
+// [TrackIntervals], to compare them to the configured period.
+type IntervalStats struct {
+	// Period is the configured period of the task, for the reference.
+	Period time.Duration
+	// Window is the number of the recent intervals, used for the percentiles.
+	Window int
+
+	mux    sync.Mutex
+	count  int
+	sum    time.Duration
+	max    time.Duration
+	recent []time.Duration
+	next   int
+}
+
+// IntervalSummary is a snapshot of [IntervalStats].
+type IntervalSummary struct {
+	Period time.Duration
+	// Count is the number of the accounted intervals.
+	Count int
+	// Sum is the total of the accounted intervals.
+	Sum time.Duration
+	// Mean and Max are computed over all the accounted intervals, and P95
+	// over the recent window.
+	Mean time.Duration
+	P95  time.Duration
+	Max  time.Duration
+}
+
+// Overrunning tells whether the mean interval exceeds the period by more than
+// the tolerance fraction, e.g. 0.1 for 10%.
+func (s IntervalSummary) Overrunning(tolerance float64) bool {
+	return s.Period > 0 && s.Count > 0 &&
+		float64(s.Mean) > float64(s.Period)*(1+tolerance)
+}
+
+// Add accounts the interval.
+func (s *IntervalStats) Add(interval time.Duration) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.count++
+	s.sum += interval
+	s.max = max(s.max, interval)
+	window := s.Window
+	if window <= 0 {
+		window = DefaultIntervalWindow
+	}
+	if len(s.recent) < window {
+		s.recent = append(s.recent, interval)
+		return
+	}
+	s.recent[s.next%len(s.recent)] = interval
+	s.next++
+}
+
+// Reset drops the accounted intervals.
+func (s *IntervalStats) Reset() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.count, s.sum, s.max = 0, 0, 0
+	s.recent, s.next = nil, 0
+}
+
+// Summary returns the snapshot of the stats.
+func (s *IntervalStats) Summary() IntervalSummary {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	summary := IntervalSummary{Period: s.Period, Count: s.count, Sum: s.sum, Max: s.max}
+	if s.count == 0 {
+		return summary
+	}
+	summary.Mean = s.sum / time.Duration(s.count)
+	recent := slices.Clone(s.recent)
+	slices.Sort(recent)
+	summary.P95 = recent[(len(recent)*95+99)/100-1]
+	return summary
+}
+
+// TrackIntervals accounts the intervals between the starts of the task runs in
+// the stats.
+func TrackIntervals[TickType any, Fn Func[TickType]](stats *IntervalStats, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("TrackIntervals", task)
+	var mux sync.Mutex
+	var lastStart time.Time
+	return func(ctx context.Context, tick TickType) error {
+		start := time.Now()
+		mux.Lock()
+		if !lastStart.IsZero() {
+			stats.Add(start.Sub(lastStart))
+		}
+		lastStart = start
+		mux.Unlock()
+		return adaptedTask(ctx, tick)
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestIntervalStats(t *testing.T) {
+	stats := &IntervalStats{Period: 10 * time.Millisecond, Window: 20}
+	assert.That(t, assert.Equal(IntervalSummary{Period: 10 * time.Millisecond}, stats.Summary()))
+	for i := 1; i <= 20; i++ {
+		stats.Add(time.Duration(i) * time.Millisecond)
+	}
+	summary := stats.Summary()
+	assert.That(t,
+		assert.Equal(20, summary.Count),
+		assert.Equal(10500*time.Microsecond, summary.Mean),
+		assert.Equal(19*time.Millisecond, summary.P95),
+		assert.Equal(20*time.Millisecond, summary.Max),
+		assert.False(summary.Overrunning(0.1)),
+		assert.True(summary.Overrunning(0)))
+
+	for range 20 {
+		stats.Add(time.Millisecond)
+	}
+	summary = stats.Summary()
+	assert.That(t,
+		assert.Equal(time.Millisecond, summary.P95),
+		assert.Equal(20*time.Millisecond, summary.Max))
+}
+
+func TestTrackIntervals(t *testing.T) {
+	stats := &IntervalStats{}
+	task := TrackIntervals[any](stats, func() {})
+	for range 3 {
+		assert.That(t, assert.NoError(task(context.Background(), nil)))
+		time.Sleep(time.Millisecond)
+	}
+	summary := stats.Summary()
+	assert.That(t,
+		assert.Equal(2, summary.Count),
+		assert.True(summary.Mean >= time.Millisecond))
+}
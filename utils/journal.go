@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a line of the [Journal].
+type JournalEntry struct {
+	Time time.Time `json:"time"`
+	Task string    `json:"task,omitempty"`
+	// Event is "start" or "end".
+	Event string `json:"event"`
+	// Run is the number of the run in the journal, the same for its start and
+	// end entries.
+	Run uint64 `json:"run"`
+	// Seq is the tick sequence number, see [Sequence].
+	Seq uint64 `json:"seq,omitempty"`
+	// The following fields are set on the end of the run.
+	Outcome  string        `json:"outcome,omitempty"`
+	Reason   string        `json:"reason,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Journal is an append-only log of the task runs, written as JSON lines of
+// [JournalEntry], independently of the logging, e.g. for the audit. It may be
+// shared by multiple tasks.
+type Journal struct {
+	mux    sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	runs   uint64
+	err    error
+}
+
+// NewJournal returns the journal, writing to w.
+func NewJournal(w io.Writer) *Journal {
+	return &Journal{w: w}
+}
+
+// OpenJournal opens or creates the journal file for appending.
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{w: file, closer: file}, nil
+}
+
+// Close closes the journal file, opened with [OpenJournal].
+func (j *Journal) Close() error {
+	if j.closer == nil {
+		return nil
+	}
+	return j.closer.Close()
+}
+
+// Err returns the first write error. The entries are not written after it.
+func (j *Journal) Err() error {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	return j.err
+}
+
+func (j *Journal) nextRun() uint64 {
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	j.runs++
+	return j.runs
+}
+
+func (j *Journal) write(entry JournalEntry) {
+	line, err := json.Marshal(entry)
+	j.mux.Lock()
+	defer j.mux.Unlock()
+	if j.err != nil {
+		return
+	}
+	if err == nil {
+		_, err = j.w.Write(append(line, '\n'))
+	}
+	j.err = err
+}
+
+// Journaled records the start and the end of every run of the named task in
+// the journal, with the outcome as reported by [Classify].
+func Journaled[TickType any, Fn Func[TickType]](j *Journal, name string, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Journaled", task)
+	return func(ctx context.Context, tick TickType) error {
+		seq, _ := TickSeqFromContext(ctx)
+		entry := JournalEntry{Time: time.Now(), Task: name, Event: "start", Run: j.nextRun(), Seq: seq}
+		j.write(entry)
+		ctx, recorder := withSkipRecorder(ctx)
+		err := adaptedTask(ctx, tick)
+		entry.Event = "end"
+		entry.Duration = time.Since(entry.Time)
+		entry.Time = time.Now()
+		switch skipped, reason := recorder.finish(err); {
+		case skipped:
+			entry.Outcome = RunSkipped.String()
+			entry.Reason = reason
+		case err != nil:
+			entry.Outcome = RunFailed.String()
+			entry.Error = err.Error()
+		default:
+			entry.Outcome = RunExecuted.String()
+		}
+		j.write(entry)
+		return err
+	}
+}
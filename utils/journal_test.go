@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestJournaled(t *testing.T) {
+	var b strings.Builder
+	j := NewJournal(&b)
+	seq := &TickSequence{}
+	task := Sequence[int](seq, Journaled[int](j, "sync", When[int](func(_ context.Context, tick int) bool { return tick != 1 }, "one",
+		func(tick int) error {
+			if tick == 2 {
+				return errors.New("failed")
+			}
+			return nil
+		})))
+	for tick := range 3 {
+		_ = task(context.Background(), tick)
+	}
+	assert.That(t, assert.NoError(j.Err()))
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(strings.NewReader(b.String()))
+	for scanner.Scan() {
+		var entry JournalEntry
+		assert.That(t, assert.NoError(json.Unmarshal(scanner.Bytes(), &entry)))
+		entries = append(entries, entry)
+	}
+	assert.That(t, assert.Equal(6, len(entries)))
+	var events, outcomes []string
+	for _, entry := range entries {
+		events = append(events, entry.Event)
+		outcomes = append(outcomes, entry.Outcome+entry.Reason+entry.Error)
+	}
+	assert.That(t,
+		assert.EqualSlices([]string{"start", "end", "start", "end", "start", "end"}, events),
+		assert.EqualSlices([]string{"", "executed", "", "skippedone", "", "failedfailed"}, outcomes),
+		assert.Equal("sync", entries[5].Task),
+		assert.Equal(uint64(3), entries[5].Run),
+		assert.Equal(uint64(3), entries[5].Seq))
+}
+
+func TestOpenJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	for range 2 {
+		j, err := OpenJournal(path)
+		assert.That(t, assert.NoError(err))
+		_ = Journaled[any](j, "task", func() {})(context.Background(), nil)
+		assert.That(t, assert.NoError(j.Close()))
+	}
+	data, err := os.ReadFile(path)
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal(4, strings.Count(string(data), "\n")))
+}
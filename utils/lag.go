@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LagStats accounts the scheduler lags, detected by [DetectLag]: the delays
+// of the tick receipt, not caused by the previous run.
+type LagStats struct {
+	// Threshold is the minimal accounted lag.
+	Threshold time.Duration
+	// OnLag, if not nil, is called with every accounted lag.
+	OnLag func(time.Duration)
+
+	mux   sync.Mutex
+	count int
+	max   time.Duration
+	last  time.Time
+}
+
+// Add accounts the lag if it reaches the threshold.
+func (s *LagStats) Add(lag time.Duration) {
+	if lag < s.Threshold || lag <= 0 {
+		return
+	}
+	s.mux.Lock()
+	s.count++
+	s.max = max(s.max, lag)
+	s.last = time.Now()
+	s.mux.Unlock()
+	if s.OnLag != nil {
+		s.OnLag(lag)
+	}
+}
+
+// Count returns the number of the accounted lags.
+func (s *LagStats) Count() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.count
+}
+
+// Max returns the maximal accounted lag.
+func (s *LagStats) Max() time.Duration {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.max
+}
+
+// Last returns the time of the last accounted lag, or zero time.
+func (s *LagStats) Last() time.Time {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.last
+}
+
+// DetectLag measures the delay between the tick time and the run start, e.g.
+// due to GC or CPU starvation, and accounts it in the stats. The time the
+// previous run of the wrapper took after the tick is not counted, so that the
+// scheduler lag is distinguished from the slow runs.
+func DetectLag[Fn Func[time.Time]](stats *LagStats, task Fn) func(context.Context, time.Time) error {
+	adaptedTask := adaptIn[time.Time]("DetectLag", task)
+	var mux sync.Mutex
+	var lastFinished time.Time
+	return func(ctx context.Context, tick time.Time) error {
+		start := time.Now()
+		mux.Lock()
+		due := tick
+		if lastFinished.After(due) {
+			due = lastFinished
+		}
+		mux.Unlock()
+		stats.Add(start.Sub(due))
+		defer func() {
+			mux.Lock()
+			defer mux.Unlock()
+			lastFinished = time.Now()
+		}()
+		return adaptedTask(ctx, tick)
+	}
+}
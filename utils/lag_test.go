@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestDetectLag(t *testing.T) {
+	var lags []time.Duration
+	stats := &LagStats{
+		Threshold: 50 * time.Millisecond,
+		OnLag:     func(lag time.Duration) { lags = append(lags, lag) },
+	}
+	task := DetectLag(stats, func() {})
+	now := time.Now()
+
+	assert.That(t,
+		assert.NoError(task(context.Background(), now.Add(-time.Millisecond))),
+		assert.Equal(0, stats.Count()))
+
+	assert.That(t,
+		assert.NoError(task(context.Background(), now.Add(-time.Minute))),
+		assert.Equal(0, stats.Count()))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.That(t,
+		assert.NoError(task(context.Background(), now.Add(-time.Minute))),
+		assert.Equal(1, stats.Count()),
+		assert.True(stats.Max() >= 50*time.Millisecond),
+		assert.False(stats.Last().IsZero()),
+		assert.Equal(1, len(lags)))
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// LoadProbe tells whether the system load is high.
+type LoadProbe func() bool
+
+// GoroutineProbe returns the probe, reporting high load while the number of
+// goroutines exceeds the limit.
+func GoroutineProbe(limit int) LoadProbe {
+	return func() bool {
+		return runtime.NumGoroutine() > limit
+	}
+}
+
+// CPUSampler is implemented by the sources of the CPU usage, e.g. by an adapter
+// of the gopsutil cpu.Percent function.
+type CPUSampler interface {
+	// Percent returns the current CPU usage in percent.
+	Percent() (float64, error)
+}
+
+// CPUProbe returns the probe, reporting high load while the CPU usage exceeds
+// the limit in percent. The sampling errors are reported as no load.
+func CPUProbe(sampler CPUSampler, limit float64) LoadProbe {
+	return func() bool {
+		usage, err := sampler.Percent()
+		return err == nil && usage > limit
+	}
+}
+
+// LoadShedder sheds the runs of the low-priority tasks, wrapped with
+// [LoadShedding], while any of its probes reports high load.
+type LoadShedder struct {
+	probes   []LoadProbe
+	shed     atomic.Uint64
+	deferred atomic.Uint64
+}
+
+// NewLoadShedder returns the shedder with the given probes.
+func NewLoadShedder(probes ...LoadProbe) *LoadShedder {
+	return &LoadShedder{probes: probes}
+}
+
+// High tells whether any of the probes reports high load.
+func (s *LoadShedder) High() bool {
+	for _, probe := range s.probes {
+		if probe() {
+			return true
+		}
+	}
+	return false
+}
+
+// Shed returns the number of the skipped runs.
+func (s *LoadShedder) Shed() uint64 {
+	return s.shed.Load()
+}
+
+// Deferred returns the number of the runs, which have been delayed, but
+// executed when the load dropped.
+func (s *LoadShedder) Deferred() uint64 {
+	return s.deferred.Load()
+}
+
+// SkipReasonLoad is the reason of the runs skipped by [LoadShedding].
+const SkipReasonLoad = "load"
+
+// loadRecheckInterval is the maximal interval between the probes of a deferred
+// run.
+const loadRecheckInterval = 100 * time.Millisecond
+
+// LoadShedding skips the runs of the low priority task, reporting
+// [SkipReasonLoad], while the shedder reports high load. If maxDefer is
+// positive, a run is deferred until the load drops, but no longer than
+// maxDefer, and is skipped then. The runs of the other tasks are never shed.
+// The context cause is returned if the context is done while deferring.
+func LoadShedding[TickType any, Fn Func[TickType]](s *LoadShedder, lowPriority bool, maxDefer time.Duration, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("LoadShedding", task)
+	return func(ctx context.Context, tick TickType) error {
+		if lowPriority && s.High() {
+			deadline := time.Now().Add(timescale.Scale(maxDefer))
+			for high := true; high; high = s.High() {
+				wait := min(time.Until(deadline), timescale.Scale(loadRecheckInterval))
+				if wait <= 0 {
+					s.shed.Add(1)
+					Skip(ctx, SkipReasonLoad)
+					return nil
+				}
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return context.Cause(ctx)
+				}
+			}
+			s.deferred.Add(1)
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
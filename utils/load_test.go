@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+type fakeCPU struct {
+	usage float64
+	err   error
+}
+
+func (c fakeCPU) Percent() (float64, error) {
+	return c.usage, c.err
+}
+
+func TestLoadProbes(t *testing.T) {
+	assert.That(t,
+		assert.True(GoroutineProbe(0)()),
+		assert.False(GoroutineProbe(1<<20)()),
+		assert.True(CPUProbe(fakeCPU{usage: 95}, 90)()),
+		assert.False(CPUProbe(fakeCPU{usage: 50}, 90)()),
+		assert.False(CPUProbe(fakeCPU{usage: 95, err: errors.New("no data")}, 90)()))
+}
+
+func TestLoadShedding(t *testing.T) {
+	var high atomic.Bool
+	high.Store(true)
+	s := NewLoadShedder(func() bool { return false }, high.Load)
+	runs := 0
+	var result RunResult
+	task := Classify[any](func(r RunResult) { result = r },
+		LoadShedding[any](s, true, 0, func() { runs++ }))
+
+	assert.That(t,
+		assert.NoError(task(context.Background(), nil)),
+		assert.Equal(0, runs),
+		assert.Equal(RunSkipped, result.Outcome),
+		assert.Equal(SkipReasonLoad, result.Reason),
+		assert.Equal(uint64(1), s.Shed()))
+
+	assert.That(t,
+		assert.NoError(LoadShedding[any](s, false, 0, func() { runs++ })(context.Background(), nil)),
+		assert.Equal(1, runs),
+		assert.Equal(uint64(1), s.Shed()))
+	runs = 0
+
+	deferred := LoadShedding[any](s, true, time.Minute, func() { runs++ })
+	time.AfterFunc(10*time.Millisecond, func() { high.Store(false) })
+	assert.That(t,
+		assert.NoError(deferred(context.Background(), nil)),
+		assert.Equal(1, runs),
+		assert.Equal(uint64(1), s.Deferred()))
+
+	high.Store(true)
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrStopped)
+	assert.That(t,
+		assert.ErrorIs(deferred(ctx, nil), ErrStopped),
+		assert.Equal(1, runs))
+}
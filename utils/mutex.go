@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextLocker is a lock, which waiting can be interrupted by the context
+// cancellation.
+type ContextLocker interface {
+	LockContext(context.Context) error
+	Unlock()
+}
+
+// Mutex is a mutual exclusion lock, implementing both [sync.Locker] and
+// [ContextLocker]. The zero value is not usable, use [NewMutex].
+type Mutex struct {
+	ch chan struct{}
+}
+
+var (
+	_ sync.Locker   = (*Mutex)(nil)
+	_ ContextLocker = (*Mutex)(nil)
+)
+
+// NewMutex returns an unlocked mutex.
+func NewMutex() *Mutex {
+	return &Mutex{ch: make(chan struct{}, 1)}
+}
+
+// Lock locks the mutex, waiting until it is available.
+func (m *Mutex) Lock() {
+	m.ch <- struct{}{}
+}
+
+// LockContext locks the mutex, waiting until it is available or the context is
+// cancelled, in which case the context cause is returned.
+func (m *Mutex) LockContext(ctx context.Context) error {
+	select {
+	case m.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Unlock unlocks the mutex.
+func (m *Mutex) Unlock() {
+	<-m.ch
+}
+
+// SyncCtx wraps a task in a lock to avoid concurrent execution, as [Sync] does,
+// but stops waiting for the lock when the context is cancelled, returning the
+// context cause.
+func SyncCtx[TickType any, Fn Func[TickType]](locker ContextLocker, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("SyncCtx", task)
+	return func(ctx context.Context, tick TickType) error {
+		if err := locker.LockContext(ctx); err != nil {
+			return err
+		}
+		defer locker.Unlock()
+		return adaptedTask(ctx, tick)
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestSyncCtx(t *testing.T) {
+	mux := NewMutex()
+	calls := 0
+	task := SyncCtx[any](mux, func() { calls++ })
+
+	assert.That(t, assert.NoError(task(context.Background(), nil)))
+
+	mux.Lock()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrStopped)
+	assert.That(t,
+		assert.ErrorIs(task(ctx, nil), ErrStopped),
+		assert.Equal(1, calls))
+	mux.Unlock()
+
+	assert.That(t,
+		assert.NoError(Sync[any](mux, func() { calls++ })(context.Background(), nil)),
+		assert.Equal(2, calls))
+}
@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// RunOutcome classifies a task run.
+type RunOutcome int
+
+const (
+	// RunExecuted is the outcome of a run that completed without error.
+	RunExecuted RunOutcome = iota
+	// RunSkipped is the outcome of a run that a wrapper declined to execute.
+	RunSkipped
+	// RunFailed is the outcome of a run that returned an error.
+	RunFailed
+)
+
+func (o RunOutcome) String() string {
+	switch o {
+	case RunExecuted:
+		return "executed"
+	case RunSkipped:
+		return "skipped"
+	case RunFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// SkipReasonOverlap is the reason of the runs skipped by [NoOverlap].
+const SkipReasonOverlap = "overlap"
+
+// ErrSkipped is wrapped by the errors of the runs, which a wrapper declined to
+// execute, when the skips are reported with [ReportSkips], so that the callers
+// can count the skips without treating them as failures.
+var ErrSkipped = errors.New("skipped")
+
+// SkipError is the error of a skipped run, returned by [ReportSkips].
+type SkipError struct {
+	Reason string
+}
+
+func (e *SkipError) Error() string {
+	return ErrSkipped.Error() + ": " + e.Reason
+}
+
+// Is tells that the error is [ErrSkipped].
+func (e *SkipError) Is(target error) bool {
+	return target == ErrSkipped
+}
+
+// RunResult is reported by [Classify] for every run.
+type RunResult struct {
+	Outcome RunOutcome
+	// Reason is the reason of the skip.
+	Reason string
+	// Err is the error of the failed run.
+	Err error
+}
+
+type skipRecorderCtxKey struct{}
+
+// skipRecorder records the skip of the run. The wrapper, which owns the
+// recorder, passes the skip of the whole run to the parent, i.e. to the
+// recorder of the outer wrapper, when the run ends, see
+// [skipRecorder.finish].
+type skipRecorder struct {
+	mux     sync.Mutex
+	skipped bool
+	reason  string
+	parent  *skipRecorder
+}
+
+// withSkipRecorder returns the context with a new recorder, nested in the
+// recorder of the given context, if any.
+func withSkipRecorder(ctx context.Context) (context.Context, *skipRecorder) {
+	parent, _ := ctx.Value(skipRecorderCtxKey{}).(*skipRecorder)
+	recorder := &skipRecorder{parent: parent}
+	return context.WithValue(ctx, skipRecorderCtxKey{}, recorder), recorder
+}
+
+// skip records the skip for the reason, unless a skip is recorded already.
+func (r *skipRecorder) skip(reason string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if !r.skipped {
+		r.skipped = true
+		r.reason = reason
+	}
+}
+
+// result returns whether the run has been skipped, and the reason.
+func (r *skipRecorder) result() (bool, string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.skipped, r.reason
+}
+
+// resultOf returns whether the run, which returned err, has been skipped,
+// and the reason: the run has returned no error after a [Skip], or an error,
+// wrapping [ErrSkipped].
+func (r *skipRecorder) resultOf(err error) (bool, string) {
+	skipped, reason := r.result()
+	var skip *SkipError
+	switch {
+	case errors.As(err, &skip):
+		return true, skip.Reason
+	case errors.Is(err, ErrSkipped):
+		return true, reason
+	case err != nil:
+		return false, ""
+	}
+	return skipped, reason
+}
+
+// finish returns [skipRecorder.resultOf] the run, and records the skip to the
+// parent recorder, if the run has been skipped without an error.
+func (r *skipRecorder) finish(err error) (bool, string) {
+	skipped, reason := r.resultOf(err)
+	if skipped && err == nil && r.parent != nil {
+		r.parent.skip(reason)
+	}
+	return skipped, reason
+}
+
+// stepSkips tracks the skips of the steps of a composite task, e.g. [Seq], so
+// that the run is skipped only if all its steps have been skipped.
+type stepSkips struct {
+	parent *skipRecorder
+	steps  []*skipRecorder
+}
+
+// newStepSkips returns the tracker of the steps of the run.
+func newStepSkips(ctx context.Context) *stepSkips {
+	parent, _ := ctx.Value(skipRecorderCtxKey{}).(*skipRecorder)
+	return &stepSkips{parent: parent}
+}
+
+// step returns the context of the next step of the run. The context is not
+// changed, if the skips are not recorded.
+func (s *stepSkips) step(ctx context.Context) context.Context {
+	if s.parent == nil {
+		return ctx
+	}
+	ctx, recorder := withSkipRecorder(ctx)
+	s.steps = append(s.steps, recorder)
+	return ctx
+}
+
+// done records the skip of the run with the reason of the first step, if all
+// the steps have been skipped. Must be called after the steps return.
+func (s *stepSkips) done() {
+	if len(s.steps) == 0 {
+		return
+	}
+	for _, step := range s.steps {
+		if skipped, _ := step.result(); !skipped {
+			return
+		}
+	}
+	_, reason := s.steps[0].result()
+	s.parent.skip(reason)
+}
+
+// Skip records to the context, provided by [Classify] or [Sequence], that the
+// run has been skipped for the reason. Wrappers that decline to execute the
+// task should call it before returning. The skip is recorded for the innermost
+// wrapper, which passes it outwards when the run ends. A step of [Seq] or
+// [Parallel] skips the run only if all the steps skip it.
+func Skip(ctx context.Context, reason string) {
+	if recorder, ok := ctx.Value(skipRecorderCtxKey{}).(*skipRecorder); ok {
+		recorder.skip(reason)
+	}
+}
+
+// Classify calls report with the outcome of every task run.
+// A run is skipped if a wrapper called [Skip] and the run returned no error,
+// or if the run returned an error, wrapping [ErrSkipped].
+func Classify[TickType any, Fn Func[TickType]](report func(RunResult), task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Classify", task)
+	return func(ctx context.Context, tick TickType) error {
+		ctx, recorder := withSkipRecorder(ctx)
+		err := adaptedTask(ctx, tick)
+		var result RunResult
+		switch skipped, reason := recorder.finish(err); {
+		case skipped:
+			result.Outcome = RunSkipped
+			result.Reason = reason
+		case err != nil:
+			result.Outcome = RunFailed
+			result.Err = err
+		}
+		report(result)
+		return err
+	}
+}
+
+// ReportSkips returns [*SkipError], wrapping [ErrSkipped], instead of no error
+// for the runs, which the inner wrappers have skipped with [Skip], e.g.
+// [NoOverlap] or [When]. The skipped runs are not retried by [Retry].
+func ReportSkips[TickType any, Fn Func[TickType]](task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("ReportSkips", task)
+	return func(ctx context.Context, tick TickType) error {
+		ctx, recorder := withSkipRecorder(ctx)
+		err := adaptedTask(ctx, tick)
+		if skipped, reason := recorder.result(); skipped && err == nil {
+			return &SkipError{reason}
+		}
+		return err
+	}
+}
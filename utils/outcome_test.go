@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestClassify(t *testing.T) {
+	var mux sync.Mutex
+	var results []RunResult
+	report := func(r RunResult) {
+		mux.Lock()
+		defer mux.Unlock()
+		results = append(results, r)
+	}
+	errTest := errors.New("test")
+
+	_ = Classify[any](report, func() {})(context.Background(), nil)
+	_ = Classify[any](report, func() error { return errTest })(context.Background(), nil)
+	_ = Classify[any](report, func(ctx context.Context) { Skip(ctx, "test") })(context.Background(), nil)
+
+	running, release := make(chan struct{}), make(chan struct{})
+	task := Classify[any](report, NoOverlap[any](func() {
+		close(running)
+		<-release
+	}))
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = task(context.Background(), nil)
+	}()
+	<-running
+	_ = task(context.Background(), nil)
+	close(release)
+	wg.Wait()
+
+	assert.That(t,
+		assert.EqualSlices([]RunResult{
+			{RunExecuted, "", nil},
+			{RunFailed, "", errTest},
+			{RunSkipped, "test", nil},
+			{RunSkipped, SkipReasonOverlap, nil},
+			{RunExecuted, "", nil},
+		}, results))
+
+	t.Run("steps", func(t *testing.T) {
+		results = nil
+		off := When[any](func(context.Context, any) bool { return false }, "off", func() {})
+		on := Adapt[any](func() {})
+		for _, task := range []func(context.Context, any) error{
+			Seq(off, on),
+			Seq(off, Classify[any](func(RunResult) {}, off)),
+			Parallel(on, off),
+			Parallel(off, off),
+			Staggered(nil, on, off),
+		} {
+			_ = Classify[any](report, task)(context.Background(), nil)
+		}
+		assert.That(t,
+			assert.EqualSlices([]RunResult{
+				{RunExecuted, "", nil},
+				{RunSkipped, "off", nil},
+				{RunExecuted, "", nil},
+				{RunSkipped, "off", nil},
+				{RunExecuted, "", nil},
+			}, results))
+	})
+}
+
+func TestReportSkips(t *testing.T) {
+	var results []RunResult
+	report := func(r RunResult) { results = append(results, r) }
+	errTest := errors.New("test")
+	skipping := func(ctx context.Context) { Skip(ctx, "test") }
+
+	err := ReportSkips[any](skipping)(context.Background(), nil)
+	var skip *SkipError
+	assert.That(t,
+		assert.ErrorIs(err, ErrSkipped),
+		assert.True(errors.As(err, &skip)),
+		assert.Equal("test", skip.Reason),
+		assert.NoError(ReportSkips[any](func() {})(context.Background(), nil)),
+		assert.ErrorIs(ReportSkips[any](func() error { return errTest })(context.Background(), nil), errTest))
+
+	attempts := 0
+	err = Classify[any](report, Retry[any](SimpleRetryPolicy(3), ReportSkips[any](func(ctx context.Context) {
+		attempts++
+		skipping(ctx)
+	})))(context.Background(), nil)
+	_ = Classify[any](report, func() error { return ErrSkipped })(context.Background(), nil)
+	assert.That(t,
+		assert.ErrorIs(err, ErrSkipped),
+		assert.Equal(1, attempts),
+		assert.EqualSlices([]RunResult{{RunSkipped, "test", nil}, {RunSkipped, "", nil}}, results))
+
+	// A run with a skipped step is not skipped.
+	ran := false
+	err = ReportSkips[any](Seq(Adapt[any](skipping), Adapt[any](func() { ran = true })))(context.Background(), nil)
+	assert.That(t,
+		assert.NoError(err),
+		assert.True(ran))
+	err = ReportSkips[any](Seq(Adapt[any](skipping), Adapt[any](skipping)))(context.Background(), nil)
+	assert.That(t, assert.ErrorIs(err, ErrSkipped))
+}
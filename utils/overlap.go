@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// SkipReasonRetrying is the reason of the runs skipped by [NoOverlapGroup],
+// while the occupying run waits for its next [Retry] attempt.
+const SkipReasonRetrying = "retrying"
+
+type retrySequenceCtxKey struct{}
+
+// retrySequence is the run of [Retry], which spans all its attempts.
+type retrySequence struct {
+	mux   sync.Mutex
+	onEnd []func()
+}
+
+// afterRetries registers f to be called when the attempts of the enclosing
+// [Retry] end. It returns false if the context is not of a Retry attempt.
+func afterRetries(ctx context.Context, f func()) bool {
+	seq, ok := ctx.Value(retrySequenceCtxKey{}).(*retrySequence)
+	if !ok {
+		return false
+	}
+	seq.mux.Lock()
+	defer seq.mux.Unlock()
+	seq.onEnd = append(seq.onEnd, f)
+	return true
+}
+
+// withRetrySequence returns the context of the attempts of a new [Retry] run,
+// and the function, which ends the run.
+func withRetrySequence(ctx context.Context) (context.Context, func()) {
+	seq := &retrySequence{}
+	return context.WithValue(ctx, retrySequenceCtxKey{}, seq), func() {
+		seq.mux.Lock()
+		onEnd := seq.onEnd
+		seq.onEnd = nil
+		seq.mux.Unlock()
+		for _, f := range onEnd {
+			f()
+		}
+	}
+}
+
+// OverlapGroup is the occupancy, shared by the tasks, wrapped with
+// [NoOverlapGroup], so that at most one of them runs at a time. The zero value
+// is ready to use.
+type OverlapGroup struct {
+	mux      sync.Mutex
+	occupied bool
+	// seq is the Retry run, which occupies the group, if any.
+	seq *retrySequence
+	// running is false while the occupying Retry run waits for the next
+	// attempt.
+	running bool
+}
+
+// NoOverlapGroup prevents the tasks of the group from running concurrently.
+// Unlike [NoOverlap], a run occupies the group for the whole sequence of its
+// [Retry] attempts in both orders of the composition:
+//
+//   - NoOverlapGroup(g, Retry(policy, task)) holds the group from the first
+//     attempt until the last one returns, including the backoff. The runs in
+//     between are skipped with [SkipReasonOverlap];
+//   - Retry(policy, NoOverlapGroup(g, task)) takes the group on the first
+//     attempt, and keeps it for the next attempts of the same run until Retry
+//     returns. The runs, which come during the backoff, are skipped with
+//     [SkipReasonRetrying], and the runs during an attempt with
+//     SkipReasonOverlap.
+//
+// Compare with Retry(policy, NoOverlap(task)), which occupies the task for a
+// single attempt: another run may start during the backoff, and the skipped
+// attempt ends the retries, as it returns no error.
+func NoOverlapGroup[TickType any, Fn Func[TickType]](g *OverlapGroup, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("NoOverlapGroup", task)
+	return func(ctx context.Context, tick TickType) error {
+		seq, _ := ctx.Value(retrySequenceCtxKey{}).(*retrySequence)
+		g.mux.Lock()
+		switch {
+		case !g.occupied:
+			g.occupied = true
+			g.seq = seq
+			if seq != nil {
+				afterRetries(ctx, g.release)
+			}
+		case seq == nil || g.seq != seq:
+			reason := SkipReasonOverlap
+			if g.seq != nil && !g.running {
+				reason = SkipReasonRetrying
+			}
+			g.mux.Unlock()
+			Skip(ctx, reason)
+			return nil
+		}
+		g.running = true
+		g.mux.Unlock()
+		defer func() {
+			g.mux.Lock()
+			g.running = false
+			g.mux.Unlock()
+			if seq == nil {
+				g.release()
+			}
+		}()
+		return adaptedTask(ctx, tick)
+	}
+}
+
+// release frees the group for the next run.
+func (g *OverlapGroup) release() {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.occupied = false
+	g.seq = nil
+}
@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestNoOverlapGroup(t *testing.T) {
+	errTest := errors.New("test")
+
+	// run starts the task in background, and returns the channel, which
+	// unblocks the backoff of the first attempt, and the result channel.
+	run := func(fn func(context.Context, any) error) (chan struct{}, chan error) {
+		backoff, done := make(chan struct{}), make(chan error, 1)
+		go func() { done <- fn(context.Background(), nil) }()
+		return backoff, done
+	}
+	skipReason := func(fn func(context.Context, any) error) string {
+		var result RunResult
+		_ = Classify[any](func(r RunResult) { result = r }, fn)(context.Background(), nil)
+		return result.Reason
+	}
+
+	t.Run("wrapping Retry", func(t *testing.T) {
+		var g OverlapGroup
+		waiting := make(chan struct{})
+		var backoff chan struct{}
+		policy := func(_ context.Context, i int, err error) bool {
+			if err == nil {
+				return false
+			}
+			if i == 0 {
+				waiting <- struct{}{}
+				<-backoff
+			}
+			return i == 0
+		}
+		var attempts int
+		fn := NoOverlapGroup[any](&g, Retry[any](policy, func() error {
+			attempts++
+			return errTest
+		}))
+		var done chan error
+		backoff, done = run(fn)
+		<-waiting
+		reason := skipReason(fn)
+		close(backoff)
+		assert.That(t,
+			assert.Equal(SkipReasonOverlap, reason),
+			assert.ErrorIs(<-done, errTest),
+			assert.Equal(2, attempts),
+			assert.Equal("", skipReason(NoOverlapGroup[any](&g, func() {}))))
+	})
+
+	t.Run("wrapped by Retry", func(t *testing.T) {
+		var g OverlapGroup
+		waiting, running := make(chan struct{}), make(chan struct{})
+		var backoff chan struct{}
+		policy := func(_ context.Context, i int, err error) bool {
+			if err == nil {
+				return false
+			}
+			if i == 0 {
+				waiting <- struct{}{}
+				<-backoff
+			}
+			return i == 0
+		}
+		var mux sync.Mutex
+		var attempts int
+		fn := Retry[any](policy, NoOverlapGroup[any](&g, func(ctx context.Context) error {
+			mux.Lock()
+			attempts++
+			attempt := attempts
+			mux.Unlock()
+			if attempt == 2 {
+				running <- struct{}{}
+				<-running
+			}
+			return errTest
+		}))
+		var done chan error
+		backoff, done = run(fn)
+		<-waiting
+		// The run during the backoff is skipped, and the skipped attempt is not
+		// retried.
+		retrying := skipReason(fn)
+		close(backoff)
+		<-running
+		overlap := skipReason(fn)
+		running <- struct{}{}
+		assert.That(t,
+			assert.Equal(SkipReasonRetrying, retrying),
+			assert.Equal(SkipReasonOverlap, overlap),
+			assert.ErrorIs(<-done, errTest),
+			assert.Equal(2, attempts))
+
+		// The group is released after the retries.
+		other := NoOverlapGroup[any](&g, func() {})
+		assert.That(t, assert.Equal("", skipReason(other)))
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		var g OverlapGroup
+		running, release := make(chan struct{}), make(chan struct{})
+		first := NoOverlapGroup[any](&g, func() {
+			close(running)
+			<-release
+		})
+		second := NoOverlapGroup[any](&g, func() {})
+		_, done := run(first)
+		<-running
+		reason := skipReason(second)
+		close(release)
+		assert.That(t,
+			assert.Equal(SkipReasonOverlap, reason),
+			assert.NoError(<-done),
+			assert.Equal("", skipReason(second)))
+	})
+}
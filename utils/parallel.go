@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Parallel executes the tasks concurrently, and returns the joined errors of
+// the failed tasks when all of them finish. Nil tasks are skipped. The run is
+// skipped, see [Skip], only if all the tasks are skipped.
+func Parallel[TickType any](tasks ...func(context.Context, TickType) error) func(context.Context, TickType) error {
+	return func(ctx context.Context, tick TickType) error {
+		errs := make([]error, len(tasks))
+		skips := newStepSkips(ctx)
+		var wg sync.WaitGroup
+		for i, task := range tasks {
+			if task == nil {
+				continue
+			}
+			stepCtx := skips.step(ctx)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = task(stepCtx, tick)
+			}()
+		}
+		wg.Wait()
+		skips.done()
+		return errors.Join(errs...)
+	}
+}
+
+// Result is the value and the error of a step, executed by [ParallelCollect].
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Results are the results of the steps, executed by [ParallelCollect], by the
+// step index.
+type Results[T any] []Result[T]
+
+// Err returns the joined errors of the failed steps, annotated with the step
+// indices, or nil.
+func (r Results[T]) Err() error {
+	var errs []error
+	for i, result := range r {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("step #%d: %w", i, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Values returns the values of the succeeded steps in order.
+func (r Results[T]) Values() []T {
+	var values []T
+	for _, result := range r {
+		if result.Err == nil {
+			values = append(values, result.Value)
+		}
+	}
+	return values
+}
+
+// ParallelCollect returns the step, which executes the steps concurrently and
+// collects their results. The error of the step is [Results.Err], and the
+// results are returned in any case, so that the partial results can be used.
+// See [PipeTask] to consume the results. Nil steps are skipped, as by
+// [Parallel], and the results are indexed by the remaining steps.
+func ParallelCollect[In, T any](steps ...Step[In, T]) Step[In, Results[T]] {
+	steps = slices.DeleteFunc(slices.Clone(steps), func(step Step[In, T]) bool { return step == nil })
+	return func(ctx context.Context, in In) (Results[T], error) {
+		results := make(Results[T], len(steps))
+		var wg sync.WaitGroup
+		for i, step := range steps {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i].Value, results[i].Err = step(ctx, in)
+			}()
+		}
+		wg.Wait()
+		return results, results.Err()
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestParallel(t *testing.T) {
+	var calls atomic.Int32
+	errFailed := errors.New("failed")
+	task := Parallel(
+		func(context.Context, int) error { calls.Add(1); return nil },
+		nil,
+		func(context.Context, int) error { calls.Add(1); return errFailed })
+	assert.That(t,
+		assert.ErrorIs(task(context.Background(), 0), errFailed),
+		assert.Equal(int32(2), calls.Load()))
+}
+
+func TestParallelCollect(t *testing.T) {
+	errFailed := errors.New("failed")
+	double := func(_ context.Context, in int) (int, error) { return 2 * in, nil }
+	fail := func(context.Context, int) (int, error) { return 0, errFailed }
+
+	results, err := ParallelCollect[int, int](double, fail, double)(context.Background(), 2)
+	assert.That(t,
+		assert.ErrorIs(err, errFailed),
+		assert.Equal("step #1: failed", err.Error()),
+		assert.Equal(3, len(results)),
+		assert.ErrorIs(results[1].Err, errFailed),
+		assert.EqualSlices([]int{4, 4}, results.Values()))
+
+	results, err = ParallelCollect[int, int](double)(context.Background(), 1)
+	assert.That(t,
+		assert.NoError(err),
+		assert.EqualSlices([]int{2}, results.Values()))
+
+	results, err = ParallelCollect[int, int](nil, fail, nil, double)(context.Background(), 1)
+	assert.That(t,
+		assert.Equal("step #0: failed", err.Error()),
+		assert.Equal(2, len(results)),
+		assert.EqualSlices([]int{2}, results.Values()))
+}
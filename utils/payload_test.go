@@ -0,0 +1,213 @@
+package utils
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+// payload is a custom tick type, which the wrappers must pass through to the
+// inner task intact.
+type payload struct {
+	id   int
+	data []string
+}
+
+// wrapper is a combinator of the tasks, receiving the payload ticks.
+type wrapper func(task func(context.Context, payload) error) func(context.Context, payload) error
+
+func TestWrappers_passPayload(t *testing.T) {
+	always := func(context.Context, payload) bool { return true }
+	wrappers := map[string]wrapper{
+		"Adapt": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Adapt[payload](task)
+		},
+		"AdaptiveConcurrency": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return AdaptiveConcurrency[payload](NewAdaptiveLimiter(1, 2, time.Second), task)
+		},
+		"AutoTimeout": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return AutoTimeout[payload](10, task)
+		},
+		"Budget": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Budget[payload](BudgetLimits{Duration: time.Second}, nil, task)
+		},
+		"CaptureOutput": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return CaptureOutput[payload](func(OutputStream, []byte) {}, task)
+		},
+		"CaptureRunLog": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return CaptureRunLog[payload](&RunLogs{}, nil, task)
+		},
+		"Classify": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Classify[payload](func(RunResult) {}, task)
+		},
+		"HealthGate": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return HealthGate[payload](func(context.Context) error { return nil }, nil, task)
+		},
+		"Idempotent": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Idempotent[payload](func(payload) string { return "" }, &MemoryKeyStore{}, task)
+		},
+		"IdleStop": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return IdleStop[payload](2, func(error) bool { return false }, task)
+		},
+		"IgnoreErr": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return IgnoreErr[payload](task)
+		},
+		"InPool": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return InPool[payload](NewPool(1, 0, OverflowBlock), task)
+		},
+		"InWindow": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return InWindow[payload](Window{}, WindowSkip, task)
+		},
+		"Journaled": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Journaled[payload](NewJournal(io.Discard), "test", task)
+		},
+		"LoadShedding": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return LoadShedding[payload](NewLoadShedder(), true, time.Second, task)
+		},
+		"Log": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Log[payload](io.Discard, io.Discard, "test", task)
+		},
+		"MapErr": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return MapErr[payload](func(err error) error { return err }, task)
+		},
+		"MinGap": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return MinGap[payload](time.Millisecond, task)
+		},
+		"NoOverlap": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return NoOverlap[payload](task)
+		},
+		"NoOverlapGroup": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return NoOverlapGroup[payload](&OverlapGroup{}, task)
+		},
+		"Optional": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Optional[payload](true, task)
+		},
+		"Parallel": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Parallel(task)
+		},
+		"Recover": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Recover[payload](task)
+		},
+		"ReportSkips": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return ReportSkips[payload](task)
+		},
+		"Retry": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Retry[payload](SimpleRetryPolicy(2), task)
+		},
+		"RetryCancelBetweenAttempts": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return RetryCancelBetweenAttempts[payload](SimpleRetryPolicy(2), task)
+		},
+		"Seq": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Seq(task)
+		},
+		"Sequence": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Sequence[payload](&TickSequence{}, task)
+		},
+		"Shape": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Shape[payload](NewShaper(1000), false, task)
+		},
+		"Staggered": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Staggered([]time.Duration{0}, task)
+		},
+		"StartGate": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return StartGate[payload](func(context.Context) error { return nil }, nil, task)
+		},
+		"StopOn": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return StopOn[payload](func(error) bool { return false }, task)
+		},
+		"Sync": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Sync[payload](NewMutex(), task)
+		},
+		"SyncCtx": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return SyncCtx[payload](NewMutex(), task)
+		},
+		"Takeover": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Takeover[payload](task)
+		},
+		"ThrottleReplay": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return ThrottleReplay[payload](time.Millisecond, task)
+		},
+		"Timeout": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return Timeout[payload](time.Second, task)
+		},
+		"TrackFailures": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return TrackFailures[payload](&FailureStats{}, task)
+		},
+		"TrackInFlight": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return TrackInFlight[payload]("test", task)
+		},
+		"TrackIntervals": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return TrackIntervals[payload](&IntervalStats{}, task)
+		},
+		"TreatTimeoutAs": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return TreatTimeoutAs[payload](TimeoutRetry, task)
+		},
+		"When": func(task func(context.Context, payload) error) func(context.Context, payload) error {
+			return When[payload](always, "", task)
+		},
+	}
+	for _, name := range packageWrappers(t) {
+		if _, ok := wrappers[name]; !ok {
+			t.Errorf("the wrapper %s is not tested", name)
+		}
+	}
+	for name, wrap := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			tick := payload{id: 42, data: []string{"a", "b"}}
+			received := make(chan payload, 1)
+			err := wrap(func(_ context.Context, p payload) error {
+				received <- p
+				return nil
+			})(context.Background(), tick)
+			// Some wrappers, e.g. Takeover, run the task in background.
+			got := <-received
+			assert.That(t,
+				assert.NoError(err),
+				assert.Equal(tick.id, got.id),
+				assert.EqualSlices(tick.data, got.data))
+		})
+	}
+}
+
+// packageWrappers returns the names of the exported wrappers of the package,
+// which accept the task of any tick type as Fn Func[TickType], so that a new
+// wrapper cannot be left out of the tests.
+func packageWrappers(t *testing.T) []string {
+	files, err := filepath.Glob("*.go")
+	assert.That(t, assert.NoError(err))
+	var names []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution)
+		assert.That(t, assert.NoError(err))
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !fn.Name.IsExported() || fn.Type.TypeParams == nil {
+				continue
+			}
+			for _, param := range fn.Type.TypeParams.List {
+				index, ok := param.Type.(*ast.IndexExpr)
+				if !ok {
+					continue
+				}
+				constraint, isFunc := index.X.(*ast.Ident)
+				tick, isTickType := index.Index.(*ast.Ident)
+				if isFunc && isTickType && constraint.Name == "Func" && tick.Name == "TickType" {
+					names = append(names, fn.Name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,38 @@
+package utils
+
+import "context"
+
+// Step is a pipeline stage, that processes the output of the previous one.
+type Step[In, Out any] func(context.Context, In) (Out, error)
+
+// Pipe chains the steps, so that the second one receives the output of the
+// first one. The pipeline stops on the first error. Longer pipelines are built
+// by nesting:
+//
+//	Pipe(Pipe(fetch, parse), validate)
+func Pipe[In, Mid, Out any](first Step[In, Mid], second Step[Mid, Out]) Step[In, Out] {
+	return func(ctx context.Context, in In) (Out, error) {
+		mid, err := first(ctx, in)
+		if err != nil {
+			var out Out
+			return out, err
+		}
+		return second(ctx, mid)
+	}
+}
+
+// PipeTask returns the task, that runs the pipeline on every tick, and passes
+// its output to sink.
+//
+// Example:
+//
+//	PipeTask(Pipe(fetch, parse), store)
+func PipeTask[TickType, Out any](pipeline Step[TickType, Out], sink func(context.Context, Out) error) func(context.Context, TickType) error {
+	return func(ctx context.Context, tick TickType) error {
+		out, err := pipeline(ctx, tick)
+		if err != nil {
+			return err
+		}
+		return sink(ctx, out)
+	}
+}
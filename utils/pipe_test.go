@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestPipe(t *testing.T) {
+	double := func(_ context.Context, tick int) (int, error) { return tick * 2, nil }
+	format := func(_ context.Context, n int) (string, error) { return strconv.Itoa(n), nil }
+	errOdd := errors.New("odd")
+	even := func(_ context.Context, s string) (string, error) {
+		if n, _ := strconv.Atoi(s); n%2 != 0 {
+			return "", errOdd
+		}
+		return s, nil
+	}
+
+	var stored []string
+	task := PipeTask(Pipe(Pipe(double, format), even),
+		func(_ context.Context, s string) error {
+			stored = append(stored, s)
+			return nil
+		})
+	assert.That(t,
+		assert.NoError(task(context.Background(), 21)),
+		assert.EqualSlices([]string{"42"}, stored))
+
+	odd := PipeTask(Pipe(format, even),
+		func(context.Context, string) error { return nil })
+	assert.That(t, assert.ErrorIs(odd(context.Background(), 3), errOdd))
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+)
+
+// ErrPoolOverflow is returned by [InPool] when the pool queue is full.
+var ErrPoolOverflow = errors.New("pool queue overflow")
+
+// SkipReasonPoolOverflow is the reason of the runs skipped by [InPool].
+const SkipReasonPoolOverflow = "pool overflow"
+
+// OverflowPolicy defines what [InPool] does when the pool queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits for a free worker regardless of the queue size.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowSkip skips the run, reporting [SkipReasonPoolOverflow].
+	OverflowSkip
+	// OverflowFail fails the run with [ErrPoolOverflow].
+	OverflowFail
+)
+
+// Pool limits the number of concurrent runs of the tasks, that share it.
+type Pool struct {
+	workers  chan struct{}
+	queue    int
+	overflow OverflowPolicy
+	queued   atomic.Int32
+}
+
+// NewPool returns a pool of the given number of workers, with the given number
+// of runs allowed to wait for a worker.
+func NewPool(workers, queue int, overflow OverflowPolicy) *Pool {
+	return &Pool{
+		workers:  make(chan struct{}, max(workers, 1)),
+		queue:    queue,
+		overflow: overflow,
+	}
+}
+
+// ProcsWorkers returns the number of workers, taking the share of
+// [runtime.GOMAXPROCS], e.g. 0.5 to reserve the half of the processors for the
+// request path of the service, but at least one. The share outside of (0, 1]
+// is treated as 1.
+func ProcsWorkers(share float64) int {
+	if share <= 0 || share > 1 {
+		share = 1
+	}
+	return max(int(float64(runtime.GOMAXPROCS(0))*share), 1)
+}
+
+// NewProcsPool returns a pool of [ProcsWorkers] workers, sized on the current
+// [runtime.GOMAXPROCS], as [NewPool] does.
+func NewProcsPool(share float64, queue int, overflow OverflowPolicy) *Pool {
+	return NewPool(ProcsWorkers(share), queue, overflow)
+}
+
+// Workers returns the number of the pool workers.
+func (p *Pool) Workers() int {
+	return cap(p.workers)
+}
+
+// Running returns the number of busy workers.
+func (p *Pool) Running() int {
+	return len(p.workers)
+}
+
+// Queued returns the number of runs, waiting for a worker.
+func (p *Pool) Queued() int {
+	return int(p.queued.Load())
+}
+
+// acquire waits for a free worker.
+func (p *Pool) acquire(ctx context.Context) error {
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	default:
+	}
+	if queued := p.queued.Add(1); p.overflow != OverflowBlock && int(queued) > p.queue {
+		p.queued.Add(-1)
+		return ErrPoolOverflow
+	}
+	defer p.queued.Add(-1)
+	select {
+	case p.workers <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+func (p *Pool) release() {
+	<-p.workers
+}
+
+// InPool runs the task on a worker of the pool. The run waits for a free
+// worker, or is handled according to the pool overflow policy if the queue is
+// full.
+func InPool[TickType any, Fn Func[TickType]](pool *Pool, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("InPool", task)
+	return func(ctx context.Context, tick TickType) error {
+		if err := pool.acquire(ctx); err != nil {
+			if errors.Is(err, ErrPoolOverflow) && pool.overflow == OverflowSkip {
+				Skip(ctx, SkipReasonPoolOverflow)
+				return nil
+			}
+			return err
+		}
+		defer pool.release()
+		return adaptedTask(ctx, tick)
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestInPool(t *testing.T) {
+	for policy, expected := range map[OverflowPolicy]error{
+		OverflowSkip: nil,
+		OverflowFail: ErrPoolOverflow,
+	} {
+		pool := NewPool(1, 1, policy)
+		running, release := make(chan struct{}), make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- InPool[any](pool, func() {
+				close(running)
+				<-release
+			})(context.Background(), nil)
+		}()
+		<-running
+		// Take the queue place, as a run waiting for the worker would.
+		pool.queued.Add(1)
+		var results []RunResult
+		err := Classify[any](func(r RunResult) { results = append(results, r) },
+			InPool[any](pool, func() {}))(context.Background(), nil)
+		assert.That(t,
+			assert.ErrorIs(err, expected),
+			assert.Equal(1, pool.Running()),
+			assert.Equal(1, len(results)))
+		if policy == OverflowSkip {
+			assert.That(t,
+				assert.Equal(RunResult{RunSkipped, SkipReasonPoolOverflow, nil}, results[0]))
+		}
+		pool.queued.Add(-1)
+		close(release)
+		assert.That(t,
+			assert.NoError(<-done),
+			assert.Equal(0, pool.Running()),
+			assert.Equal(0, pool.Queued()))
+	}
+
+	t.Run("queued", func(t *testing.T) {
+		pool := NewPool(1, 1, OverflowFail)
+		pool.workers <- struct{}{}
+		done := make(chan error)
+		go func() { done <- InPool[any](pool, func() {})(context.Background(), nil) }()
+		for pool.Queued() == 0 {
+			runtime.Gosched()
+		}
+		select {
+		case <-done:
+			t.Fatal("the run has not been queued")
+		default:
+		}
+		// The queued run gets the worker on release.
+		pool.release()
+		assert.That(t,
+			assert.NoError(<-done),
+			assert.Equal(0, pool.Running()))
+	})
+
+	t.Run("cancelled while queued", func(t *testing.T) {
+		pool := NewPool(1, 0, OverflowBlock)
+		pool.workers <- struct{}{}
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(ErrStopped)
+		err := InPool[any](pool, func() { t.Error("unexpected call") })(ctx, nil)
+		assert.That(t, assert.ErrorIs(err, ErrStopped))
+	})
+}
+
+func TestProcsWorkers(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	assert.That(t,
+		assert.Equal(4, ProcsWorkers(0.5)),
+		assert.Equal(8, ProcsWorkers(1)),
+		assert.Equal(8, ProcsWorkers(0)),
+		assert.Equal(1, ProcsWorkers(0.01)),
+		assert.Equal(2, NewProcsPool(0.25, 0, OverflowBlock).Workers()))
+}
@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPanic is wrapped by [PanicError].
+var ErrPanic = errors.New("panic")
+
+// fingerprintFrames is the number of the top stack frames, accounted by
+// [Fingerprint].
+const fingerprintFrames = 3
+
+// PanicError is returned by [Recover] when the task panics.
+type PanicError struct {
+	Value any
+	// Frames are the functions of the panicking stack, top first.
+	Frames []string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrPanic, e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return errors.Join(ErrPanic, err)
+	}
+	return ErrPanic
+}
+
+// panicFrames returns the function names of the panicking goroutine stack,
+// called from a deferred function.
+func panicFrames() []string {
+	pc := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(3, pc)])
+	var names []string
+	panicking := true
+	for {
+		frame, more := frames.Next()
+		// Skip the frames down to the runtime panic.
+		if panicking {
+			panicking = !strings.HasPrefix(frame.Function, "runtime.gopanic")
+		} else {
+			names = append(names, frame.Function)
+		}
+		if !more {
+			break
+		}
+	}
+	return names
+}
+
+// Recover converts the task panic to a [*PanicError].
+func Recover[TickType any, Fn Func[TickType]](task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Recover", task)
+	return func(ctx context.Context, tick TickType) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Frames: panicFrames()}
+			}
+		}()
+		return adaptedTask(ctx, tick)
+	}
+}
+
+// Fingerprint returns a stable identifier of the error kind: a hash of the
+// types of the error chain, the message of the innermost error, and, for a
+// [*PanicError], of the top stack frames. Errors with dynamic innermost
+// messages have distinct fingerprints.
+func Fingerprint(err error) string {
+	if err == nil {
+		return ""
+	}
+	h := sha256.New()
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		_, _ = fmt.Fprintf(h, "%T\n", panicErr.Value)
+		if e, ok := panicErr.Value.(error); ok {
+			err = e
+		} else {
+			_, _ = fmt.Fprintln(h, panicErr.Value)
+			err = nil
+		}
+		for _, frame := range panicErr.Frames[:min(fingerprintFrames, len(panicErr.Frames))] {
+			_, _ = fmt.Fprintln(h, frame)
+		}
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		_, _ = fmt.Fprintf(h, "%T\n", err)
+		if errors.Unwrap(err) == nil {
+			_, _ = fmt.Fprintln(h, err.Error())
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// FailureStat aggregates the failures with the same fingerprint.
+type FailureStat struct {
+	Fingerprint string
+	Count       int
+	First, Last time.Time
+	// LastErr is the last error with the fingerprint.
+	LastErr error
+}
+
+// FailureStats counts the task failures by their [Fingerprint].
+type FailureStats struct {
+	// MaxFingerprints, if positive, limits the number of the kept
+	// fingerprints, dropping the least recently failed ones.
+	MaxFingerprints int
+	// MaxAge, if positive, drops the fingerprints, which have not failed for
+	// the duration.
+	MaxAge time.Duration
+	// OnEvict, if not nil, is called with the dropped statistics.
+	OnEvict func(FailureStat)
+
+	mux   sync.Mutex
+	stats map[string]*list.Element
+	// recent orders the statistics by the last failure, the latest first.
+	recent list.List
+}
+
+// retain applies the limits, and returns the dropped statistics. Must be
+// called under the lock.
+func (s *FailureStats) retain(now time.Time) []FailureStat {
+	var evicted []FailureStat
+	for oldest := s.recent.Back(); oldest != nil; oldest = s.recent.Back() {
+		stat := oldest.Value.(*FailureStat)
+		if (s.MaxFingerprints <= 0 || s.recent.Len() <= s.MaxFingerprints) &&
+			(s.MaxAge <= 0 || !stat.Last.Before(now.Add(-s.MaxAge))) {
+			break
+		}
+		s.recent.Remove(oldest)
+		delete(s.stats, stat.Fingerprint)
+		evicted = append(evicted, *stat)
+	}
+	return evicted
+}
+
+// evict calls OnEvict with the dropped statistics.
+func (s *FailureStats) evict(evicted []FailureStat) {
+	if s.OnEvict == nil {
+		return
+	}
+	for _, stat := range evicted {
+		s.OnEvict(stat)
+	}
+}
+
+// Add accounts the error.
+func (s *FailureStats) Add(err error) {
+	if err == nil {
+		return
+	}
+	fingerprint := Fingerprint(err)
+	now := time.Now()
+	s.mux.Lock()
+	if s.stats == nil {
+		s.stats = make(map[string]*list.Element)
+	}
+	elem, ok := s.stats[fingerprint]
+	if ok {
+		s.recent.MoveToFront(elem)
+	} else {
+		elem = s.recent.PushFront(&FailureStat{Fingerprint: fingerprint, First: now})
+		s.stats[fingerprint] = elem
+	}
+	stat := elem.Value.(*FailureStat)
+	stat.Count++
+	stat.Last = now
+	stat.LastErr = err
+	evicted := s.retain(now)
+	s.mux.Unlock()
+	s.evict(evicted)
+}
+
+// Reset drops the statistics of all failures without calling OnEvict.
+func (s *FailureStats) Reset() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.stats = nil
+	s.recent.Init()
+}
+
+// Get returns the statistics of the failures with the fingerprint.
+func (s *FailureStats) Get(fingerprint string) (FailureStat, bool) {
+	s.mux.Lock()
+	evicted := s.retain(time.Now())
+	elem, ok := s.stats[fingerprint]
+	stat := FailureStat{}
+	if ok {
+		stat = *elem.Value.(*FailureStat)
+	}
+	s.mux.Unlock()
+	s.evict(evicted)
+	return stat, ok
+}
+
+// All returns the statistics of all failures, the most recent first.
+func (s *FailureStats) All() []FailureStat {
+	s.mux.Lock()
+	evicted := s.retain(time.Now())
+	stats := make([]FailureStat, 0, s.recent.Len())
+	for elem := s.recent.Front(); elem != nil; elem = elem.Next() {
+		stats = append(stats, *elem.Value.(*FailureStat))
+	}
+	s.mux.Unlock()
+	s.evict(evicted)
+	return stats
+}
+
+// TrackFailures recovers the task panics, and accounts the task errors in the
+// stats.
+func TrackFailures[TickType any, Fn Func[TickType]](stats *FailureStats, task Fn) func(context.Context, TickType) error {
+	recovered := Recover[TickType](task)
+	return func(ctx context.Context, tick TickType) error {
+		err := recovered(ctx, tick)
+		stats.Add(err)
+		return err
+	}
+}
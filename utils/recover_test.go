@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func panicking() {
+	panic("oops")
+}
+
+func TestRecover(t *testing.T) {
+	err := Recover[any](panicking)(context.Background(), nil)
+	var panicErr *PanicError
+	assert.That(t,
+		assert.ErrorIs(err, ErrPanic),
+		assert.True(errors.As(err, &panicErr)),
+		assert.Equal("panic: oops", err.Error()))
+	assert.That(t,
+		assert.Equal("github.com/parametalol/goticks/utils.panicking", panicErr.Frames[0]))
+
+	errTest := errors.New("test")
+	err = Recover[any](func() { panic(errTest) })(context.Background(), nil)
+	assert.That(t,
+		assert.ErrorIs(err, ErrPanic),
+		assert.ErrorIs(err, errTest))
+}
+
+func TestFingerprint(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	panicErr := Recover[any](panicking)(context.Background(), nil)
+
+	assert.That(t,
+		assert.Equal("", Fingerprint(nil)),
+		assert.Equal(Fingerprint(errA), Fingerprint(errors.New("a"))),
+		assert.Not(assert.Equal(Fingerprint(errA), Fingerprint(errB))),
+		assert.Equal(Fingerprint(fmt.Errorf("x: %w", errA)), Fingerprint(fmt.Errorf("y: %w", errA))),
+		assert.Not(assert.Equal(Fingerprint(errA), Fingerprint(fmt.Errorf("x: %w", errA)))),
+		assert.Equal(Fingerprint(panicErr), Fingerprint(Recover[any](panicking)(context.Background(), nil))),
+		assert.Not(assert.Equal(Fingerprint(panicErr), Fingerprint(Recover[any](func() { panic("oops") })(context.Background(), nil)))))
+}
+
+func TestTrackFailures(t *testing.T) {
+	stats := &FailureStats{}
+	errTest := errors.New("test")
+	task := TrackFailures[int](stats, func(tick int) error {
+		switch tick {
+		case 0:
+			return nil
+		case 1:
+			panicking()
+		}
+		return errTest
+	})
+	for tick := range 4 {
+		_ = task(context.Background(), tick)
+	}
+	stat, ok := stats.Get(Fingerprint(errTest))
+	assert.That(t,
+		assert.Equal(2, len(stats.All())),
+		assert.True(ok),
+		assert.Equal(2, stat.Count),
+		assert.ErrorIs(stat.LastErr, errTest))
+}
+
+func TestFailureStats_retention(t *testing.T) {
+	var evicted []string
+	stats := &FailureStats{MaxFingerprints: 2, OnEvict: func(stat FailureStat) {
+		evicted = append(evicted, stat.LastErr.Error())
+	}}
+	errA, errB, errC := errors.New("a"), errors.New("b"), fmt.Errorf("c: %w", errors.New("c"))
+	stats.Add(errA)
+	stats.Add(errB)
+	stats.Add(errA)
+	stats.Add(errC)
+	_, okB := stats.Get(Fingerprint(errB))
+	all := stats.All()
+	assert.That(t,
+		assert.EqualSlices([]string{"b"}, evicted),
+		assert.False(okB),
+		assert.Equal(2, len(all)),
+		assert.ErrorIs(all[0].LastErr, errC),
+		assert.ErrorIs(all[1].LastErr, errA))
+
+	stats.MaxAge = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	assert.That(t,
+		assert.Equal(0, len(stats.All())),
+		assert.EqualSlices([]string{"b", "a", "c: c"}, evicted))
+}
@@ -0,0 +1,70 @@
+package utils
+
+import "time"
+
+// timed is an item of the ring with its time.
+type timed[T any] struct {
+	at   time.Time
+	item T
+}
+
+// ring is a bounded FIFO of the timed items, which drops the oldest ones on
+// overflow or expiration in O(1) per item. The zero value has no capacity, and
+// must be resized before use.
+type ring[T any] struct {
+	items []timed[T]
+	head  int
+	size  int
+}
+
+// resize sets the capacity to limit, dropping the oldest items over it into
+// evicted. It copies the items only if the capacity changes.
+func (r *ring[T]) resize(limit int, evicted *[]T) {
+	if len(r.items) == limit {
+		return
+	}
+	for r.size > limit {
+		*evicted = append(*evicted, r.pop())
+	}
+	items := make([]timed[T], limit)
+	for i := range r.size {
+		items[i] = r.items[(r.head+i)%len(r.items)]
+	}
+	r.items, r.head = items, 0
+}
+
+// push appends the item, dropping the oldest one into evicted if the ring is
+// full.
+func (r *ring[T]) push(at time.Time, item T, evicted *[]T) {
+	if r.size == len(r.items) {
+		*evicted = append(*evicted, r.pop())
+	}
+	r.items[(r.head+r.size)%len(r.items)] = timed[T]{at, item}
+	r.size++
+}
+
+// pop removes and returns the oldest item.
+func (r *ring[T]) pop() T {
+	oldest := r.items[r.head].item
+	r.items[r.head] = timed[T]{}
+	r.head = (r.head + 1) % len(r.items)
+	r.size--
+	return oldest
+}
+
+// expire drops the items older than before into evicted.
+func (r *ring[T]) expire(before time.Time, evicted *[]T) {
+	for r.size > 0 && r.items[r.head].at.Before(before) {
+		*evicted = append(*evicted, r.pop())
+	}
+}
+
+// len returns the number of the items.
+func (r *ring[T]) len() int {
+	return r.size
+}
+
+// at returns the i-th item, the oldest first.
+func (r *ring[T]) at(i int) T {
+	return r.items[(r.head+i)%len(r.items)].item
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RetryBudget is a token bucket of retries, shared by the retry policies of
+// multiple tasks, so that a widespread outage does not multiply the load by
+// the simultaneous retries everywhere.
+type RetryBudget struct {
+	mux      sync.Mutex
+	capacity float64
+	rate     float64 // tokens per second
+	tokens   float64
+	last     time.Time
+	denied   uint64
+}
+
+// NewRetryBudget returns a budget, allowing up to retries retries per window.
+// The budget is refilled continuously, and starts full.
+func NewRetryBudget(retries int, window time.Duration) *RetryBudget {
+	return &RetryBudget{
+		capacity: float64(retries),
+		rate:     float64(retries) / window.Seconds(),
+		tokens:   float64(retries),
+		last:     time.Now(),
+	}
+}
+
+// take takes a token from the budget if there is one.
+func (b *RetryBudget) take() bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	now := time.Now()
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens < 1 {
+		b.denied++
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refund returns the taken token to the budget.
+func (b *RetryBudget) refund() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.tokens = min(b.capacity, b.tokens+1)
+}
+
+// Denied returns the number of the retries, denied by the budget.
+func (b *RetryBudget) Denied() uint64 {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.denied
+}
+
+// Policy returns the retry policy, that retries as the given one does while
+// there are tokens in the budget. Every retry of a failed attempt takes a
+// token, which is taken before calling the given policy, so that the policy
+// does not wait for the backoff in vain, and is returned if the policy
+// declines the retry.
+//
+// Example:
+//
+//	budget := NewRetryBudget(10, time.Minute)
+//	Retry[time.Time](budget.Policy(SimpleRetryPolicy(3)), task)
+func (b *RetryBudget) Policy(policy RetryPolicy) RetryPolicy {
+	return func(ctx context.Context, i int, err error) bool {
+		if err == nil {
+			return policy(ctx, i, err)
+		}
+		if !b.take() {
+			return false
+		}
+		if !policy(ctx, i, err) {
+			b.refund()
+			return false
+		}
+		return true
+	}
+}
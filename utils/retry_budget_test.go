@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestRetryBudget(t *testing.T) {
+	budget := NewRetryBudget(3, time.Hour)
+	attempts := 0
+	failing := func() error {
+		attempts++
+		return errors.New("failed")
+	}
+	policy := budget.Policy(SimpleRetryPolicy(3))
+	taskA := Retry[any](policy, failing)
+	taskB := Retry[any](policy, failing)
+
+	_ = taskA(context.Background(), nil)
+	assert.That(t, assert.Equal(3, attempts), assert.Equal(uint64(0), budget.Denied()))
+	_ = taskB(context.Background(), nil)
+	assert.That(t, assert.Equal(5, attempts), assert.Equal(uint64(1), budget.Denied()))
+	_ = taskA(context.Background(), nil)
+	assert.That(t, assert.Equal(6, attempts), assert.Equal(uint64(2), budget.Denied()))
+
+	t.Run("no backoff without tokens", func(t *testing.T) {
+		budget := NewRetryBudget(1, time.Hour)
+		policy := budget.Policy(ExponentialBackoffPolicy(3, time.Hour))
+		assert.That(t, assert.True(budget.take()))
+		start := time.Now()
+		assert.That(t,
+			assert.False(policy(context.Background(), 0, errors.New("failed"))),
+			assert.True(time.Since(start) < time.Minute),
+			assert.Equal(uint64(1), budget.Denied()))
+		// The declined retry returns the token.
+		budget.refund()
+		assert.That(t,
+			assert.False(budget.Policy(SimpleRetryPolicy(1))(context.Background(), 0, errors.New("failed"))),
+			assert.True(budget.take()))
+	})
+
+	t.Run("refill", func(t *testing.T) {
+		budget := NewRetryBudget(1, 10*time.Millisecond)
+		assert.That(t, assert.True(budget.take()), assert.False(budget.take()))
+		time.Sleep(20 * time.Millisecond)
+		assert.That(t, assert.True(budget.take()))
+	})
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+type retryHintCtxKey struct{}
+
+// retryHint is the retry time, suggested by a [Retry] attempt.
+type retryHint struct {
+	mux sync.Mutex
+	at  time.Time
+}
+
+// withRetryHint returns the context, which the attempt can suggest the retry
+// time to.
+func withRetryHint(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryHintCtxKey{}, &retryHint{})
+}
+
+// SuggestRetryAt suggests to the cooperating retry policies, see
+// [HonorRetryAt], not to retry the failed attempt before the time, e.g. given
+// by the Retry-After response header of the remote side. The suggestion has no
+// effect outside of [Retry].
+func SuggestRetryAt(ctx context.Context, at time.Time) {
+	if hint, ok := ctx.Value(retryHintCtxKey{}).(*retryHint); ok {
+		hint.mux.Lock()
+		defer hint.mux.Unlock()
+		hint.at = at
+	}
+}
+
+// SuggestedRetryAt returns the retry time, suggested by the last attempt with
+// [SuggestRetryAt], or false if there is no suggestion.
+func SuggestedRetryAt(ctx context.Context) (time.Time, bool) {
+	if hint, ok := ctx.Value(retryHintCtxKey{}).(*retryHint); ok {
+		hint.mux.Lock()
+		defer hint.mux.Unlock()
+		return hint.at, !hint.at.IsZero()
+	}
+	return time.Time{}, false
+}
+
+// HonorRetryAt returns the retry policy, which, if the failed attempt has
+// suggested the retry time with [SuggestRetryAt], waits until that time before
+// consulting the given policy, so that the remote side dictates the pacing.
+// The waiting is interrupted by the context cancellation, in which case the
+// attempt is not retried.
+//
+// Example:
+//
+//	Retry(HonorRetryAt(SimpleRetryPolicy(5)), func(ctx context.Context) error {
+//		...
+//		if resp.StatusCode == http.StatusTooManyRequests {
+//			SuggestRetryAt(ctx, time.Now().Add(retryAfter))
+//		}
+//	})
+func HonorRetryAt(policy RetryPolicy) RetryPolicy {
+	return func(ctx context.Context, i int, err error) bool {
+		if at, ok := SuggestedRetryAt(ctx); ok && err != nil {
+			if wait := time.Until(at); wait > 0 {
+				timer := time.NewTimer(timescale.Scale(wait))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return false
+				}
+			}
+		}
+		return policy(ctx, i, err)
+	}
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestHonorRetryAt(t *testing.T) {
+	errLimited := errors.New("rate limited")
+	var starts []time.Time
+	task := Retry[any](HonorRetryAt(SimpleRetryPolicy(3)), func(ctx context.Context) error {
+		starts = append(starts, time.Now())
+		if len(starts) == 1 {
+			SuggestRetryAt(ctx, time.Now().Add(30*time.Millisecond))
+			return errLimited
+		}
+		if _, ok := SuggestedRetryAt(ctx); ok {
+			return errors.New("the suggestion of the previous attempt leaked")
+		}
+		return nil
+	})
+	assert.That(t,
+		assert.NoError(task(context.Background(), nil)),
+		assert.Equal(2, len(starts)),
+		assert.True(starts[1].Sub(starts[0]) >= 30*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	task = Retry[any](HonorRetryAt(SimpleRetryPolicy(3)), func(ctx context.Context) error {
+		attempts++
+		SuggestRetryAt(ctx, time.Now().Add(time.Hour))
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return errLimited
+	})
+	assert.That(t,
+		assert.ErrorIs(task(ctx, nil), errLimited),
+		assert.Equal(1, attempts))
+
+	SuggestRetryAt(context.Background(), time.Now())
+	_, ok := SuggestedRetryAt(context.Background())
+	assert.That(t, assert.False(ok))
+}
@@ -0,0 +1,224 @@
+package utils
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Default limits of [RunLogs].
+const (
+	DefaultRunLogRecords = 100
+	DefaultRunLogRuns    = 10
+)
+
+// RunLog is the log of a task run, captured by [CaptureRunLog].
+type RunLog struct {
+	Start time.Time
+	Err   error
+	// Records are the first records of the run, logged at any level.
+	Records []slog.Record
+	// Dropped is the number of the records over the limit.
+	Dropped int
+}
+
+// RunLogs keeps the logs of the recent runs, captured by [CaptureRunLog].
+type RunLogs struct {
+	// Records limits the number of the records per run. Defaults to
+	// [DefaultRunLogRecords].
+	Records int
+	// Runs limits the number of the kept runs. Defaults to
+	// [DefaultRunLogRuns].
+	Runs int
+	// MaxAge, if positive, drops the logs of the runs started earlier.
+	MaxAge time.Duration
+	// OnEvict, if not nil, is called with the dropped logs, e.g. to archive
+	// them.
+	OnEvict func(RunLog)
+
+	mux  sync.Mutex
+	runs ring[RunLog]
+}
+
+// retain applies the limits, and returns the dropped logs. Must be called
+// under the lock.
+func (l *RunLogs) retain() []RunLog {
+	limit := l.Runs
+	if limit <= 0 {
+		limit = DefaultRunLogRuns
+	}
+	var evicted []RunLog
+	l.runs.resize(limit, &evicted)
+	if l.MaxAge > 0 {
+		l.runs.expire(time.Now().Add(-l.MaxAge), &evicted)
+	}
+	return evicted
+}
+
+// evict calls OnEvict with the dropped logs.
+func (l *RunLogs) evict(evicted []RunLog) {
+	if l.OnEvict == nil {
+		return
+	}
+	for _, run := range evicted {
+		l.OnEvict(run)
+	}
+}
+
+func (l *RunLogs) add(run RunLog) {
+	l.mux.Lock()
+	evicted := l.retain()
+	l.runs.push(run.Start, run, &evicted)
+	l.mux.Unlock()
+	l.evict(evicted)
+}
+
+// Reset drops the kept logs without calling OnEvict.
+func (l *RunLogs) Reset() {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.runs = ring[RunLog]{}
+}
+
+// Recent returns the logs of the kept runs, the oldest first.
+func (l *RunLogs) Recent() []RunLog {
+	l.mux.Lock()
+	evicted := l.retain()
+	runs := make([]RunLog, l.runs.len())
+	for i := range runs {
+		runs[i] = l.runs.at(i)
+	}
+	l.mux.Unlock()
+	l.evict(evicted)
+	return runs
+}
+
+// LastFailed returns the log of the last kept failed run.
+func (l *RunLogs) LastFailed() (RunLog, bool) {
+	l.mux.Lock()
+	evicted := l.retain()
+	run, ok := RunLog{}, false
+	for i := l.runs.len() - 1; i >= 0; i-- {
+		if r := l.runs.at(i); r.Err != nil {
+			run, ok = r, true
+			break
+		}
+	}
+	l.mux.Unlock()
+	l.evict(evicted)
+	return run, ok
+}
+
+// runLogBuffer collects the records of a run.
+type runLogBuffer struct {
+	mux   sync.Mutex
+	limit int
+	log   RunLog
+}
+
+func (b *runLogBuffer) add(r slog.Record) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if len(b.log.Records) >= b.limit {
+		b.log.Dropped++
+		return
+	}
+	b.log.Records = append(b.log.Records, r)
+}
+
+// runLogHandler buffers all records, and passes the enabled ones to the next
+// handler.
+type runLogHandler struct {
+	buffer *runLogBuffer
+	next   slog.Handler
+	// attrs are the attributes of WithAttrs, nested in the groups, open at
+	// the time, and groups are the open groups.
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *runLogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func nest(groups []string, attrs []slog.Attr) []slog.Attr {
+	for i := len(groups) - 1; i >= 0 && len(attrs) > 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
+
+func (h *runLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	buffered := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	buffered.AddAttrs(h.attrs...)
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	buffered.AddAttrs(nest(h.groups, attrs)...)
+	h.buffer.add(buffered)
+	if h.next.Enabled(ctx, r.Level) {
+		return h.next.Handle(ctx, r)
+	}
+	return nil
+}
+
+func (h *runLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &runLogHandler{
+		buffer: h.buffer,
+		next:   h.next.WithAttrs(attrs),
+		attrs:  append(slices.Clip(h.attrs), nest(h.groups, attrs)...),
+		groups: h.groups,
+	}
+}
+
+func (h *runLogHandler) WithGroup(name string) slog.Handler {
+	return &runLogHandler{
+		buffer: h.buffer,
+		next:   h.next.WithGroup(name),
+		attrs:  h.attrs,
+		groups: append(slices.Clip(h.groups), name),
+	}
+}
+
+type loggerCtxKey struct{}
+
+// LoggerFromContext returns the run logger, provided by [CaptureRunLog], or
+// [slog.Default] if there is none.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerCtxKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// CaptureRunLog provides the task runs with a logger, see
+// [LoggerFromContext], which records are kept in the logs with the run error,
+// regardless of the level of the handler, so that the full log of a failed run
+// is available. The records are also passed to the handler, if enabled by it.
+// If the handler is nil, the handler of [slog.Default] is used.
+func CaptureRunLog[TickType any, Fn Func[TickType]](logs *RunLogs, handler slog.Handler, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("CaptureRunLog", task)
+	return func(ctx context.Context, tick TickType) error {
+		next := handler
+		if next == nil {
+			next = slog.Default().Handler()
+		}
+		limit := logs.Records
+		if limit <= 0 {
+			limit = DefaultRunLogRecords
+		}
+		buffer := &runLogBuffer{limit: limit, log: RunLog{Start: time.Now()}}
+		logger := slog.New(&runLogHandler{buffer: buffer, next: next})
+		err := adaptedTask(context.WithValue(ctx, loggerCtxKey{}, logger), tick)
+		buffer.mux.Lock()
+		run := buffer.log
+		buffer.mux.Unlock()
+		run.Err = err
+		logs.add(run)
+		return err
+	}
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestCaptureRunLog(t *testing.T) {
+	var out bytes.Buffer
+	handler := slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelWarn})
+	logs := &RunLogs{Records: 3, Runs: 2}
+	errFailed := errors.New("failed")
+	i := 0
+	task := CaptureRunLog[any](logs, handler, func(ctx context.Context) error {
+		i++
+		logger := LoggerFromContext(ctx).With("run", i).WithGroup("g")
+		logger.Debug("debug", "k", "v")
+		if i == 2 {
+			logger.Warn("warning")
+			logger.Info("one")
+			logger.Info("two")
+			return errFailed
+		}
+		return nil
+	})
+	for range 3 {
+		_ = task(context.Background(), nil)
+	}
+
+	recent := logs.Recent()
+	failed, ok := logs.LastFailed()
+	assert.That(t,
+		assert.Equal(2, len(recent)),
+		assert.True(ok),
+		assert.ErrorIs(failed.Err, errFailed),
+		assert.Equal(3, len(failed.Records)),
+		assert.Equal(1, failed.Dropped),
+		assert.Equal("debug", failed.Records[0].Message),
+		assert.Equal(slog.LevelDebug, failed.Records[0].Level),
+		assert.Equal(1, strings.Count(out.String(), "\n")),
+		assert.True(strings.Contains(out.String(), "msg=warning run=2")))
+
+	var attrs []string
+	failed.Records[0].Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a.String())
+		return true
+	})
+	assert.That(t,
+		assert.EqualSlices([]string{"run=2", "g=[k=v]"}, attrs),
+		assert.Equal[*slog.Logger](slog.Default(), LoggerFromContext(context.Background())))
+}
+
+func TestRunLogs_retention(t *testing.T) {
+	var evicted []time.Time
+	logs := &RunLogs{Runs: 2, OnEvict: func(run RunLog) {
+		evicted = append(evicted, run.Start)
+	}}
+	now := time.Now()
+	for i := range 3 {
+		logs.add(RunLog{Start: now.Add(time.Duration(i-3) * time.Hour)})
+	}
+	assert.That(t,
+		assert.EqualSlices([]time.Time{now.Add(-3 * time.Hour)}, evicted),
+		assert.Equal(2, len(logs.Recent())))
+
+	logs.Runs = 3
+	logs.add(RunLog{Start: now, Err: errors.New("failed")})
+	assert.That(t, assert.Equal(3, len(logs.Recent())))
+
+	logs.MaxAge = 90 * time.Minute
+	failed, ok := logs.LastFailed()
+	recent := logs.Recent()
+	assert.That(t,
+		assert.True(ok),
+		assert.Equal(now, failed.Start),
+		assert.Equal(2, len(recent)),
+		assert.Equal(now.Add(-time.Hour), recent[0].Start),
+		assert.Equal(2, len(evicted)))
+}
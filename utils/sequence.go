@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// TickSequence numbers the ticks with the monotonically increasing sequence
+// numbers, distinct from the run IDs, and counts the gaps: the ticks, which
+// have not produced a run, being skipped, e.g. by [NoOverlap], [LoadShedding]
+// or by [InPool] on overflow.
+type TickSequence struct {
+	// OnGap, if not nil, is called with the sequence number of every tick,
+	// which has not produced a run, and the skip reason.
+	OnGap func(seq uint64, reason string)
+
+	last atomic.Uint64
+	gaps atomic.Uint64
+}
+
+// Last returns the sequence number of the last tick, or 0 if there was none.
+func (s *TickSequence) Last() uint64 {
+	return s.last.Load()
+}
+
+// Gaps returns the number of the ticks, which have not produced a run.
+func (s *TickSequence) Gaps() uint64 {
+	return s.gaps.Load()
+}
+
+type tickSeqCtxKey struct{}
+
+// TickSeqFromContext returns the sequence number of the tick, given by
+// [Sequence], or false if the tick is not numbered.
+func TickSeqFromContext(ctx context.Context) (uint64, bool) {
+	seq, ok := ctx.Value(tickSeqCtxKey{}).(uint64)
+	return seq, ok
+}
+
+// Sequence numbers every tick in the sequence, passing the number in the
+// context, see [TickSeqFromContext], and accounts the tick as a gap if the
+// inner wrappers have skipped the run with [Skip], and no error is returned,
+// or if the returned error wraps [ErrSkipped].
+func Sequence[TickType any, Fn Func[TickType]](s *TickSequence, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Sequence", task)
+	return func(ctx context.Context, tick TickType) error {
+		seq := s.last.Add(1)
+		ctx, recorder := withSkipRecorder(context.WithValue(ctx, tickSeqCtxKey{}, seq))
+		err := adaptedTask(ctx, tick)
+		if skipped, reason := recorder.finish(err); skipped {
+			s.gaps.Add(1)
+			if s.OnGap != nil {
+				s.OnGap(seq, reason)
+			}
+		}
+		return err
+	}
+}
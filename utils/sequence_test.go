@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestSequence(t *testing.T) {
+	var gaps []uint64
+	var reasons []string
+	s := &TickSequence{OnGap: func(seq uint64, reason string) {
+		gaps = append(gaps, seq)
+		reasons = append(reasons, reason)
+	}}
+	var seqs []uint64
+	var outcomes []RunOutcome
+	task := Sequence[int](s, Classify[int](func(r RunResult) { outcomes = append(outcomes, r.Outcome) },
+		When[int](func(_ context.Context, tick int) bool { return tick%2 == 0 }, "odd",
+			func(ctx context.Context, tick int) error {
+				seq, _ := TickSeqFromContext(ctx)
+				seqs = append(seqs, seq)
+				if tick == 4 {
+					return errors.New("failed")
+				}
+				return nil
+			})))
+	for tick := range 5 {
+		_ = task(context.Background(), tick)
+	}
+	assert.That(t,
+		assert.EqualSlices([]uint64{1, 3, 5}, seqs),
+		assert.EqualSlices([]uint64{2, 4}, gaps),
+		assert.EqualSlices([]string{"odd", "odd"}, reasons),
+		assert.EqualSlices([]RunOutcome{RunExecuted, RunSkipped, RunExecuted, RunSkipped, RunFailed}, outcomes),
+		assert.Equal(uint64(5), s.Last()),
+		assert.Equal(uint64(2), s.Gaps()))
+
+	_, ok := TickSeqFromContext(context.Background())
+	assert.That(t, assert.False(ok))
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// Shaper limits the aggregate rate of the run starts of the tasks, that share
+// it, to protect their common downstream dependencies from the combined load.
+type Shaper struct {
+	mux   sync.Mutex
+	limit int
+	// starts are the run start times within the last second, in order.
+	starts []time.Time
+}
+
+// NewShaper returns a shaper, allowing perSecond run starts per second.
+func NewShaper(perSecond int) *Shaper {
+	return &Shaper{limit: max(perSecond, 1)}
+}
+
+// Rate returns the number of the run starts within the last second.
+func (s *Shaper) Rate() int {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.prune(time.Now())
+	return len(s.starts)
+}
+
+// prune drops the starts older than a second. Must be called under the lock.
+func (s *Shaper) prune(now time.Time) {
+	i := 0
+	for i < len(s.starts) && now.Sub(s.starts[i]) >= time.Second {
+		i++
+	}
+	s.starts = s.starts[i:]
+}
+
+// admit accounts the run start if it is allowed now, or returns the time to
+// wait otherwise.
+func (s *Shaper) admit(lowPriority bool) time.Duration {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now := time.Now()
+	s.prune(now)
+	if lowPriority && len(s.starts) >= s.limit {
+		return s.starts[len(s.starts)-s.limit].Add(time.Second).Sub(now)
+	}
+	s.starts = append(s.starts, now)
+	return 0
+}
+
+// Shape delays the runs of the low priority task while the aggregate rate of
+// the run starts of the shaped tasks exceeds the shaper limit. The runs of the
+// other tasks are never delayed, but are accounted. The context cause is
+// returned if the context is done while waiting.
+func Shape[TickType any, Fn Func[TickType]](s *Shaper, lowPriority bool, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Shape", task)
+	return func(ctx context.Context, tick TickType) error {
+		for {
+			wait := s.admit(lowPriority)
+			if wait <= 0 {
+				break
+			}
+			timer := time.NewTimer(timescale.Scale(wait))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return context.Cause(ctx)
+			}
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestShape(t *testing.T) {
+	s := NewShaper(2)
+	high := Shape[any](s, false, func() {})
+	low := Shape[any](s, true, func() {})
+
+	start := time.Now()
+	assert.That(t,
+		assert.NoError(low(context.Background(), nil)),
+		assert.NoError(high(context.Background(), nil)),
+		assert.NoError(high(context.Background(), nil)),
+		assert.Equal(3, s.Rate()),
+		assert.True(time.Since(start) < 500*time.Millisecond))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrStopped)
+	assert.That(t, assert.ErrorIs(low(ctx, nil), ErrStopped))
+
+	assert.That(t,
+		assert.NoError(low(context.Background(), nil)),
+		assert.True(time.Since(start) >= time.Second))
+}
@@ -0,0 +1,62 @@
+package utils
+
+import "context"
+
+type wrapperStackCtxKey struct{}
+
+// wrapperFrame is an element of the linked wrapper stack in the run context.
+// The bottom frame, put by [WithWrapperStack], has no name.
+type wrapperFrame struct {
+	name   string
+	parent *wrapperFrame
+}
+
+// WithWrapperStack returns the context, under which the wrappers of this
+// package record themselves in the wrapper stack of the run context, see
+// [WrapperStackFromContext]. The recording costs a context value per wrapper
+// per run, and is disabled without this context.
+func WithWrapperStack(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(wrapperStackCtxKey{}).(*wrapperFrame); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, wrapperStackCtxKey{}, &wrapperFrame{})
+}
+
+// adaptIn adapts the task, wrapped by the named wrapper, so that the wrapper
+// name is pushed to the wrapper stack of the task context, if it is enabled by
+// [WithWrapperStack].
+func adaptIn[TickType any, Fn Func[TickType]](wrapper string, task Fn) func(context.Context, TickType) error {
+	adaptedTask := Adapt[TickType](task)
+	return func(ctx context.Context, tick TickType) error {
+		if parent, ok := ctx.Value(wrapperStackCtxKey{}).(*wrapperFrame); ok {
+			ctx = context.WithValue(ctx, wrapperStackCtxKey{}, &wrapperFrame{wrapper, parent})
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
+
+// WrapperStackFromContext returns the names of the wrappers of this package,
+// which the run has passed through, from the outermost, e.g. to annotate the
+// errors or the traces. The stack is empty, unless the run context is derived
+// from [WithWrapperStack].
+//
+// Example:
+//
+//	task := Retry(policy, Timeout(time.Second, func(ctx context.Context) error {
+//		if err := call(ctx); err != nil {
+//			// failed inside Retry>Timeout: ...
+//			return fmt.Errorf("failed inside %s: %w", strings.Join(WrapperStackFromContext(ctx), ">"), err)
+//		}
+//		return nil
+//	}))
+//	err := task(WithWrapperStack(ctx), tick)
+func WrapperStackFromContext(ctx context.Context) []string {
+	var stack []string
+	for frame, _ := ctx.Value(wrapperStackCtxKey{}).(*wrapperFrame); frame != nil && frame.parent != nil; frame = frame.parent {
+		stack = append(stack, frame.name)
+	}
+	for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+		stack[i], stack[j] = stack[j], stack[i]
+	}
+	return stack
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestWrapperStackFromContext(t *testing.T) {
+	var stacks [][]string
+	task := Retry[any](SimpleRetryPolicy(2), Timeout[any](time.Minute, func(ctx context.Context) error {
+		stacks = append(stacks, WrapperStackFromContext(ctx))
+		return ErrTimeout
+	}))
+	_ = task(WithWrapperStack(context.Background()), nil)
+	assert.That(t,
+		assert.Equal(2, len(stacks)),
+		assert.EqualSlices([]string{"Retry", "Timeout"}, stacks[0]),
+		assert.EqualSlices([]string{"Retry", "Timeout"}, stacks[1]),
+		assert.Equal(0, len(WrapperStackFromContext(context.Background()))))
+
+	stacks = nil
+	_ = task(context.Background(), nil)
+	assert.That(t,
+		assert.Equal(2, len(stacks)),
+		assert.Equal(0, len(stacks[0])))
+
+	stacks = nil
+	_ = RetryCancelBetweenAttempts[any](SimpleRetryPolicy(1), func(ctx context.Context) {
+		stacks = append(stacks, WrapperStackFromContext(ctx))
+	})(WithWrapperStack(context.Background()), nil)
+	assert.That(t, assert.EqualSlices([]string{"RetryCancelBetweenAttempts"}, stacks[0]))
+}
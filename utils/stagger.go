@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+)
+
+// Staggered executes the tasks in order as [Seq] does, but starts every task
+// not earlier than its offset from the start of the run, so that the load of
+// the steps is spread over the tick period instead of a burst at the tick
+// time. A task without an offset starts right after the previous one.
+// If the context is cancelled while waiting, the execution stops and returns
+// the context cause. The run is skipped, see [Skip], only if all the tasks are
+// skipped.
+func Staggered[TickType any](offsets []time.Duration, tasks ...func(context.Context, TickType) error) func(context.Context, TickType) error {
+	return func(ctx context.Context, tick TickType) error {
+		start := time.Now()
+		skips := newStepSkips(ctx)
+		for i, task := range tasks {
+			if task == nil {
+				continue
+			}
+			if i < len(offsets) {
+				if wait := time.Until(start.Add(timescale.Scale(offsets[i]))); wait > 0 {
+					timer := time.NewTimer(wait)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return context.Cause(ctx)
+					case <-timer.C:
+					}
+				}
+			}
+			if err := task(skips.step(ctx), tick); err != nil {
+				return err
+			}
+		}
+		skips.done()
+		return nil
+	}
+}
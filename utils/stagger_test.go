@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestStaggered(t *testing.T) {
+	var starts []time.Duration
+	var start time.Time
+	step := func(context.Context, any) error {
+		starts = append(starts, time.Since(start))
+		return nil
+	}
+	task := Staggered([]time.Duration{0, 20 * time.Millisecond, 10 * time.Millisecond}, step, step, step, step)
+	start = time.Now()
+	assert.That(t,
+		assert.NoError(task(context.Background(), nil)),
+		assert.Equal(4, len(starts)),
+		assert.True(starts[0] < 10*time.Millisecond),
+		assert.True(starts[1] >= 20*time.Millisecond),
+		assert.True(starts[2] >= starts[1]),
+		assert.True(starts[3] >= starts[2]))
+
+	errCancel := errors.New("cancelled")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errCancel)
+	starts = nil
+	assert.That(t,
+		assert.ErrorIs(Staggered([]time.Duration{0, time.Hour}, step, step)(ctx, nil), errCancel),
+		assert.Equal(1, len(starts)))
+}
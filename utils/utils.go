@@ -10,10 +10,15 @@ import (
 	"time"
 
 	"github.com/parametalol/curry"
+	"github.com/parametalol/goticks/internal/timescale"
 )
 
 var ErrStopped = errors.New("stopped")
 
+// ErrTimeout is wrapped by the cause of the task context cancellation on
+// timeout.
+var ErrTimeout = errors.New("timeout")
+
 type attemptNumberCtxKey struct{}
 
 var AttemptNumber attemptNumberCtxKey
@@ -31,31 +36,96 @@ func AdaptT[Fn Func[time.Time]](task Fn) func(context.Context, time.Time) error
 	return Adapt[time.Time](task)
 }
 
-// Seq executes a sequence of tasks in order.
+// ErrNilTask is returned by [StrictSeq] for a nil task.
+var ErrNilTask = errors.New("nil task")
+
+// Seq executes a sequence of tasks in order. Nil tasks are skipped, so that
+// optional steps can be included conditionally, see [Optional].
 // If one of the tasks fails, the execution stops and returns the error.
+// The run is skipped, see [Skip], only if all the tasks are skipped.
 func Seq[TickType any](tasks ...func(context.Context, TickType) error) func(context.Context, TickType) error {
 	return func(ctx context.Context, tick TickType) error {
+		skips := newStepSkips(ctx)
 		for _, task := range tasks {
-			if err := task(ctx, tick); err != nil {
+			if task == nil {
+				continue
+			}
+			if err := task(skips.step(ctx), tick); err != nil {
 				return err
 			}
 		}
+		skips.done()
 		return nil
 	}
 }
 
+// StrictSeq returns [Seq] of the tasks, or an error, wrapping [ErrNilTask], if
+// any of the tasks is nil.
+func StrictSeq[TickType any](tasks ...func(context.Context, TickType) error) (func(context.Context, TickType) error, error) {
+	for i, task := range tasks {
+		if task == nil {
+			return nil, fmt.Errorf("task #%d: %w", i, ErrNilTask)
+		}
+	}
+	return Seq(tasks...), nil
+}
+
+// Optional returns the adapted task if include is true, or nil, skipped by
+// [Seq], otherwise.
+//
+// Example:
+//
+//	Seq(fetch, Optional[time.Time](cfg.Notify, notify), store)
+func Optional[TickType any, Fn Func[TickType]](include bool, task Fn) func(context.Context, TickType) error {
+	if !include {
+		return nil
+	}
+	return Adapt[TickType](task)
+}
+
 // IgnoreErr wraps a task and ignores its error.
 func IgnoreErr[TickType any, Fn Func[TickType]](task Fn) func(context.Context, TickType) error {
-	adaptedTask := Adapt[TickType](task)
+	adaptedTask := adaptIn[TickType]("IgnoreErr", task)
 	return func(ctx context.Context, tick TickType) error {
 		_ = adaptedTask(ctx, tick)
 		return nil
 	}
 }
 
+// MapErr passes the task errors through fn, e.g. to classify them, wrapping
+// [ErrStopped] for the permanent ones, or to annotate them, before they reach
+// the wrappers, such as [Retry], and the loop. The nil errors are not passed.
+//
+// Example:
+//
+//	Retry(policy, MapErr(stopOnNotFound, fetch))
+func MapErr[TickType any, Fn Func[TickType]](fn func(error) error, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("MapErr", task)
+	return func(ctx context.Context, tick TickType) error {
+		if err := adaptedTask(ctx, tick); err != nil {
+			return fn(err)
+		}
+		return nil
+	}
+}
+
+// StopOn makes the errors, for which fatal returns true, stop the task loop by
+// wrapping them with [ErrStopped]. The other errors are returned as they are,
+// and do not stop the loop, unless they already wrap [ErrStopped].
+func StopOn[TickType any, Fn Func[TickType]](fatal func(error) bool, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("StopOn", task)
+	return func(ctx context.Context, tick TickType) error {
+		err := adaptedTask(ctx, tick)
+		if err != nil && fatal(err) && !errors.Is(err, ErrStopped) {
+			return fmt.Errorf("%w: %w", ErrStopped, err)
+		}
+		return err
+	}
+}
+
 // Sync wraps a task in a mutex lock to avoid concurrent execution.
 func Sync[TickType any, Fn Func[TickType]](locker sync.Locker, task Fn) func(context.Context, TickType) error {
-	adaptedTask := Adapt[TickType](task)
+	adaptedTask := adaptIn[TickType]("Sync", task)
 	return func(ctx context.Context, tick TickType) error {
 		locker.Lock()
 		defer locker.Unlock()
@@ -63,27 +133,185 @@ func Sync[TickType any, Fn Func[TickType]](locker sync.Locker, task Fn) func(con
 	}
 }
 
+// withTimeout returns a context, cancelled after the timeout with the cause,
+// wrapping both [ErrTimeout] and [context.DeadlineExceeded].
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, timeout,
+		fmt.Errorf("%w after %v: %w", ErrTimeout, timeout, context.DeadlineExceeded))
+}
+
 // Timeout sets a timeout for the task.
 // If the task does not finish before the timeout, the context will be
-// cancelled.
+// cancelled. The [context.Cause] of the cancellation wraps [ErrTimeout].
 func Timeout[TickType any, Fn Func[TickType]](timeout time.Duration, task Fn) func(context.Context, TickType) error {
-	adaptedTask := Adapt[TickType](task)
+	adaptedTask := adaptIn[TickType]("Timeout", task)
 	return func(ctx context.Context, tick TickType) error {
-		ctx, cancel := context.WithTimeout(ctx, timeout)
+		ctx, cancel := withTimeout(ctx, timescale.Scale(timeout))
 		defer cancel()
 		return adaptedTask(ctx, tick)
 	}
 }
 
+// TimeoutPolicy defines how [TreatTimeoutAs] handles a run that exceeded its
+// own deadline.
+type TimeoutPolicy int
+
+const (
+	// TimeoutRetry returns the error as is, so that it can be retried by an
+	// enclosing [Retry], and doesn't stop the loop.
+	TimeoutRetry TimeoutPolicy = iota
+	// TimeoutSkip ignores the error, as if the run succeeded.
+	TimeoutSkip
+	// TimeoutFatal wraps the error with [ErrStopped], which stops the loop.
+	TimeoutFatal
+)
+
+// TreatTimeoutAs applies the policy to the task errors, caused by the task
+// own deadline, e.g. set by an inner [Timeout]. The errors, caused by the
+// parent context cancellation, are returned as is.
+func TreatTimeoutAs[TickType any, Fn Func[TickType]](policy TimeoutPolicy, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("TreatTimeoutAs", task)
+	return func(ctx context.Context, tick TickType) error {
+		err := adaptedTask(ctx, tick)
+		if !errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			return err
+		}
+		switch policy {
+		case TimeoutSkip:
+			return nil
+		case TimeoutFatal:
+			return fmt.Errorf("%w: %w", ErrStopped, err)
+		}
+		return err
+	}
+}
+
+// autoTimeoutWarmup is the number of runs, after which [AutoTimeout] starts
+// limiting the execution time.
+const autoTimeoutWarmup = 3
+
+// autoTimeoutAlpha is the smoothing factor of the run duration exponential
+// moving average.
+const autoTimeoutAlpha = 0.2
+
+// AutoTimeout sets a timeout for the task, learnt from the previous runs.
+// The timeout is multiplier times the exponential moving average of the run
+// durations. The runs are not limited until the average is collected over a
+// few runs. Runs, cancelled by the parent context, are not accounted. The
+// learnt timeout is not scaled by the test acceleration, as the measured runs
+// are accelerated already.
+func AutoTimeout[TickType any, Fn Func[TickType]](multiplier float64, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("AutoTimeout", task)
+	var mux sync.Mutex
+	var average float64
+	var runs int
+	return func(ctx context.Context, tick TickType) error {
+		mux.Lock()
+		timeout := time.Duration(average * multiplier)
+		limited := runs >= autoTimeoutWarmup
+		mux.Unlock()
+
+		taskCtx := ctx
+		if limited {
+			var cancel context.CancelFunc
+			taskCtx, cancel = withTimeout(ctx, timeout)
+			defer cancel()
+		}
+		start := time.Now()
+		err := adaptedTask(taskCtx, tick)
+		if ctx.Err() == nil {
+			elapsed := float64(time.Since(start))
+			mux.Lock()
+			if runs == 0 {
+				average = elapsed
+			} else {
+				average += autoTimeoutAlpha * (elapsed - average)
+			}
+			runs++
+			mux.Unlock()
+		}
+		return err
+	}
+}
+
 func getAttemptNumber(ctx context.Context) (int, bool) {
 	attempt, ok := ctx.Value(AttemptNumber).(int)
 	return attempt, ok
 }
 
+type logOptions struct {
+	every    int
+	interval time.Duration
+}
+
+type logOption func(*logOptions)
+
+// LogErrorsEvery makes [Log] write only the first and every nth of the
+// consecutive identical error messages.
+func LogErrorsEvery(n int) logOption {
+	return func(o *logOptions) {
+		o.every = n
+	}
+}
+
+// LogErrorsOncePer makes [Log] write the consecutive identical error messages
+// at most once per interval d.
+func LogErrorsOncePer(d time.Duration) logOption {
+	return func(o *logOptions) {
+		o.interval = d
+	}
+}
+
+// errorSampler suppresses the consecutive identical error messages.
+type errorSampler struct {
+	logOptions
+	mux        sync.Mutex
+	last       string
+	repeats    int
+	logged     time.Time
+	suppressed int
+}
+
+// sample returns whether the message should be written, and the number of the
+// identical messages suppressed since the last written one.
+func (s *errorSampler) sample(message string) (bool, int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now := time.Now()
+	if message != s.last {
+		s.last, s.repeats, s.logged, s.suppressed = message, 0, now, 0
+		return true, 0
+	}
+	s.repeats++
+	if s.every > 1 && s.repeats%s.every != 0 ||
+		s.interval > 0 && now.Sub(s.logged) < s.interval {
+		s.suppressed++
+		return false, 0
+	}
+	suppressed := s.suppressed
+	s.logged, s.suppressed = now, 0
+	return true, suppressed
+}
+
 // Log adds logging to the task.
 // It will log the task name on every invocation, and the error if it occurs.
-func Log[TickType any, Fn Func[TickType]](outW io.Writer, errW io.Writer, name string, task Fn) func(context.Context, TickType) error {
-	adaptedTask := Adapt[TickType](task)
+// The repeated error messages may be sampled with [LogErrorsEvery] and
+// [LogErrorsOncePer].
+func Log[TickType any, Fn Func[TickType]](outW io.Writer, errW io.Writer, name string, task Fn, opts ...logOption) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Log", task)
+	sampler := &errorSampler{}
+	for _, opt := range opts {
+		opt(&sampler.logOptions)
+	}
+	logError := func(a ...any) {
+		message := fmt.Sprintln(a...)
+		if ok, suppressed := sampler.sample(message); ok {
+			if suppressed > 0 {
+				message = fmt.Sprintf("%s(%d identical messages suppressed)\n", message, suppressed)
+			}
+			_, _ = io.WriteString(errW, message)
+		}
+	}
 	return func(ctx context.Context, tick TickType) error {
 		attempt, ok := getAttemptNumber(ctx)
 		if attempt > 0 {
@@ -96,19 +324,19 @@ func Log[TickType any, Fn Func[TickType]](outW io.Writer, errW io.Writer, name s
 		case err != nil && ctx.Err() == nil:
 			if errors.Is(err, ErrStopped) {
 				if attempt > 0 {
-					_, _ = fmt.Fprintln(errW, "Execution of", name, "stopped after retry", attempt, "with error:", err.Error())
+					logError("Execution of", name, "stopped after retry", attempt, "with error:", err.Error())
 				} else if ok {
-					_, _ = fmt.Fprintln(errW, "Execution of", name, "stopped after the first attempt with error:", err.Error())
+					logError("Execution of", name, "stopped after the first attempt with error:", err.Error())
 				} else {
-					_, _ = fmt.Fprintln(errW, "Execution of", name, "stopped with error:", err.Error())
+					logError("Execution of", name, "stopped with error:", err.Error())
 				}
 			} else {
 				if attempt > 0 {
-					_, _ = fmt.Fprintln(errW, "Execution of", name, "failed after retry", attempt, "with error:", err.Error())
+					logError("Execution of", name, "failed after retry", attempt, "with error:", err.Error())
 				} else if ok {
-					_, _ = fmt.Fprintln(errW, "Execution of", name, "failed after the first attempt with error:", err.Error())
+					logError("Execution of", name, "failed after the first attempt with error:", err.Error())
 				} else {
-					_, _ = fmt.Fprintln(errW, "Execution of", name, "failed with error:", err.Error())
+					logError("Execution of", name, "failed with error:", err.Error())
 				}
 			}
 		case ctx.Err() == context.Canceled:
@@ -121,12 +349,16 @@ func Log[TickType any, Fn Func[TickType]](outW io.Writer, errW io.Writer, name s
 }
 
 // NoOverlap prevents the task from running concurrently.
-// It will skip the task if it is already running.
+// It will skip the task if it is already running, and report the skip with
+// [SkipReasonOverlap]. Wrapping [Retry], it occupies the task for all the
+// attempts of a run; see [NoOverlapGroup] for the occupancy, which holds in the
+// other order too.
 func NoOverlap[TickType any, Fn Func[TickType]](task Fn) func(context.Context, TickType) error {
-	adaptedTask := Adapt[TickType](task)
+	adaptedTask := adaptIn[TickType]("NoOverlap", task)
 	var running atomic.Int32
 	return func(ctx context.Context, tick TickType) error {
 		if !running.CompareAndSwap(0, 1) {
+			Skip(ctx, SkipReasonOverlap)
 			return nil
 		}
 		defer running.Store(0)
@@ -150,30 +382,314 @@ func SimpleRetryPolicy(attempts int) RetryPolicy {
 
 // ExponentialBackoffPolicy returns a retry policy that uses exponential
 // backoff.
-// It will retry to run the task the specified number of times.
+// It will retry to run the task the specified number of times. The backoff is
+// interrupted, and the task is not retried, if the context is done.
 func ExponentialBackoffPolicy(attempts int, duration time.Duration) RetryPolicy {
 	return func(ctx context.Context, i int, err error) bool {
-		if err != nil && ctx.Err() == nil {
-			time.Sleep(time.Duration(i+1) * duration)
-			return i < attempts-1
+		if err == nil || ctx.Err() != nil || i >= attempts-1 {
+			return false
+		}
+		timer := time.NewTimer(timescale.Scale(time.Duration(i+1) * duration))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// PolicyByError returns the retry policy, which delegates the decision to the
+// policy, picked by the error, e.g. by its classification as a network,
+// validation or rate limit error. The error is not retried if pick returns
+// nil. The picked policy is called with the number of all previous attempts.
+//
+// Example:
+//
+//	Retry(PolicyByError(func(err error) RetryPolicy {
+//		switch {
+//		case errors.Is(err, errRateLimited):
+//			return ExponentialBackoffPolicy(5, time.Second)
+//		case errors.Is(err, errInvalid):
+//			return nil
+//		}
+//		return SimpleRetryPolicy(3)
+//	}), task)
+func PolicyByError(pick func(error) RetryPolicy) RetryPolicy {
+	return func(ctx context.Context, i int, err error) bool {
+		if err == nil {
+			return false
 		}
-		return false
+		policy := pick(err)
+		return policy != nil && policy(ctx, i, err)
 	}
 }
 
 // Retry retries the task if it returns an error.
 // It will retry to run the task according to the policy function.
+// The repeated attempts are invoked with [RunCauseRetry]. The attempts may
+// suggest the retry time with [SuggestRetryAt]. The errors, wrapping
+// [ErrStopped] or [ErrSkipped], are not retried.
 func Retry[TickType any, Fn Func[TickType]](policy RetryPolicy, task Fn) func(context.Context, TickType) error {
-	adaptedTask := Adapt[TickType](task)
+	return retry(policy, adaptIn[TickType]("Retry", task))
+}
+
+// retry implements [Retry] for the adapted task.
+func retry[TickType any](policy RetryPolicy, adaptedTask func(context.Context, TickType) error) func(context.Context, TickType) error {
 	return func(ctx context.Context, tick TickType) error {
+		ctx, end := withRetrySequence(ctx)
+		defer end()
 		var err error
 		for i := 0; ; i++ {
-			ctx = context.WithValue(ctx, AttemptNumber, i)
+			ctx = withRetryHint(context.WithValue(ctx, AttemptNumber, i))
+			if i == 1 {
+				ctx = WithRunCause(ctx, RunCauseRetry)
+			}
 			err = adaptedTask(ctx, tick)
-			if errors.Is(err, ErrStopped) || !policy(ctx, i, err) {
+			if errors.Is(err, ErrStopped) || errors.Is(err, ErrSkipped) || !policy(ctx, i, err) {
 				break
 			}
 		}
 		return err
 	}
 }
+
+// SkipReasonUnhealthy is the reason of the runs skipped by [HealthGate].
+const SkipReasonUnhealthy = "unhealthy"
+
+// HealthGate calls the probe before every run, and skips the run, reporting
+// [SkipReasonUnhealthy], if the probe fails. If the policy is not nil, the
+// probe is retried according to the policy before skipping, which allows for
+// delaying the run with backoff.
+func HealthGate[TickType any, Fn Func[TickType]](probe func(context.Context) error, policy RetryPolicy, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("HealthGate", task)
+	return func(ctx context.Context, tick TickType) error {
+		for i := 0; ; i++ {
+			err := probe(ctx)
+			if err == nil {
+				return adaptedTask(ctx, tick)
+			}
+			if policy == nil || !policy(ctx, i, err) {
+				Skip(ctx, SkipReasonUnhealthy)
+				return nil
+			}
+		}
+	}
+}
+
+// SkipReasonNotReady is the reason of the runs skipped by [StartGate].
+const SkipReasonNotReady = "not ready"
+
+// StartGate holds the first run until the gate, e.g. checking that a database
+// is reachable and migrated, returns no error, retrying it according to the
+// policy, which may delay the attempts with backoff. If the gate still fails,
+// the run is skipped, reporting [SkipReasonNotReady], and the gate is checked
+// again on the next run. Once it has passed, the gate is not called anymore.
+// The concurrent runs wait for the gate check in progress, but not for the
+// policy backoff. The context cause is returned if the context is done while
+// waiting.
+func StartGate[TickType any, Fn Func[TickType]](gate func(context.Context) error, policy RetryPolicy, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("StartGate", task)
+	checking := make(chan struct{}, 1)
+	var open atomic.Bool
+	return func(ctx context.Context, tick TickType) error {
+		for i := 0; !open.Load(); i++ {
+			select {
+			case checking <- struct{}{}:
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+			var err error
+			if !open.Load() {
+				if err = gate(ctx); err == nil {
+					open.Store(true)
+				}
+			}
+			<-checking
+			if err != nil && (policy == nil || !policy(ctx, i, err)) {
+				Skip(ctx, SkipReasonNotReady)
+				return nil
+			}
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
+
+// MinGap serializes the task runs, and delays a run until at least d elapses
+// after the end of the previous one. The delay is interrupted if the context is
+// cancelled, in which case the context cause is returned.
+func MinGap[TickType any, Fn Func[TickType]](d time.Duration, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("MinGap", task)
+	var mux sync.Mutex
+	var lastEnd time.Time
+	return func(ctx context.Context, tick TickType) error {
+		mux.Lock()
+		defer mux.Unlock()
+		if wait := timescale.Scale(d) - time.Since(lastEnd); !lastEnd.IsZero() && wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return context.Cause(ctx)
+			}
+		}
+		defer func() { lastEnd = time.Now() }()
+		return adaptedTask(ctx, tick)
+	}
+}
+
+// ThrottleReplay delays the runs with [RunCauseReplay], e.g. backfilling the
+// ticks, missed while the process was down, so that they start at least
+// interval apart, and do not overload the dependencies on restart. The other
+// runs are neither delayed nor accounted. The delay is interrupted if the
+// context is cancelled, in which case the context cause is returned.
+func ThrottleReplay[TickType any, Fn Func[TickType]](interval time.Duration, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("ThrottleReplay", task)
+	var mux sync.Mutex
+	var next time.Time
+	return func(ctx context.Context, tick TickType) error {
+		if RunCauseFromContext(ctx) != RunCauseReplay {
+			return adaptedTask(ctx, tick)
+		}
+		mux.Lock()
+		start := time.Now()
+		if start.Before(next) {
+			start = next
+		}
+		next = start.Add(timescale.Scale(interval))
+		mux.Unlock()
+		if wait := time.Until(start); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return context.Cause(ctx)
+			}
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
+
+// RetryCancelBetweenAttempts retries the task as [Retry] does, but an attempt
+// in progress is not interrupted by the context cancellation: the attempts are
+// executed with the context values, but without its cancellation and deadline.
+// The cancellation is checked before every next attempt, which is needed for
+// non-idempotent operations.
+func RetryCancelBetweenAttempts[TickType any, Fn Func[TickType]](policy RetryPolicy, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("RetryCancelBetweenAttempts", task)
+	return retry(func(ctx context.Context, i int, err error) bool {
+		return policy(ctx, i, err) && ctx.Err() == nil
+	}, func(ctx context.Context, tick TickType) error {
+		return adaptedTask(context.WithoutCancel(ctx), tick)
+	})
+}
+
+// When executes the task only if the condition is true, and reports the skip
+// with the reason otherwise.
+func When[TickType any, Fn Func[TickType]](condition func(context.Context, TickType) bool, reason string, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("When", task)
+	return func(ctx context.Context, tick TickType) error {
+		if !condition(ctx, tick) {
+			Skip(ctx, reason)
+			return nil
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
+
+// ErrSuperseded is the cause of the run context cancellation by [Takeover].
+var ErrSuperseded = errors.New("superseded by a newer run")
+
+type backgroundCtxKey struct{}
+
+// backgroundTracker is the tracker of the background runs, see
+// [WithBackground].
+type backgroundTracker struct {
+	lifetime context.Context
+	started  func() func(error)
+}
+
+// WithBackground returns the context, under which the wrappers, which continue
+// the run in background after returning, e.g. [Takeover], report such runs:
+// started is called before a background run starts, and the returned function
+// with the run error after it finishes. The background runs are not cancelled
+// with the run context, which ends when the wrapper returns, but are cancelled
+// with lifetime, e.g. when the task stops.
+func WithBackground(ctx context.Context, lifetime context.Context, started func() (finished func(error))) context.Context {
+	return context.WithValue(ctx, backgroundCtxKey{}, &backgroundTracker{lifetime, started})
+}
+
+// goBackground calls f in a new goroutine, reported to the tracker of the
+// context, if any, and calls cancel with the cause of the end of the tracker
+// lifetime, if it ends first. See [WithBackground].
+func goBackground(ctx context.Context, cancel context.CancelCauseFunc, f func() error) {
+	finished := func(error) {}
+	stop := func() bool { return false }
+	if tracker, ok := ctx.Value(backgroundCtxKey{}).(*backgroundTracker); ok {
+		finished = tracker.started()
+		stop = context.AfterFunc(tracker.lifetime, func() { cancel(context.Cause(tracker.lifetime)) })
+	}
+	go func() {
+		defer stop()
+		finished(f())
+	}()
+}
+
+// Takeover starts the task in background, and returns immediately. A new run
+// cancels the context of the previous one with [ErrSuperseded], so that the
+// newest run wins. The runs are not cancelled with the context of the run,
+// which started them, e.g. by [Timeout], but keep its values. The background
+// runs and their errors, except the ones of the superseded runs, are reported
+// to the tracker of the context, see [WithBackground]; the tasks, created with
+// NewTask, wait for such runs, cancel them on stop, and stop on the errors,
+// wrapping [ErrStopped], as on the errors of the task function. Without a
+// tracker the errors are dropped.
+func Takeover[TickType any, Fn Func[TickType]](task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("Takeover", task)
+	var mux sync.Mutex
+	var cancel context.CancelCauseFunc
+	return func(ctx context.Context, tick TickType) error {
+		mux.Lock()
+		defer mux.Unlock()
+		if cancel != nil {
+			cancel(ErrSuperseded)
+		}
+		runCtx, runCancel := context.WithCancelCause(context.WithoutCancel(ctx))
+		cancel = runCancel
+		goBackground(ctx, runCancel, func() error {
+			err := adaptedTask(runCtx, tick)
+			runCancel(nil)
+			if errors.Is(context.Cause(runCtx), ErrSuperseded) {
+				return nil
+			}
+			return err
+		})
+		return nil
+	}
+}
+
+// ErrIdle is returned by [IdleStop], and wraps [ErrStopped].
+var ErrIdle = fmt.Errorf("idle: %w", ErrStopped)
+
+// IdleStop stops the task after n consecutive no-op runs, for which isNoOp
+// returns true, by returning an error, wrapping [ErrIdle], instead of the task
+// error. The other runs reset the count.
+func IdleStop[TickType any, Fn Func[TickType]](n int, isNoOp func(error) bool, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("IdleStop", task)
+	var idle atomic.Int32
+	return func(ctx context.Context, tick TickType) error {
+		err := adaptedTask(ctx, tick)
+		if !isNoOp(err) {
+			idle.Store(0)
+			return err
+		}
+		if runs := idle.Add(1); int(runs) >= n {
+			idle.Store(0)
+			return fmt.Errorf("%d consecutive no-op runs: %w", runs, ErrIdle)
+		}
+		return err
+	}
+}
@@ -3,6 +3,8 @@ package utils
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -26,6 +28,89 @@ func TestSeqIgnoreErr(t *testing.T) {
 		assert.Equal(12, i))
 }
 
+func TestMapErr(t *testing.T) {
+	errNotFound := errors.New("not found")
+	permanent := func(err error) error {
+		if errors.Is(err, errNotFound) {
+			return fmt.Errorf("%w: %w", ErrStopped, err)
+		}
+		return err
+	}
+	var attempts int
+	task := Retry[any](SimpleRetryPolicy(3), MapErr[any](permanent, func() error {
+		attempts++
+		return errNotFound
+	}))
+	err := task(context.Background(), nil)
+	assert.That(t,
+		assert.ErrorIs(err, ErrStopped),
+		assert.ErrorIs(err, errNotFound),
+		assert.Equal(1, attempts),
+		assert.NoError(MapErr[any](permanent, func() {})(context.Background(), nil)))
+}
+
+func TestStopOn(t *testing.T) {
+	errFatal := errors.New("fatal")
+	errTransient := errors.New("transient")
+	fatal := func(err error) bool { return errors.Is(err, errFatal) }
+	failWith := func(err error) func() error { return func() error { return err } }
+
+	err := StopOn[any](fatal, failWith(errFatal))(context.Background(), nil)
+	assert.That(t,
+		assert.ErrorIs(err, ErrStopped),
+		assert.ErrorIs(err, errFatal),
+		assert.Equal("stopped: fatal", err.Error()))
+
+	err = StopOn[any](fatal, failWith(errTransient))(context.Background(), nil)
+	assert.That(t,
+		assert.Equal(errTransient, err),
+		assert.Equal(ErrIdle, StopOn[any](fatal, failWith(ErrIdle))(context.Background(), nil)))
+}
+
+func TestSeqOptional(t *testing.T) {
+	var calls []string
+	step := func(name string) func() {
+		return func() { calls = append(calls, name) }
+	}
+	err := Seq(
+		Optional[any](true, step("a")),
+		Optional[any](false, step("b")),
+		nil,
+		Adapt[any](step("c")))(context.Background(), nil)
+	assert.That(t,
+		assert.NoError(err),
+		assert.EqualSlices([]string{"a", "c"}, calls))
+
+	_, err = StrictSeq(Adapt[any](step("a")), Optional[any](false, step("b")))
+	assert.That(t, assert.ErrorIs(err, ErrNilTask))
+	seq, err := StrictSeq(Adapt[any](step("d")))
+	assert.That(t,
+		assert.NoError(err),
+		assert.NoError(seq(context.Background(), nil)),
+		assert.EqualSlices([]string{"a", "c", "d"}, calls))
+}
+
+func TestIdleStop(t *testing.T) {
+	errEmpty := errors.New("empty")
+	results := []error{errEmpty, nil, errEmpty, errEmpty, errEmpty}
+	i := 0
+	task := IdleStop[any](3, func(err error) bool { return errors.Is(err, errEmpty) },
+		func() error {
+			i++
+			return results[i-1]
+		})
+	var errs []error
+	for range results {
+		errs = append(errs, task(context.Background(), nil))
+	}
+	assert.That(t,
+		assert.ErrorIs(errs[0], errEmpty),
+		assert.NoError(errs[1]),
+		assert.ErrorIs(errs[3], errEmpty),
+		assert.ErrorIs(errs[4], ErrIdle),
+		assert.ErrorIs(errs[4], ErrStopped))
+}
+
 type arr []string
 
 func (a *arr) Write(data []byte) (int, error) {
@@ -33,6 +118,36 @@ func (a *arr) Write(data []byte) (int, error) {
 	return len(data), nil
 }
 
+func TestLog_sampling(t *testing.T) {
+	failing := func() error { return errors.New("oops") }
+
+	t.Run("every", func(t *testing.T) {
+		var a = &arr{}
+		task := Log[any](io.Discard, a, "test", failing, LogErrorsEvery(3))
+		for range 7 {
+			_ = task(context.Background(), nil)
+		}
+		assert.That(t, assert.EqualSlices([]string{
+			"Execution of test failed with error: oops\n",
+			"Execution of test failed with error: oops\n(2 identical messages suppressed)\n",
+			"Execution of test failed with error: oops\n(2 identical messages suppressed)\n",
+		}, *a))
+	})
+
+	t.Run("once per", func(t *testing.T) {
+		var a = &arr{}
+		task := Log[any](io.Discard, a, "test", failing, LogErrorsOncePer(time.Hour))
+		for range 3 {
+			_ = task(context.Background(), nil)
+		}
+		_ = Log[any](io.Discard, a, "other", failing)(context.Background(), nil)
+		assert.That(t, assert.EqualSlices([]string{
+			"Execution of test failed with error: oops\n",
+			"Execution of other failed with error: oops\n",
+		}, *a))
+	})
+}
+
 func TestWithLog(t *testing.T) {
 	t.Run("error", func(t *testing.T) {
 		var a = &arr{}
@@ -146,13 +261,18 @@ func TestWithLog(t *testing.T) {
 func TestWithTimeout(t *testing.T) {
 	var deadline time.Time
 	var ok bool
+	var cause error
 	now := time.Now()
 	err := Timeout[any](0, func(ctx context.Context) error {
 		deadline, ok = ctx.Deadline()
+		cause = context.Cause(ctx)
 		return ctx.Err()
 	})(context.Background(), 0)
 	assert.That(t,
 		assert.ErrorIs(err, context.DeadlineExceeded),
+		assert.ErrorIs(cause, ErrTimeout),
+		assert.ErrorIs(cause, context.DeadlineExceeded),
+		assert.Equal("timeout after 0s: context deadline exceeded", cause.Error()),
 		assert.True(ok),
 		assert.True(time.Since(now) >= time.Since(deadline)))
 }
@@ -211,6 +331,35 @@ func TestWithRetry(t *testing.T) {
 			assert.NoError(err),
 			assert.Equal(1, i))
 	})
+	t.Run("with policy by error", func(t *testing.T) {
+		errNetwork := errors.New("network")
+		errInvalid := errors.New("invalid")
+		policy := PolicyByError(func(err error) RetryPolicy {
+			if errors.Is(err, errNetwork) {
+				return SimpleRetryPolicy(4)
+			}
+			return nil
+		})
+		i := 0
+		err := Retry[any](policy, func() error {
+			i++
+			if i < 3 {
+				return errNetwork
+			}
+			return errInvalid
+		})(context.Background(), 0)
+		assert.That(t,
+			assert.ErrorIs(err, errInvalid),
+			assert.Equal(3, i))
+		i = 0
+		err = Retry[any](policy, func() error {
+			i++
+			return errNetwork
+		})(context.Background(), 0)
+		assert.That(t,
+			assert.ErrorIs(err, errNetwork),
+			assert.Equal(4, i))
+	})
 	t.Run("with exponential backoff", func(t *testing.T) {
 		var i int
 		task := func() error {
@@ -233,6 +382,11 @@ func TestWithRetry(t *testing.T) {
 		assert.That(t,
 			assert.NoError(err),
 			assert.Equal(1, i))
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		assert.That(t,
+			assert.False(ExponentialBackoffPolicy(3, time.Hour)(ctx, 0, errors.New("failed"))))
 	})
 }
 
@@ -256,3 +410,258 @@ func TestSync(t *testing.T) {
 			"unlocked\n",
 		}, (*loglock)))
 }
+
+func TestAutoTimeout(t *testing.T) {
+	var deadlines []bool
+	delay := time.Millisecond
+	task := AutoTimeout[any](2, func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		deadlines = append(deadlines, ok)
+		select {
+		case <-time.After(delay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	for range autoTimeoutWarmup {
+		assert.That(t, assert.NoError(task(context.Background(), nil)))
+	}
+	assert.That(t, assert.NoError(task(context.Background(), nil)))
+
+	delay = time.Second
+	assert.That(t,
+		assert.ErrorIs(task(context.Background(), nil), context.DeadlineExceeded),
+		assert.EqualSlices([]bool{false, false, false, true, true}, deadlines))
+}
+
+func TestTreatTimeoutAs(t *testing.T) {
+	slow := Timeout[any](0, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	errTest := errors.New("test")
+	failing := func() error { return errTest }
+
+	for policy, expected := range map[TimeoutPolicy]error{
+		TimeoutRetry: context.DeadlineExceeded,
+		TimeoutSkip:  nil,
+		TimeoutFatal: ErrStopped,
+	} {
+		err := TreatTimeoutAs[any](policy, slow)(context.Background(), nil)
+		assert.That(t,
+			assert.ErrorIs(err, expected),
+			assert.ErrorIs(TreatTimeoutAs[any](policy, failing)(context.Background(), nil), errTest))
+	}
+
+	t.Run("parent deadline", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+		defer cancel()
+		err := TreatTimeoutAs[any](TimeoutSkip, slow)(ctx, nil)
+		assert.That(t,
+			assert.ErrorIs(err, context.DeadlineExceeded))
+	})
+}
+
+func TestHealthGate(t *testing.T) {
+	errDown := errors.New("down")
+	probes := 0
+	probe := func(context.Context) error {
+		probes++
+		if probes < 3 {
+			return errDown
+		}
+		return nil
+	}
+	runs := 0
+	task := func() { runs++ }
+
+	var results []RunResult
+	report := func(r RunResult) { results = append(results, r) }
+	_ = Classify[any](report, HealthGate[any](probe, nil, task))(context.Background(), nil)
+	assert.That(t,
+		assert.Equal(0, runs),
+		assert.EqualSlices([]RunResult{{RunSkipped, SkipReasonUnhealthy, nil}}, results))
+
+	probes = 0
+	err := HealthGate[any](probe, ExponentialBackoffPolicy(3, time.Millisecond), task)(context.Background(), nil)
+	assert.That(t,
+		assert.NoError(err),
+		assert.Equal(3, probes),
+		assert.Equal(1, runs))
+}
+
+func TestStartGate(t *testing.T) {
+	errDown := errors.New("down")
+	checks := 0
+	gate := func(context.Context) error {
+		checks++
+		if checks < 4 {
+			return errDown
+		}
+		return nil
+	}
+	runs := 0
+	var results []RunResult
+	task := Classify[any](func(r RunResult) { results = append(results, r) },
+		StartGate[any](gate, SimpleRetryPolicy(2), func() { runs++ }))
+
+	for range 3 {
+		_ = task(context.Background(), nil)
+	}
+	assert.That(t,
+		assert.Equal(4, checks),
+		assert.Equal(2, runs),
+		assert.EqualSlices([]RunResult{
+			{RunSkipped, SkipReasonNotReady, nil},
+			{RunExecuted, "", nil},
+			{RunExecuted, "", nil},
+		}, results))
+
+	t.Run("backoff", func(t *testing.T) {
+		var checks atomic.Int32
+		backoff := make(chan struct{})
+		policy := func(ctx context.Context, i int, err error) bool {
+			if checks.Load() == 1 {
+				close(backoff)
+				<-ctx.Done()
+			}
+			return false
+		}
+		task := StartGate[any](func(context.Context) error {
+			checks.Add(1)
+			return errDown
+		}, policy, func() { t.Error("unexpected call") })
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- task(ctx, nil) }()
+		<-backoff
+		// The concurrent run checks the gate during the backoff.
+		assert.That(t,
+			assert.NoError(task(context.Background(), nil)),
+			assert.Equal(int32(2), checks.Load()))
+		cancel()
+		assert.That(t, assert.NoError(<-done))
+	})
+}
+
+func TestThrottleReplay(t *testing.T) {
+	var starts []time.Time
+	task := ThrottleReplay[any](20*time.Millisecond, func() {
+		starts = append(starts, time.Now())
+	})
+	replay := WithRunCause(context.Background(), RunCauseReplay)
+	for range 3 {
+		assert.That(t, assert.NoError(task(replay, nil)))
+	}
+	assert.That(t,
+		assert.Equal(3, len(starts)),
+		assert.True(starts[2].Sub(starts[0]) >= 40*time.Millisecond))
+
+	start := time.Now()
+	assert.That(t,
+		assert.NoError(task(context.Background(), nil)),
+		assert.True(time.Since(start) < 20*time.Millisecond))
+
+	ctx, cancel := context.WithCancelCause(replay)
+	cancel(ErrStopped)
+	_ = task(replay, nil)
+	assert.That(t,
+		assert.ErrorIs(task(ctx, nil), ErrStopped),
+		assert.Equal(5, len(starts)))
+}
+
+func TestMinGap(t *testing.T) {
+	var starts []time.Time
+	task := MinGap[any](20*time.Millisecond, func() {
+		starts = append(starts, time.Now())
+	})
+	for range 3 {
+		assert.That(t, assert.NoError(task(context.Background(), nil)))
+	}
+	assert.That(t,
+		assert.True(starts[1].Sub(starts[0]) >= 20*time.Millisecond),
+		assert.True(starts[2].Sub(starts[1]) >= 20*time.Millisecond))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrStopped)
+	assert.That(t,
+		assert.ErrorIs(task(ctx, nil), ErrStopped),
+		assert.Equal(3, len(starts)))
+}
+
+func TestRetryCancelBetweenAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts []int
+	var errs []error
+	err := RetryCancelBetweenAttempts[any](SimpleRetryPolicy(3), func(ctx context.Context) error {
+		attempt, _ := getAttemptNumber(ctx)
+		attempts = append(attempts, attempt)
+		cancel()
+		errs = append(errs, ctx.Err())
+		return errors.New("test")
+	})(ctx, nil)
+	assert.That(t,
+		assert.Not(assert.NoError(err)),
+		assert.EqualSlices([]int{0}, attempts),
+		assert.EqualSlices([]error{nil}, errs))
+}
+
+func TestWhen(t *testing.T) {
+	var results []RunResult
+	report := func(r RunResult) { results = append(results, r) }
+	calls := 0
+	task := Classify[int](report, When[int](func(_ context.Context, tick int) bool {
+		return tick%2 == 0
+	}, "odd", func() { calls++ }))
+	for tick := range 3 {
+		_ = task(context.Background(), tick)
+	}
+	assert.That(t,
+		assert.Equal(2, calls),
+		assert.EqualSlices([]RunResult{{}, {RunSkipped, "odd", nil}, {}}, results))
+}
+
+func TestTakeover(t *testing.T) {
+	causes := make(chan error, 2)
+	errTest := errors.New("test")
+	task := Takeover[int](func(ctx context.Context, tick int) error {
+		if tick == 2 {
+			return errTest
+		}
+		<-ctx.Done()
+		causes <- context.Cause(ctx)
+		return ctx.Err()
+	})
+	var started atomic.Int32
+	finished := make(chan error, 3)
+	lifetime, stop := context.WithCancelCause(context.Background())
+	ctx := WithBackground(context.Background(), lifetime, func() func(error) {
+		started.Add(1)
+		return func(err error) { finished <- err }
+	})
+	assert.That(t, assert.NoError(task(ctx, 0)))
+	assert.That(t, assert.NoError(task(ctx, 1)))
+	assert.That(t,
+		assert.ErrorIs(<-causes, ErrSuperseded),
+		assert.NoError(<-finished))
+	assert.That(t, assert.NoError(task(ctx, 2)))
+	assert.That(t, assert.ErrorIs(<-causes, ErrSuperseded))
+	// The superseded run and the failed one finish in any order.
+	err := errors.Join(<-finished, <-finished)
+	assert.That(t,
+		assert.ErrorIs(err, errTest),
+		assert.Equal(errTest.Error(), err.Error()),
+		assert.Equal(int32(3), started.Load()))
+
+	// The run outlives the context of the run, which started it, but not the
+	// lifetime of the tracker.
+	runCtx, cancel := context.WithCancel(ctx)
+	assert.That(t, assert.NoError(task(runCtx, 3)))
+	cancel()
+	stop(ErrStopped)
+	assert.That(t,
+		assert.ErrorIs(<-causes, ErrStopped),
+		assert.ErrorIs(<-finished, context.Canceled))
+}
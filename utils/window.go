@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/parametalol/goticks/internal/timescale"
+	"github.com/parametalol/goticks/ticker"
+)
+
+// TimeOfDay is the time since the midnight.
+type TimeOfDay time.Duration
+
+// At returns the time of day of the hour and the minute, e.g. At(9, 30).
+func At(hour, minute int) TimeOfDay {
+	return TimeOfDay(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+}
+
+// Window is the daily time window, e.g. the business hours, or the nightly
+// batch window, which ends after the midnight, if End is before Start. The
+// window is open the whole day if Start equals End.
+type Window struct {
+	Start, End TimeOfDay
+	// Location defaults to [time.Local].
+	Location *time.Location
+}
+
+func (w Window) location() *time.Location {
+	if w.Location == nil {
+		return time.Local
+	}
+	return w.Location
+}
+
+// Contains tells whether the window is open at t, comparing the wall clock time
+// of day in the window location.
+func (w Window) Contains(t time.Time) bool {
+	if w.Start == w.End {
+		return true
+	}
+	_, open := ticker.DailyWindow(time.Duration(w.Start), time.Duration(w.End))(t.In(w.location()))
+	return open
+}
+
+// NextStart returns the next opening of the window after t.
+func (w Window) NextStart(t time.Time) time.Time {
+	year, month, day := t.In(w.location()).Date()
+	start := w.startOn(year, month, day)
+	if !start.After(t) {
+		start = w.startOn(year, month, day+1)
+	}
+	return start
+}
+
+// startOn returns the opening of the window on the day by the wall clock, which
+// is normalized by [time.Date], if the day skips it.
+func (w Window) startOn(year int, month time.Month, day int) time.Time {
+	start := time.Duration(w.Start)
+	return time.Date(year, month, day,
+		int(start/time.Hour), int(start%time.Hour/time.Minute), int(start%time.Minute/time.Second), int(start%time.Second),
+		w.location())
+}
+
+// WindowPolicy tells what [InWindow] does with the runs outside the window.
+type WindowPolicy int
+
+const (
+	// WindowDefer delays the run until the window opens.
+	WindowDefer WindowPolicy = iota
+	// WindowSkip skips the run, reporting [SkipReasonOutsideWindow].
+	WindowSkip
+)
+
+// SkipReasonOutsideWindow is the reason of the runs skipped by [InWindow].
+const SkipReasonOutsideWindow = "outside window"
+
+// InWindow executes the task only while the window is open. The runs, which
+// start outside the window, are deferred until it opens, or skipped, according
+// to the policy. The context cause is returned if the context is done while
+// deferring.
+func InWindow[TickType any, Fn Func[TickType]](w Window, policy WindowPolicy, task Fn) func(context.Context, TickType) error {
+	adaptedTask := adaptIn[TickType]("InWindow", task)
+	return func(ctx context.Context, tick TickType) error {
+		if now := time.Now(); !w.Contains(now) {
+			if policy == WindowSkip {
+				Skip(ctx, SkipReasonOutsideWindow)
+				return nil
+			}
+			timer := time.NewTimer(timescale.Scale(w.NextStart(now).Sub(now)))
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return context.Cause(ctx)
+			}
+		}
+		return adaptedTask(ctx, tick)
+	}
+}
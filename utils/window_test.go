@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parametalol/curry/assert"
+)
+
+func TestWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, time.March, 1, hour, minute, 0, 0, time.UTC)
+	}
+	business := Window{At(9, 0), At(17, 30), time.UTC}
+	nightly := Window{At(22, 0), At(4, 0), time.UTC}
+	assert.That(t,
+		assert.True(business.Contains(at(9, 0))),
+		assert.True(business.Contains(at(17, 29))),
+		assert.False(business.Contains(at(17, 30))),
+		assert.False(business.Contains(at(3, 0))),
+		assert.True(nightly.Contains(at(23, 0))),
+		assert.True(nightly.Contains(at(3, 59))),
+		assert.False(nightly.Contains(at(12, 0))),
+		assert.True(Window{At(1, 0), At(1, 0), nil}.Contains(at(12, 0))),
+
+		assert.Equal(at(9, 0), business.NextStart(at(3, 0))),
+		assert.Equal(at(9, 0).AddDate(0, 0, 1), business.NextStart(at(9, 0))),
+		assert.Equal(at(22, 0), nightly.NextStart(at(12, 0))))
+
+	// The time of day in the window location.
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err == nil {
+		assert.That(t,
+			assert.True(Window{At(9, 0), At(10, 0), berlin}.Contains(at(8, 30))),
+			assert.True(at(8, 0).AddDate(0, 0, 1).Equal(Window{At(9, 0), At(10, 0), berlin}.NextStart(at(8, 30)))))
+
+		// The clocks jump from 2:00 to 3:00 on March 31, and from 3:00 back
+		// to 2:00 on October 27.
+		morning := Window{At(9, 0), At(10, 0), berlin}
+		for _, day := range []time.Time{
+			time.Date(2024, 3, 31, 0, 0, 0, 0, berlin),
+			time.Date(2024, 10, 27, 0, 0, 0, 0, berlin),
+		} {
+			at := func(hour, minute int) time.Time {
+				return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, berlin)
+			}
+			assert.That(t,
+				assert.False(morning.Contains(at(8, 30))),
+				assert.True(morning.Contains(at(9, 30))),
+				assert.False(morning.Contains(at(10, 30))),
+				assert.True(at(9, 0).Equal(morning.NextStart(day))))
+		}
+	}
+}
+
+func TestInWindow(t *testing.T) {
+	// The window opens 12 hours from now.
+	hour := (time.Now().Hour() + 12) % 24
+	closed := Window{At(hour, 0), At((hour+1)%24, 0), nil}
+	open := Window{}
+	runs := 0
+	var result RunResult
+	report := func(r RunResult) { result = r }
+
+	assert.That(t,
+		assert.NoError(Classify[any](report, InWindow[any](closed, WindowSkip, func() { runs++ }))(context.Background(), nil)),
+		assert.Equal(0, runs),
+		assert.Equal(SkipReasonOutsideWindow, result.Reason),
+		assert.NoError(InWindow[any](open, WindowDefer, func() { runs++ })(context.Background(), nil)),
+		assert.Equal(1, runs))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	time.AfterFunc(10*time.Millisecond, func() { cancel(ErrStopped) })
+	assert.That(t,
+		assert.ErrorIs(InWindow[any](closed, WindowDefer, func() { runs++ })(ctx, nil), ErrStopped),
+		assert.Equal(1, runs))
+}